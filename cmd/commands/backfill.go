@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/inngest/inngest/pkg/cli"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/execution/runner"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/spf13/cobra"
+
+	// Import the default drivers, queues, and state stores.
+	_ "github.com/inngest/inngest/pkg/config/defaults"
+)
+
+func NewCmdBackfill() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "backfill",
+		Short:   "Run a function for historical events",
+		Example: "inngest backfill --function my-fn --from 2022-07-01T00:00:00Z --to 2022-07-08T00:00:00Z",
+		Run:     doBackfill,
+	}
+
+	cmd.Flags().StringP("config", "c", "", "The config file location (defaults to ./inngest.(cue|json) or /etc/inngest.(cue|json)")
+	cmd.Flags().StringP("function", "f", "", "The ID of the function to backfill")
+	cmd.Flags().String("from", "", "Backfill events received at or after this time, in RFC3339 format")
+	cmd.Flags().String("to", "", "Backfill events received before this time, in RFC3339 format (defaults to now)")
+	cmd.Flags().Float64("rate", 10, "The maximum number of runs to start per second, or 0 for no limit")
+	cmd.Flags().Bool("dry-run", false, "Count the matching events without starting any runs")
+	_ = cmd.MarkFlagRequired("function")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+func doBackfill(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	exit := func(err error) {
+		fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+		os.Exit(1)
+	}
+
+	from, err := time.Parse(time.RFC3339, cmd.Flag("from").Value.String())
+	if err != nil {
+		exit(fmt.Errorf("invalid --from time: %w", err))
+	}
+	to := time.Now()
+	if raw := cmd.Flag("to").Value.String(); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			exit(fmt.Errorf("invalid --to time: %w", err))
+		}
+	}
+	rate, _ := cmd.Flags().GetFloat64("rate")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	locs := []string{}
+	if c := cmd.Flag("config").Value.String(); c != "" {
+		locs = []string{c}
+	}
+	conf, err := config.Load(ctx, locs...)
+	if err != nil {
+		exit(err)
+	}
+
+	rw, err := conf.DataStore.Service.Concrete.ReadWriter(ctx)
+	if err != nil {
+		exit(err)
+	}
+
+	fns, err := rw.Functions(ctx)
+	if err != nil {
+		exit(err)
+	}
+	var fn *function.Function
+	for _, f := range fns {
+		if f.ID == cmd.Flag("function").Value.String() {
+			copied := f
+			fn = &copied
+			break
+		}
+	}
+	if fn == nil {
+		exit(fmt.Errorf("function not found: %s", cmd.Flag("function").Value.String()))
+	}
+
+	opts := runner.BackfillOpts{
+		Function: *fn,
+		From:     from,
+		To:       to,
+		Rate:     rate,
+		DryRun:   dryRun,
+		Events:   rw,
+	}
+	if !dryRun {
+		if opts.State, err = conf.State.Service.Concrete.Manager(ctx); err != nil {
+			exit(err)
+		}
+		if opts.Queue, err = conf.Queue.Service.Concrete.Producer(); err != nil {
+			exit(err)
+		}
+	}
+
+	res, err := runner.Backfill(ctx, opts)
+	if err != nil {
+		exit(err)
+	}
+
+	if dryRun {
+		fmt.Printf("%d matching events, %d already backfilled\n", res.Matched, res.Skipped)
+		return
+	}
+	fmt.Printf("%d matching events, %d runs started, %d already backfilled\n", res.Matched, res.Initialized, res.Skipped)
+	if res.Failed > 0 {
+		fmt.Println(cli.RenderError(fmt.Sprintf("%d runs failed to start;  see the logs for details", res.Failed)))
+		os.Exit(1)
+	}
+}
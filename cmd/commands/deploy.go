@@ -66,6 +66,18 @@ func deploy(cmd *cobra.Command, args []string) error {
 		fns = []*function.Function{fn}
 	}
 
+	for _, fn := range fns {
+		if err := fn.ValidateGraph(ctx); err != nil {
+			return fmt.Errorf("The function is not valid: %w", err)
+		}
+	}
+
+	if known, ok := workspaceEvents(ctx); ok {
+		for _, w := range function.Warnings(ctx, fns, known) {
+			fmt.Println(cli.RenderWarning(w))
+		}
+	}
+
 	funcNames := make([]string, 0, len(fns))
 
 	for _, fn := range fns {
@@ -133,6 +145,33 @@ func deploy(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// workspaceEvents returns a func reporting whether an event is known within
+// the current workspace.  Self-hosted APIs check events themselves when
+// functions are deployed, so this returns false if the workspace's events
+// can't be listed.
+func workspaceEvents(ctx context.Context) (func(event string) bool, bool) {
+	s := clistate.RequireState(ctx)
+	if !s.Client.IsCloudAPI() {
+		return nil, false
+	}
+	ws, err := clistate.Workspace(ctx)
+	if err != nil {
+		return nil, false
+	}
+	evts, err := s.Client.AllEvents(ctx, &client.EventQuery{WorkspaceID: &ws.ID})
+	if err != nil {
+		return nil, false
+	}
+	names := map[string]struct{}{}
+	for _, e := range evts {
+		names[e.Name] = struct{}{}
+	}
+	return func(event string) bool {
+		_, ok := names[event]
+		return ok
+	}, true
+}
+
 func deployFunction(ctx context.Context, fn *function.Function) error {
 	s := clistate.RequireState(ctx)
 	if s.Client.IsCloudAPI() {
@@ -20,6 +20,7 @@ func NewCmdDev() *cobra.Command {
 
 	cmd.Flags().String("host", "", "host to run the API on")
 	cmd.Flags().StringP("port", "p", "9999", "port to run the API on")
+	cmd.Flags().String("ui-port", "8288", "port to serve the web UI on, or 0 to disable the UI")
 	cmd.Flags().String("dir", ".", "directory to load functions from")
 
 	return cmd
@@ -44,9 +45,26 @@ func doDev(cmd *cobra.Command, args []string) {
 	if host != "" {
 		conf.EventAPI.Addr = host
 	}
-	dir := cmd.Flag("dir").Value.String()
+	uiPort, err := strconv.Atoi(cmd.Flag("ui-port").Value.String())
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	opts := devserver.StartOpts{
+		Dir: cmd.Flag("dir").Value.String(),
+	}
+	if uiPort != 0 {
+		// The UI can resend events, so only serve it on localhost unless a
+		// host is given.
+		uiHost := host
+		if uiHost == "" {
+			uiHost = "127.0.0.1"
+		}
+		opts.UIAddr = fmt.Sprintf("%s:%d", uiHost, uiPort)
+	}
 
-	err = devserver.NewDevServer(ctx, *conf, dir)
+	err = devserver.NewDevServer(ctx, *conf, opts)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/inngest/inngest/cmd/commands/internal/table"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/inngest/client"
+	"github.com/inngest/inngest/inngest/clistate"
+	"github.com/inngest/inngest/pkg/cli"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdFunctions() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "functions",
+		Aliases: []string{"fn"},
+		Short:   "Inspects functions, and manages functions deployed to a self-hosted Inngest core API",
+	}
+
+	versions := &cobra.Command{
+		Use:     "versions [function-id]",
+		Short:   "Lists every version of a function, newest first",
+		Example: "inngest functions versions my-function",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := functionVersions(cmd.Context(), args[0]); err != nil {
+				fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+				os.Exit(1)
+			}
+		},
+	}
+
+	rollback := &cobra.Command{
+		Use:     "rollback [function-id] [version]",
+		Short:   "Makes a prior version of a function live",
+		Long:    "Makes a prior version of a function live, replacing the current live version.\n\nThis reverts a bad deploy without rebuilding or redeploying the function's config.",
+		Example: "inngest functions rollback my-function 3",
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rollbackFunction(cmd.Context(), args[0], args[1]); err != nil {
+				fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+				os.Exit(1)
+			}
+		},
+	}
+
+	graph := &cobra.Command{
+		Use:   "graph [dir]",
+		Short: "Renders a function's steps and edges as a Mermaid or Graphviz graph",
+		Example: `inngest fn graph
+inngest fn graph ./my-function --format dot | dot -Tsvg > graph.svg`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			if err := functionGraph(cmd.Context(), dir, cmd.Flag("format").Value.String()); err != nil {
+				fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+				os.Exit(1)
+			}
+		},
+	}
+	graph.Flags().String("format", inngest.GraphFormatMermaid, "The output format: mermaid or dot")
+
+	cmd.AddCommand(versions)
+	cmd.AddCommand(rollback)
+	cmd.AddCommand(graph)
+	return cmd
+}
+
+// coreAPIClient returns the client for the self-hosted core API.  Function
+// versions are managed via workflows when using Inngest Cloud.
+func coreAPIClient(ctx context.Context) (client.Client, error) {
+	s := clistate.RequireState(ctx)
+	if s.Client.IsCloudAPI() {
+		return nil, fmt.Errorf("This command requires a self-hosted core API.  Set INNGEST_API to your core API's URL, or use `inngest workflows` with Inngest Cloud")
+	}
+	return s.Client, nil
+}
+
+func functionVersions(ctx context.Context, functionID string) error {
+	c, err := coreAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	versions, err := c.FunctionVersions(ctx, functionID)
+	if err != nil {
+		return fmt.Errorf("failed to list function versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("Function %s has no versions", functionID)
+	}
+
+	t := table.New(table.Row{"Version", "Live", "Live since", "Live until", "Last updated"})
+	for _, v := range versions {
+		live := ""
+		if v.ValidFrom != nil && v.ValidTo == nil {
+			live = "Yes"
+		}
+		t.AppendRow(table.Row{v.Version, live, formatTime(v.ValidFrom), formatTime(v.ValidTo), formatTime(&v.UpdatedAt)})
+	}
+	t.Render()
+	return nil
+}
+
+func rollbackFunction(ctx context.Context, functionID, version string) error {
+	v, err := strconv.Atoi(version)
+	if err != nil || v < 1 {
+		return fmt.Errorf("Invalid version: %s", version)
+	}
+
+	c, err := coreAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(cli.BoldStyle.Render(fmt.Sprintf("Rolling back function %s to version %d...", functionID, v)))
+	fv, err := c.RollbackFunction(ctx, functionID, v)
+	if err != nil {
+		return fmt.Errorf("failed to roll back function: %w", err)
+	}
+
+	fmt.Println(cli.BoldStyle.Copy().Foreground(cli.Green).Render(fmt.Sprintf("Version %d is live", fv.Version)))
+	return nil
+}
+
+// functionGraph prints the graph of the function within dir.
+func functionGraph(ctx context.Context, dir, format string) error {
+	fn, err := function.Load(ctx, dir)
+	if err != nil {
+		return err
+	}
+	w, err := fn.Workflow(ctx)
+	if err != nil {
+		return err
+	}
+	g, err := inngest.NewGraph(*w)
+	if err != nil {
+		return err
+	}
+	out, err := g.Render(format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/inngest/inngest/pkg/cli"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/spf13/cobra"
+
+	// Import the default drivers, queues, and state stores.
+	_ "github.com/inngest/inngest/pkg/config/defaults"
+)
+
+func NewCmdKeys() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the source keys used to send events to a self-hosted event API",
+		Long:  "Manage the source keys used to send events to a self-hosted event API.\n\nKeys are stored within the configured datastore.  Once any key is stored, the event API rejects events sent with unknown keys.",
+	}
+	cmd.PersistentFlags().StringP("config", "c", "", "The config file location (defaults to ./inngest.(cue|json) or /etc/inngest.(cue|json)")
+
+	create := &cobra.Command{
+		Use:     "create",
+		Short:   "Create a new source key",
+		Example: "inngest keys create --name billing --prefix billing/",
+		Run:     doCreateKey,
+	}
+	create.Flags().String("name", "", "The name of the source, attached to each event sent with the key")
+	create.Flags().StringSlice("prefix", nil, "Restrict the key to events with the given name prefix.  May be repeated")
+	_ = create.MarkFlagRequired("name")
+
+	del := &cobra.Command{
+		Use:     "delete [key]",
+		Short:   "Delete a source key, revoking access",
+		Example: "inngest keys delete 4d1f...",
+		Args:    cobra.ExactArgs(1),
+		Run:     doDeleteKey,
+	}
+
+	cmd.AddCommand(create, del)
+	return cmd
+}
+
+func doCreateKey(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	name, _ := cmd.Flags().GetString("name")
+	prefixes, _ := cmd.Flags().GetStringSlice("prefix")
+
+	rw, err := keyStore(ctx, cmd)
+	if err != nil {
+		exitWithError(err)
+	}
+	k, err := createSourceKey(ctx, rw, name, prefixes)
+	if err != nil {
+		exitWithError(err)
+	}
+	fmt.Println(k.Key)
+}
+
+func doDeleteKey(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	rw, err := keyStore(ctx, cmd)
+	if err != nil {
+		exitWithError(err)
+	}
+	if _, err := rw.SourceKey(ctx, args[0]); err != nil {
+		exitWithError(err)
+	}
+	if err := rw.DeleteSourceKey(ctx, args[0]); err != nil {
+		exitWithError(err)
+	}
+	fmt.Println(cli.BoldStyle.Copy().Foreground(cli.Green).Render("Key deleted"))
+}
+
+// keyStore returns the source key store of the configured datastore.
+func keyStore(ctx context.Context, cmd *cobra.Command) (coredata.SourceKeyStore, error) {
+	locs := []string{}
+	if c := cmd.Flag("config").Value.String(); c != "" {
+		locs = []string{c}
+	}
+	conf, err := config.Load(ctx, locs...)
+	if err != nil {
+		return nil, err
+	}
+	return conf.DataStore.Service.Concrete.ReadWriter(ctx)
+}
+
+// createSourceKey stores a new, randomly generated source key.
+func createSourceKey(ctx context.Context, w coredata.SourceKeyWriter, name string, prefixes []string) (coredata.SourceKey, error) {
+	byt := make([]byte, 32)
+	if _, err := rand.Read(byt); err != nil {
+		return coredata.SourceKey{}, err
+	}
+	k := coredata.SourceKey{
+		Key:           hex.EncodeToString(byt),
+		Name:          name,
+		EventPrefixes: prefixes,
+	}
+	return k, w.CreateSourceKey(ctx, k)
+}
+
+func exitWithError(err error) {
+	fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+	os.Exit(1)
+}
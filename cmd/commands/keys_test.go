@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSourceKey(t *testing.T) {
+	ctx := context.Background()
+	store := inmemorydatastore.NewInMemorySourceKeyStore()
+
+	a, err := createSourceKey(ctx, store, "billing", []string{"billing/"})
+	require.NoError(t, err)
+	require.Len(t, a.Key, 64)
+	b, err := createSourceKey(ctx, store, "billing", nil)
+	require.NoError(t, err)
+	require.NotEqual(t, a.Key, b.Key)
+
+	found, err := store.SourceKey(ctx, a.Key)
+	require.NoError(t, err)
+	require.Equal(t, a, *found)
+}
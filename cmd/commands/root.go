@@ -69,10 +69,17 @@ func Execute() {
 	rootCmd.AddCommand(NewCmdInit())
 	rootCmd.AddCommand(NewCmdRun())
 	rootCmd.AddCommand(NewCmdDeploy())
+	rootCmd.AddCommand(NewCmdValidate())
+	rootCmd.AddCommand(NewCmdFunctions())
+	rootCmd.AddCommand(NewCmdRuns())
 	rootCmd.AddCommand(NewCmdActions())
 	rootCmd.AddCommand(NewCmdDev())
+	rootCmd.AddCommand(NewCmdSend())
+	rootCmd.AddCommand(NewCmdTest())
 	rootCmd.AddCommand(NewCmdVersion())
 	rootCmd.AddCommand(NewCmdServe())
+	rootCmd.AddCommand(NewCmdBackfill())
+	rootCmd.AddCommand(NewCmdKeys())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -32,6 +32,10 @@ type runFunctionOpts struct {
 	// If true, prints extra information about step input/output to stdout during
 	// a function's run.
 	verbose bool
+
+	// If true, runs the function using a virtual clock which skips waits and
+	// pause timeouts.
+	fastForward bool
 }
 
 func NewCmdRun() *cobra.Command {
@@ -48,6 +52,7 @@ func NewCmdRun() *cobra.Command {
 	cmd.Flags().Int64VarP(&replayCount, "count", "c", 10, "Number of events to replay in replay mode")
 	cmd.Flags().StringP("event-id", "e", "", "Specifies a specific event to replay in replay mode")
 	cmd.Flags().BoolP("snapshot", "s", false, "Returns found or generated events as JSON instead of running them")
+	cmd.Flags().Bool("fast-forward", false, "Skips step waits and pause timeouts by fast-forwarding time whenever the function is idle")
 
 	return cmd
 }
@@ -115,8 +120,9 @@ func doRun(cmd *cobra.Command, args []string) {
 	}
 
 	opts := runFunctionOpts{
-		verbose:   hasVerboseFlag,
-		eventFunc: eventFunc,
+		verbose:     hasVerboseFlag,
+		eventFunc:   eventFunc,
+		fastForward: cmd.Flag("fast-forward").Value.String() == "true",
 	}
 
 	if err = runFunction(ctx, *fn, opts); err != nil {
@@ -293,11 +299,12 @@ func runFunction(ctx context.Context, fn function.Function, opts runFunctionOpts
 
 	// Run the function.
 	ui, err := cli.NewRunUI(ctx, cli.RunUIOpts{
-		Function:  fn,
-		Events:    evts,
-		Seed:      runSeed,
-		LogBuffer: buf,
-		Verbose:   opts.verbose || len(evts) == 1,
+		Function:    fn,
+		Events:      evts,
+		Seed:        runSeed,
+		LogBuffer:   buf,
+		Verbose:     opts.verbose || len(evts) == 1,
+		FastForward: opts.fastForward,
 	})
 	if err != nil {
 		return err
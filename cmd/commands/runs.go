@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/inngest/inngest/cmd/commands/internal/table"
+	"github.com/inngest/inngest/inngest/client"
+	"github.com/inngest/inngest/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdRuns() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runs",
+		Short: "Inspects and manages function runs within a self-hosted Inngest core API",
+	}
+
+	exit := func(err error) {
+		fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+		os.Exit(1)
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "Lists recent function runs, newest first",
+		Example: "inngest runs list --function my-function --status failed",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			limit, _ := cmd.Flags().GetInt("limit")
+			query := client.FunctionRunsQuery{
+				FunctionID: cmd.Flag("function").Value.String(),
+				Status:     strings.ToUpper(cmd.Flag("status").Value.String()),
+				Limit:      limit,
+			}
+			if err := listRuns(cmd.Context(), query); err != nil {
+				exit(err)
+			}
+		},
+	}
+	list.Flags().StringP("function", "f", "", "Only list runs of the given function ID")
+	list.Flags().StringP("status", "s", "", "Only list runs with the given status: scheduled, running, completed, failed, or cancelled")
+	list.Flags().IntP("limit", "l", 0, "The maximum number of runs to list (defaults to 50)")
+
+	get := &cobra.Command{
+		Use:     "get [run-id]",
+		Short:   "Shows a function run's event, and each step's output, errors and attempts",
+		Example: "inngest runs get 01G9XKRKW8ZW04F5CNN6HHDPBT",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getRun(cmd.Context(), args[0]); err != nil {
+				exit(err)
+			}
+		},
+	}
+
+	cancel := &cobra.Command{
+		Use:     "cancel [run-id]",
+		Short:   "Cancels a function run, preventing any further steps from running",
+		Example: "inngest runs cancel 01G9XKRKW8ZW04F5CNN6HHDPBT",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cancelRun(cmd.Context(), args[0]); err != nil {
+				exit(err)
+			}
+		},
+	}
+
+	rerun := &cobra.Command{
+		Use:     "rerun [run-id]",
+		Short:   "Reruns a function run from a step, eg. after the step failed, clearing the results of the step and every step after it",
+		Example: "inngest runs rerun 01G9XKRKW8ZW04F5CNN6HHDPBT --from my-step",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rerunRun(cmd.Context(), args[0], cmd.Flag("from").Value.String()); err != nil {
+				exit(err)
+			}
+		},
+	}
+	rerun.Flags().String("from", "", "The ID of the step to rerun the function from")
+	_ = rerun.MarkFlagRequired("from")
+
+	cmd.AddCommand(list)
+	cmd.AddCommand(get)
+	cmd.AddCommand(cancel)
+	cmd.AddCommand(rerun)
+	return cmd
+}
+
+func listRuns(ctx context.Context, query client.FunctionRunsQuery) error {
+	c, err := coreAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	runs, err := c.FunctionRuns(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+	if viper.GetBool("json") {
+		return printJSON(runs)
+	}
+	if len(runs) == 0 {
+		fmt.Println(cli.FeintStyle.Render("No runs found"))
+		return nil
+	}
+
+	t := table.New(table.Row{"Run ID", "Function", "Status", "Started", "Pending steps"})
+	for _, r := range runs {
+		t.AppendRow(table.Row{r.ID, r.FunctionID, strings.ToLower(r.Status), formatTime(&r.StartedAt), r.Pending})
+	}
+	t.Render()
+	return nil
+}
+
+func getRun(ctx context.Context, runID string) error {
+	c, err := coreAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	run, err := c.FunctionRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to load run: %w", err)
+	}
+	if run == nil {
+		return fmt.Errorf("Run %s not found", runID)
+	}
+	if viper.GetBool("json") {
+		return printJSON(run)
+	}
+
+	printRun(*run)
+	return nil
+}
+
+func cancelRun(ctx context.Context, runID string) error {
+	c, err := coreAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	run, err := c.CancelRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel run: %w", err)
+	}
+	if run == nil {
+		return fmt.Errorf("Run %s not found", runID)
+	}
+
+	fmt.Println(cli.BoldStyle.Copy().Foreground(cli.Green).Render(fmt.Sprintf("Run %s is %s", run.ID, strings.ToLower(run.Status))))
+	return nil
+}
+
+func rerunRun(ctx context.Context, runID, stepID string) error {
+	c, err := coreAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(cli.BoldStyle.Render(fmt.Sprintf("Rerunning run %s from step %s...", runID, stepID)))
+	run, err := c.RerunStep(ctx, runID, stepID)
+	if err != nil {
+		return fmt.Errorf("failed to rerun step: %w", err)
+	}
+	if run == nil {
+		return fmt.Errorf("Run %s not found", runID)
+	}
+
+	fmt.Println(cli.BoldStyle.Copy().Foreground(cli.Green).Render(fmt.Sprintf("Step %s has been enqueued", stepID)))
+	return nil
+}
+
+// printRun pretty-prints a run's event, steps and pauses.
+func printRun(run client.FunctionRun) {
+	heading := cli.BoldStyle.Copy().Foreground(cli.Primary)
+
+	fmt.Println()
+	fmt.Println(cli.BoldStyle.Render(fmt.Sprintf("Run %s", run.ID)))
+	fmt.Println(cli.TextStyle.Render(fmt.Sprintf("Function:  %s", run.FunctionID)))
+	fmt.Println(cli.TextStyle.Render(fmt.Sprintf("Status:    %s", strings.ToLower(run.Status))))
+	fmt.Println(cli.TextStyle.Render(fmt.Sprintf("Started:   %s", formatTime(&run.StartedAt))))
+	if run.Pending > 0 {
+		fmt.Println(cli.TextStyle.Render(fmt.Sprintf("Pending:   %d steps", run.Pending)))
+	}
+
+	fmt.Println()
+	fmt.Println(heading.Render("Event"))
+	fmt.Println(indentJSON(run.Event))
+
+	fmt.Println()
+	fmt.Println(heading.Render("Steps"))
+	if len(run.Steps) == 0 {
+		fmt.Println(cli.FeintStyle.Render("No steps have run"))
+	}
+	for _, step := range run.Steps {
+		fmt.Println()
+		fmt.Println(cli.BoldStyle.Render(step.Name) + " " + cli.FeintStyle.Render(fmt.Sprintf("(%s, %d attempts)", step.ID, step.Attempts)))
+		if step.Output != nil {
+			fmt.Println(indentJSON(*step.Output))
+		}
+		if step.Error != nil {
+			fmt.Println(renderStepError(*step.Error))
+		}
+	}
+
+	if len(run.Pauses) > 0 {
+		fmt.Println()
+		fmt.Println(heading.Render("Pending pauses"))
+		t := table.New(table.Row{"After", "Next step", "Event", "Expression", "Expires"})
+		for _, p := range run.Pauses {
+			t.AppendRow(table.Row{p.Outgoing, p.Incoming, deref(p.Event), deref(p.Expression), formatTime(&p.Expires)})
+		}
+		t.Render()
+	}
+	fmt.Println()
+}
+
+func renderStepError(msg string) string {
+	return cli.TextStyle.Copy().Foreground(cli.Red).Render("Error: " + msg)
+}
+
+// indentJSON indents the given JSON for printing, returning the input as-is if
+// it isn't valid JSON.
+func indentJSON(s string) string {
+	buf := &bytes.Buffer{}
+	if err := json.Indent(buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
+func printJSON(v interface{}) error {
+	byt, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(byt))
+	return nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
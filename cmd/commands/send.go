@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/inngest/inngest/cmd/commands/internal/table"
+	"github.com/inngest/inngest/pkg/cli"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdSend() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send [event-name]",
+		Short: "Send an event to the dev server or a self-hosted event API",
+		Example: `# Send an event to the dev server
+inngest send api/user.created --data '{"id": 1}'
+
+# Send events from a file, waiting for the runs they create
+inngest send --file events.json --sync
+
+# Send a fake event to a self-hosted event API, which listens on port 8288 by default
+inngest send api/user.created --fake --url http://localhost:8288 --key my-key`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  doSend,
+	}
+
+	cmd.Flags().StringP("data", "d", "", "The event's data, as JSON")
+	cmd.Flags().StringP("file", "f", "", "A JSON file containing the event, or an array of events, to send")
+	cmd.Flags().Bool("fake", false, "Generate the event from the event definition of functions triggered by the event")
+	cmd.Flags().String("dir", ".", "The directory to load functions from when generating events with --fake")
+	cmd.Flags().String("url", "http://127.0.0.1:9999", "The URL of the event API")
+	cmd.Flags().StringP("key", "k", "dev", "The source key used to send the event")
+	cmd.Flags().Bool("sync", false, "Wait for the runs created by the event")
+	cmd.Flags().Duration("timeout", 5*time.Second, "The time to wait for the runs created by the event with --sync")
+
+	return cmd
+}
+
+func doSend(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	exit := func(err error) {
+		fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+		os.Exit(1)
+	}
+
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+	fake, _ := cmd.Flags().GetBool("fake")
+
+	events, err := sendEvents(ctx, name, cmd.Flag("data").Value.String(), cmd.Flag("file").Value.String(), fake, cmd.Flag("dir").Value.String())
+	if err != nil {
+		exit(err)
+	}
+
+	// Events are sent asynchronously by default, as waiting for runs requires
+	// the event API to store events.
+	var timeout time.Duration
+	if sync, _ := cmd.Flags().GetBool("sync"); sync {
+		timeout, _ = cmd.Flags().GetDuration("timeout")
+	}
+	resp, err := sendToEventAPI(ctx, cmd.Flag("url").Value.String(), cmd.Flag("key").Value.String(), events, timeout)
+	if err != nil {
+		exit(err)
+	}
+
+	if viper.GetBool("json") {
+		if err := printJSON(resp); err != nil {
+			exit(err)
+		}
+		return
+	}
+
+	if timeout == 0 {
+		t := table.New(table.Row{"Event", "Event ID"})
+		for n, id := range resp.IDs {
+			name := ""
+			if n < len(events) {
+				name = events[n].Name
+			}
+			t.AppendRow(table.Row{name, id})
+		}
+		t.Render()
+		return
+	}
+
+	t := table.New(table.Row{"Event", "Event ID", "Function", "Run ID"})
+	for n, id := range resp.IDs {
+		name := ""
+		if n < len(events) {
+			name = events[n].Name
+		}
+		runs := resp.Runs[id]
+		if len(runs) == 0 {
+			t.AppendRow(table.Row{name, id, "", ""})
+			continue
+		}
+		for _, run := range runs {
+			t.AppendRow(table.Row{name, id, run.FunctionID, run.RunID})
+		}
+	}
+	t.Render()
+}
+
+// sendEvents returns the events to send, from either the --data or --file flags
+// or generated from function definitions if fake is true.
+func sendEvents(ctx context.Context, name, data, file string, fake bool, dir string) ([]event.Event, error) {
+	if fake {
+		if name == "" {
+			return nil, fmt.Errorf("An event name is required when generating events")
+		}
+		evt, err := generateEvent(ctx, name, dir)
+		if err != nil {
+			return nil, err
+		}
+		return []event.Event{evt}, nil
+	}
+
+	if file != "" {
+		byt, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading events: %w", err)
+		}
+		events := []event.Event{}
+		if trimmed := bytes.TrimSpace(byt); len(trimmed) > 0 && trimmed[0] == '[' {
+			err = json.Unmarshal(trimmed, &events)
+		} else {
+			evt := event.Event{}
+			err = json.Unmarshal(trimmed, &evt)
+			events = append(events, evt)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing events: %w", err)
+		}
+		for n := range events {
+			if events[n].Name == "" {
+				events[n].Name = name
+			}
+			if events[n].Name == "" {
+				return nil, fmt.Errorf("Event %d has no name", n)
+			}
+		}
+		return events, nil
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("An event name or --file is required")
+	}
+	evt := event.Event{Name: name, Data: map[string]interface{}{}}
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &evt.Data); err != nil {
+			return nil, fmt.Errorf("--data must be a JSON object: %w", err)
+		}
+	}
+	return []event.Event{evt}, nil
+}
+
+// generateEvent generates fake data for the given event from the event
+// definitions of each function within dir triggered by the event.
+func generateEvent(ctx context.Context, name, dir string) (event.Event, error) {
+	fns, err := function.LoadRecursive(ctx, dir)
+	if err != nil {
+		return event.Event{}, err
+	}
+
+	triggers := []function.Trigger{}
+	for _, fn := range fns {
+		for _, t := range fn.Triggers {
+			if t.EventTrigger != nil && t.Event == name && t.Definition != nil {
+				triggers = append(triggers, t)
+			}
+		}
+	}
+	if len(triggers) == 0 {
+		return event.Event{}, fmt.Errorf("No functions within %s define the event %s", dir, name)
+	}
+
+	evt, err := function.GenerateTriggerData(ctx, time.Now().UnixNano(), triggers)
+	if err != nil {
+		return event.Event{}, fmt.Errorf("error generating event: %w", err)
+	}
+	evt.Name = name
+	return evt, nil
+}
+
+// sendResponse is the response from the event API.
+type sendResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+	// IDs lists the ID of each event sent, in order.
+	IDs []string `json:"ids"`
+	// Runs lists the runs created by each event, keyed by event ID.
+	Runs map[string][]struct {
+		FunctionID string `json:"functionID"`
+		RunID      string `json:"runID"`
+	} `json:"runs,omitempty"`
+}
+
+// sendToEventAPI sends the events to the event API at the given URL.  If timeout
+// is non-zero the events are sent synchronously, waiting up to timeout for the
+// runs that the events create.
+func sendToEventAPI(ctx context.Context, apiURL, key string, events []event.Event, timeout time.Duration) (*sendResponse, error) {
+	u, err := url.Parse(strings.TrimSuffix(apiURL, "/") + "/e/" + url.PathEscape(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if timeout > 0 {
+		u.RawQuery = url.Values{
+			"sync":    []string{"true"},
+			"timeout": []string{timeout.String()},
+		}.Encode()
+	}
+
+	byt, err := json.Marshal(events)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(byt))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sendResponse{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("unexpected response from event API (%d): %s", resp.StatusCode, string(body))
+	}
+	if resp.StatusCode >= 300 {
+		if result.Error == "" {
+			result.Error = http.StatusText(resp.StatusCode)
+		}
+		return nil, fmt.Errorf("error sending events: %s", result.Error)
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendEvents(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("it requires a name or file", func(t *testing.T) {
+		_, err := sendEvents(ctx, "", "", "", false, ".")
+		require.Error(t, err)
+		_, err = sendEvents(ctx, "", "", "", true, ".")
+		require.Error(t, err)
+	})
+
+	t.Run("it sends an event with data", func(t *testing.T) {
+		events, err := sendEvents(ctx, "test/event", `{"id": 1}`, "", false, ".")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "test/event", events[0].Name)
+		require.EqualValues(t, 1, events[0].Data["id"])
+
+		_, err = sendEvents(ctx, "test/event", `[1]`, "", false, ".")
+		require.Error(t, err)
+	})
+
+	t.Run("it reads events from a file", func(t *testing.T) {
+		dir := t.TempDir()
+		single := filepath.Join(dir, "single.json")
+		require.NoError(t, os.WriteFile(single, []byte(`{"data": {"id": 1}}`), 0600))
+		many := filepath.Join(dir, "many.json")
+		require.NoError(t, os.WriteFile(many, []byte(` [{"name": "a"}, {"name": "b"}]`), 0600))
+
+		// Events without a name use the given name.
+		events, err := sendEvents(ctx, "test/event", "", single, false, ".")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "test/event", events[0].Name)
+
+		_, err = sendEvents(ctx, "", "", single, false, ".")
+		require.Error(t, err)
+
+		events, err = sendEvents(ctx, "", "", many, false, ".")
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "a", events[0].Name)
+		require.Equal(t, "b", events[1].Name)
+
+		_, err = sendEvents(ctx, "", "", filepath.Join(dir, "missing.json"), false, ".")
+		require.Error(t, err)
+	})
+}
+
+func TestSendToEventAPI(t *testing.T) {
+	ctx := context.Background()
+
+	var req *http.Request
+	var received []event.Event
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		received = nil
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			_, _ = w.Write([]byte(`{"status": 400, "error": "invalid event"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": 200, "ids": ["evt-1"], "runs": {"evt-1": [{"functionID": "fn", "runID": "run-1"}]}}`))
+	}))
+	defer srv.Close()
+
+	events := []event.Event{{Name: "test/event"}}
+
+	t.Run("it sends events asynchronously without a timeout", func(t *testing.T) {
+		resp, err := sendToEventAPI(ctx, srv.URL+"/", "my key", events, 0)
+		require.NoError(t, err)
+		require.Equal(t, []string{"evt-1"}, resp.IDs)
+		require.Equal(t, "/e/my%20key", req.URL.EscapedPath())
+		require.Empty(t, req.URL.RawQuery)
+		require.Equal(t, events, received)
+	})
+
+	t.Run("it waits for runs with a timeout", func(t *testing.T) {
+		resp, err := sendToEventAPI(ctx, srv.URL, "key", events, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, "true", req.URL.Query().Get("sync"))
+		require.Equal(t, "1s", req.URL.Query().Get("timeout"))
+		require.Len(t, resp.Runs["evt-1"], 1)
+		require.Equal(t, "run-1", resp.Runs["evt-1"][0].RunID)
+	})
+
+	t.Run("it returns API errors", func(t *testing.T) {
+		status = http.StatusBadRequest
+		defer func() { status = http.StatusOK }()
+		_, err := sendToEventAPI(ctx, srv.URL, "key", events, 0)
+		require.ErrorContains(t, err, "invalid event")
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/inngest/inngest/pkg/coreapi"
 	"github.com/inngest/inngest/pkg/execution/executor"
 	"github.com/inngest/inngest/pkg/execution/runner"
+	"github.com/inngest/inngest/pkg/publisher"
 	"github.com/inngest/inngest/pkg/service"
 	"github.com/spf13/cobra"
 
@@ -18,15 +19,16 @@ import (
 )
 
 const (
-	ServeExecutor = "executor"
-	ServeRunner   = "runner"
-	ServeEventAPI = "event-api"
-	ServeCoreAPI  = "core-api"
+	ServeExecutor  = "executor"
+	ServeRunner    = "runner"
+	ServeEventAPI  = "event-api"
+	ServeCoreAPI   = "core-api"
+	ServePublisher = "publisher"
 )
 
 var (
 	serveConf = ""
-	serveArgs = []string{ServeExecutor, ServeRunner, ServeEventAPI, ServeCoreAPI}
+	serveArgs = []string{ServeExecutor, ServeRunner, ServeEventAPI, ServeCoreAPI, ServePublisher}
 )
 
 func NewCmdServe() *cobra.Command {
@@ -66,17 +68,27 @@ func serve(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// Load functions from the data store, so that functions deployed or
+	// rolled back via the core API are run.
+	data, err := conf.DataStore.Service.Concrete.ReadWriter(ctx)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
 	svc := []service.Service{}
 	for _, name := range args {
 		switch name {
 		case ServeEventAPI:
 			svc = append(svc, api.NewService(*conf))
 		case ServeRunner:
-			svc = append(svc, runner.NewService(*conf))
+			svc = append(svc, runner.NewService(*conf, runner.WithExecutionLoader(data)))
 		case ServeExecutor:
-			svc = append(svc, executor.NewService(*conf))
+			svc = append(svc, executor.NewService(*conf, executor.WithExecutionLoader(data)))
 		case ServeCoreAPI:
 			svc = append(svc, coreapi.NewService(*conf))
+		case ServePublisher:
+			svc = append(svc, publisher.NewService(*conf))
 		default:
 			fmt.Println("Not implemented")
 			os.Exit(1)
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/inngest/inngest/pkg/cli"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/functiontest"
+	"github.com/inngest/inngest/pkg/logger"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdTest() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [dir]",
+		Short: "Test functions using the fixtures stored next to each function's config",
+		Long: `Test functions using the fixtures stored next to each function's config.
+
+Each *` + functiontest.FixtureSuffix + ` file holds an event which triggers the function, the mocked
+output of each step, and the expected results of the run.  Functions run locally
+with every step mocked, asserting which steps ran and each step's output.`,
+		Example: `inngest test
+inngest test ./functions --format junit --output report.xml`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  doTest,
+	}
+
+	cmd.Flags().String("format", "text", "The output format: text, junit or json")
+	cmd.Flags().StringP("output", "o", "", "Write the report to the given file instead of stdout")
+
+	return cmd
+}
+
+func doTest(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	exit := func(err error) {
+		fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+		os.Exit(1)
+	}
+
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	format := cmd.Flag("format").Value.String()
+	if format != "text" && format != "junit" && format != "json" {
+		exit(fmt.Errorf("Unknown format: %s", format))
+	}
+
+	// The executor logs each step, and the in-memory event stream warns when
+	// messages have no subscribers;  only show these logs when verbose.
+	if !viper.GetBool("verbose") {
+		ctx = logger.With(ctx, zerolog.Nop())
+		log.SetOutput(io.Discard)
+	}
+
+	results, err := runTests(ctx, dir)
+	if err != nil {
+		exit(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if path := cmd.Flag("output").Value.String(); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			exit(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case "junit":
+		err = functiontest.WriteJUnit(w, results)
+	case "json":
+		err = functiontest.WriteJSON(w, results)
+	default:
+		printTestResults(w, results)
+	}
+	if err != nil {
+		exit(err)
+	}
+
+	for _, r := range results {
+		if !r.Passed {
+			os.Exit(1)
+		}
+	}
+}
+
+// runTests runs every fixture of each function within dir.
+func runTests(ctx context.Context, dir string) ([]functiontest.Result, error) {
+	fns, err := function.LoadRecursive(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []functiontest.Result{}
+	for _, fn := range fns {
+		fixtures, err := functiontest.LoadFixtures(*fn)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fixtures {
+			r, err := functiontest.Run(ctx, *fn, f)
+			if err != nil {
+				return nil, fmt.Errorf("error running fixture %s: %w", f.Path, err)
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func printTestResults(w io.Writer, results []functiontest.Result) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, cli.FeintStyle.Render("No fixtures found"))
+		return
+	}
+
+	failed := 0
+	for _, r := range results {
+		name := fmt.Sprintf("%s/%s", r.Function, r.Fixture)
+		duration := cli.FeintStyle.Render(fmt.Sprintf("(%s)", r.Duration.Round(time.Millisecond)))
+		if r.Passed {
+			fmt.Fprintln(w, cli.BoldStyle.Copy().Foreground(cli.Green).Render("PASS")+" "+name+" "+duration)
+			continue
+		}
+		failed++
+		fmt.Fprintln(w, cli.BoldStyle.Copy().Foreground(cli.Red).Render("FAIL")+" "+name+" "+duration)
+		for _, msg := range r.Failures {
+			fmt.Fprintln(w, "     "+cli.TextStyle.Copy().Foreground(cli.Red).Render(msg))
+		}
+	}
+
+	fmt.Fprintln(w)
+	summary := fmt.Sprintf("%d passed, %d failed", len(results)-failed, failed)
+	if failed > 0 {
+		fmt.Fprintln(w, cli.BoldStyle.Copy().Foreground(cli.Red).Render(summary))
+		return
+	}
+	fmt.Fprintln(w, cli.BoldStyle.Copy().Foreground(cli.Green).Render(summary))
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/inngest/inngest/pkg/cli"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdValidate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "validate [dir]",
+		Short:   "Validate functions without deploying them",
+		Long:    "Validate functions without deploying them.\n\nIf no directory is provided, the function in the current directory is validated.  If a directory is provided, all functions within the directory are validated recursively.\n\nFunctions are checked for cycles, steps which can never run, invalid durations and expressions which reference steps that won't have run.  Async steps waiting for events which are never received are reported when deploying, as this depends on the events known to Inngest.",
+		Example: "inngest validate ./functions",
+		Args:    cobra.MaximumNArgs(1),
+		Run:     doValidate,
+	}
+	return cmd
+}
+
+func doValidate(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	var (
+		fns []*function.Function
+		err error
+	)
+	if len(args) > 0 {
+		fns, err = function.LoadRecursive(ctx, args[0])
+	} else {
+		var fn *function.Function
+		fn, err = function.Load(ctx, ".")
+		fns = []*function.Function{fn}
+	}
+	if err != nil {
+		fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
+		os.Exit(1)
+	}
+
+	invalid := false
+	for _, fn := range fns {
+		if err := fn.ValidateGraph(ctx); err != nil {
+			fmt.Println(cli.RenderError(fmt.Sprintf("%s: %s", fn.Name, err)))
+			invalid = true
+		}
+	}
+	if invalid {
+		os.Exit(1)
+	}
+	fmt.Println(cli.BoldStyle.Copy().Foreground(cli.Green).Render(fmt.Sprintf("%d function(s) are valid", len(fns))))
+}
@@ -48,6 +48,20 @@ type Client interface {
 
 	// DeployFunction deploys a function for a given environment. Live determines if the function is a draft or live.
 	DeployFunction(ctx context.Context, config string, env string, live bool) (*FunctionVersion, error)
+	// FunctionVersions returns every version of the given function, newest first.
+	FunctionVersions(ctx context.Context, functionID string) ([]FunctionVersion, error)
+	// RollbackFunction makes the given prior version of a function live.
+	RollbackFunction(ctx context.Context, functionID string, version int) (*FunctionVersion, error)
+
+	// FunctionRuns returns recent function runs matching the query, newest first.
+	FunctionRuns(ctx context.Context, query FunctionRunsQuery) ([]FunctionRun, error)
+	// FunctionRun returns a single function run by ID, or nil if the run isn't found.
+	FunctionRun(ctx context.Context, runID string) (*FunctionRun, error)
+	// CancelRun cancels a function run, preventing any further steps from running.
+	CancelRun(ctx context.Context, runID string) (*FunctionRun, error)
+	// RerunStep enqueues a step of a function run, clearing the results of the
+	// step and every step after it.
+	RerunStep(ctx context.Context, runID, stepID string) (*FunctionRun, error)
 
 	// Action returns a single action by DSN.  If no version is specified, this will return the latest
 	// major/minor version.  If a major version is supplied with no minor version, this will return the
@@ -51,3 +51,58 @@ func (c httpClient) DeployFunction(ctx context.Context, config string, env strin
 
 	return data.DeployFunction, nil
 }
+
+// FunctionVersions returns every version of the given function, newest first.
+func (c httpClient) FunctionVersions(ctx context.Context, functionID string) ([]FunctionVersion, error) {
+	query := `
+		query FunctionVersions($functionId: ID!) {
+			functionVersions(functionId: $functionId) {
+				functionId version config validFrom validTo createdAt updatedAt
+			}
+		}`
+
+	type response struct {
+		FunctionVersions []FunctionVersion
+	}
+	resp, err := c.DoGQL(ctx, Params{Query: query, Variables: map[string]interface{}{
+		"functionId": functionID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &response{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling function versions: %w", err)
+	}
+
+	return data.FunctionVersions, nil
+}
+
+// RollbackFunction makes the given prior version of a function live.
+func (c httpClient) RollbackFunction(ctx context.Context, functionID string, version int) (*FunctionVersion, error) {
+	query := `
+		mutation RollbackFunction($functionId: ID!, $version: Int!) {
+			rollbackFunction(functionId: $functionId, version: $version) {
+				functionId version config validFrom validTo createdAt updatedAt
+			}
+		}`
+
+	type response struct {
+		RollbackFunction *FunctionVersion
+	}
+	resp, err := c.DoGQL(ctx, Params{Query: query, Variables: map[string]interface{}{
+		"functionId": functionID,
+		"version":    version,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &response{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling function version: %w", err)
+	}
+
+	return data.RollbackFunction, nil
+}
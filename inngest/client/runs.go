@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FunctionRun is a single run of a function, as returned by the core API.
+type FunctionRun struct {
+	ID          string      `json:"id"`
+	FunctionID  string      `json:"functionId"`
+	WorkflowID  string      `json:"workflowId"`
+	Status      string      `json:"status"`
+	StartedAt   time.Time   `json:"startedAt"`
+	ScheduledAt time.Time   `json:"scheduledAt"`
+	Pending     int         `json:"pending"`
+	Event       string      `json:"event"`
+	Steps       []StepState `json:"steps"`
+	Pauses      []RunPause  `json:"pauses"`
+}
+
+// StepState is the output or error of a step within a function run.
+type StepState struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Output   *string `json:"output"`
+	Error    *string `json:"error"`
+	Attempts int     `json:"attempts"`
+}
+
+// RunPause is a pending pause within a function run.
+type RunPause struct {
+	ID         string    `json:"id"`
+	Outgoing   string    `json:"outgoing"`
+	Incoming   string    `json:"incoming"`
+	Expires    time.Time `json:"expires"`
+	Event      *string   `json:"event"`
+	Expression *string   `json:"expression"`
+	OnTimeout  bool      `json:"onTimeout"`
+}
+
+// FunctionRunsQuery filters function runs.  Empty fields do not filter runs.
+type FunctionRunsQuery struct {
+	FunctionID string
+	// Status is the run status, eg. "FAILED".
+	Status string
+	Limit  int
+}
+
+const functionRunFields = `
+	id functionId workflowId status startedAt scheduledAt pending event
+	steps { id name output error attempts }
+	pauses { id outgoing incoming expires event expression onTimeout }
+`
+
+func (c httpClient) FunctionRuns(ctx context.Context, query FunctionRunsQuery) ([]FunctionRun, error) {
+	q := `
+		query FunctionRuns($query: FunctionRunsQuery) {
+			functionRuns(query: $query) {` + functionRunFields + `}
+		}`
+
+	vars := map[string]interface{}{}
+	if query.FunctionID != "" {
+		vars["functionId"] = query.FunctionID
+	}
+	if query.Status != "" {
+		vars["status"] = query.Status
+	}
+	if query.Limit > 0 {
+		vars["limit"] = query.Limit
+	}
+
+	type response struct {
+		FunctionRuns []FunctionRun
+	}
+	resp, err := c.DoGQL(ctx, Params{Query: q, Variables: map[string]interface{}{
+		"query": vars,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &response{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling function runs: %w", err)
+	}
+	return data.FunctionRuns, nil
+}
+
+func (c httpClient) FunctionRun(ctx context.Context, runID string) (*FunctionRun, error) {
+	q := `
+		query FunctionRun($id: ID!) {
+			functionRun(id: $id) {` + functionRunFields + `}
+		}`
+
+	type response struct {
+		FunctionRun *FunctionRun
+	}
+	resp, err := c.DoGQL(ctx, Params{Query: q, Variables: map[string]interface{}{
+		"id": runID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &response{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling function run: %w", err)
+	}
+	return data.FunctionRun, nil
+}
+
+func (c httpClient) CancelRun(ctx context.Context, runID string) (*FunctionRun, error) {
+	q := `
+		mutation CancelRun($runId: ID!) {
+			cancelRun(runId: $runId) {` + functionRunFields + `}
+		}`
+
+	type response struct {
+		CancelRun *FunctionRun
+	}
+	resp, err := c.DoGQL(ctx, Params{Query: q, Variables: map[string]interface{}{
+		"runId": runID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &response{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling function run: %w", err)
+	}
+	return data.CancelRun, nil
+}
+
+func (c httpClient) RerunStep(ctx context.Context, runID, stepID string) (*FunctionRun, error) {
+	q := `
+		mutation RerunStep($runId: ID!, $stepId: ID!) {
+			rerunStep(runId: $runId, stepId: $stepId) {` + functionRunFields + `}
+		}`
+
+	type response struct {
+		RerunStep *FunctionRun
+	}
+	resp, err := c.DoGQL(ctx, Params{Query: q, Variables: map[string]interface{}{
+		"runId":  runID,
+		"stepId": stepID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &response{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling function run: %w", err)
+	}
+	return data.RerunStep, nil
+}
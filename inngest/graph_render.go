@@ -0,0 +1,178 @@
+package inngest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// GraphFormatMermaid renders graphs as Mermaid flowcharts.
+	GraphFormatMermaid = "mermaid"
+	// GraphFormatDot renders graphs using the Graphviz DOT language.
+	GraphFormatDot = "dot"
+)
+
+// Render renders the workflow's graph in the given format, either
+// GraphFormatMermaid or GraphFormatDot.  Edges are labelled with their
+// expressions, waits and async metadata;  async edges are drawn dashed.
+func (g Graph) Render(format string) (string, error) {
+	steps := make([]Step, len(g.workflow.Steps))
+	copy(steps, g.workflow.Steps)
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].ID < steps[j].ID
+	})
+
+	edges := []GraphEdge{}
+	for _, e := range g.Edges() {
+		edges = append(edges, e.(GraphEdge))
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].Outgoing.ID() != edges[j].Outgoing.ID() {
+			return edges[i].Outgoing.ID() < edges[j].Outgoing.ID()
+		}
+		return edges[i].Incoming.ID() < edges[j].Incoming.ID()
+	})
+
+	switch format {
+	case GraphFormatMermaid:
+		return g.mermaid(steps, edges), nil
+	case GraphFormatDot:
+		return g.dot(steps, edges), nil
+	default:
+		return "", fmt.Errorf("unknown graph format: %s", format)
+	}
+}
+
+// mermaidEscaper escapes characters within Mermaid labels.  Labels are
+// rendered as markup, so expressions such as `a < b && c > d` must be escaped
+// using Mermaid's entity codes.
+var mermaidEscaper = strings.NewReplacer(
+	`"`, "#quot;",
+	"&", "#amp;",
+	"<", "#lt;",
+	">", "#gt;",
+)
+
+func (g Graph) mermaid(steps []Step, edges []GraphEdge) string {
+	// Step IDs may contain characters which aren't valid within Mermaid node
+	// IDs, so each node is given a generated ID.
+	ids := map[string]string{TriggerName: "trigger"}
+	for n, s := range steps {
+		ids[s.ID] = fmt.Sprintf("step%d", n+1)
+	}
+
+	label := func(lines []string) string {
+		escaped := make([]string, len(lines))
+		for n, l := range lines {
+			escaped[n] = mermaidEscaper.Replace(l)
+		}
+		return `"` + strings.Join(escaped, "<br>") + `"`
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("flowchart TD\n")
+	fmt.Fprintf(b, "  trigger([%s])\n", label(g.triggerLabel()))
+	for _, s := range steps {
+		fmt.Fprintf(b, "  %s[%s]\n", ids[s.ID], label(stepLabel(s)))
+	}
+	for _, e := range edges {
+		arrow := "-->"
+		if isAsync(e.WorkflowEdge) {
+			arrow = "-.->"
+		}
+		if lines := edgeLabel(e.WorkflowEdge); len(lines) > 0 {
+			arrow += "|" + label(lines) + "|"
+		}
+		fmt.Fprintf(b, "  %s %s %s\n", ids[e.Outgoing.ID()], arrow, ids[e.Incoming.ID()])
+	}
+	return b.String()
+}
+
+func (g Graph) dot(steps []Step, edges []GraphEdge) string {
+	quote := func(lines ...string) string {
+		s := strings.Join(lines, "\n")
+		s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+		return `"` + s + `"`
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "digraph %s {\n", quote(g.workflow.ID))
+	fmt.Fprintf(b, "  %s [label=%s, shape=oval];\n", quote(TriggerName), quote(g.triggerLabel()...))
+	for _, s := range steps {
+		fmt.Fprintf(b, "  %s [label=%s, shape=box];\n", quote(s.ID), quote(stepLabel(s)...))
+	}
+	for _, e := range edges {
+		attrs := []string{}
+		if lines := edgeLabel(e.WorkflowEdge); len(lines) > 0 {
+			attrs = append(attrs, "label="+quote(lines...))
+		}
+		if isAsync(e.WorkflowEdge) {
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(b, "  %s -> %s", quote(e.Outgoing.ID()), quote(e.Incoming.ID()))
+		if len(attrs) > 0 {
+			fmt.Fprintf(b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// triggerLabel returns a line for each of the workflow's triggers.
+func (g Graph) triggerLabel() []string {
+	lines := []string{}
+	for _, t := range g.workflow.Triggers {
+		switch {
+		case t.EventTrigger != nil:
+			line := t.Event
+			if t.Expression != nil && *t.Expression != "" {
+				line += " if " + *t.Expression
+			}
+			lines = append(lines, line)
+		case t.CronTrigger != nil:
+			lines = append(lines, "cron: "+t.Cron)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, TriggerName)
+	}
+	return lines
+}
+
+func stepLabel(s Step) []string {
+	if s.Name == "" || s.Name == s.ID {
+		return []string{s.ID}
+	}
+	return []string{s.Name, "(" + s.ID + ")"}
+}
+
+// edgeLabel returns a line for each of the edge's conditions.
+func edgeLabel(e Edge) []string {
+	if e.Metadata == nil {
+		return nil
+	}
+	lines := []string{}
+	if e.Metadata.If != "" {
+		lines = append(lines, "if: "+e.Metadata.If)
+	}
+	if e.Metadata.Wait != nil {
+		lines = append(lines, "wait: "+*e.Metadata.Wait)
+	}
+	if am := e.Metadata.AsyncEdgeMetadata; am != nil {
+		line := fmt.Sprintf("async: %s, ttl %s", am.Event, am.TTL)
+		if am.OnTimeout {
+			line += ", on timeout"
+		}
+		lines = append(lines, line)
+		if am.Match != nil && *am.Match != "" {
+			lines = append(lines, "match: "+*am.Match)
+		}
+	}
+	return lines
+}
+
+func isAsync(e Edge) bool {
+	return e.Metadata != nil && e.Metadata.AsyncEdgeMetadata != nil
+}
@@ -0,0 +1,102 @@
+package inngest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func renderWorkflow() Workflow {
+	wait := "24h"
+	match := "async.data.id == event.data.id"
+	expr := `event.data.plan != "free"`
+	return Workflow{
+		ID: "onboarding",
+		Triggers: []Trigger{
+			{EventTrigger: &EventTrigger{Event: "user/signup", Expression: &expr}},
+			{CronTrigger: &CronTrigger{Cron: "0 * * * *"}},
+		},
+		Steps: []Step{
+			{ID: "welcome", Name: "Send welcome"},
+			{ID: "follow-up", Name: "follow-up"},
+			{ID: "nudge", Name: "Nudge"},
+		},
+		Edges: []Edge{
+			{Outgoing: TriggerName, Incoming: "welcome"},
+			{
+				Outgoing: "welcome",
+				Incoming: "follow-up",
+				Metadata: &EdgeMetadata{If: `steps.welcome.sent == true`, Wait: &wait},
+			},
+			{
+				Outgoing: "welcome",
+				Incoming: "nudge",
+				Metadata: &EdgeMetadata{AsyncEdgeMetadata: &AsyncEdgeMetadata{
+					Event:     "user/upgraded",
+					TTL:       "7d",
+					Match:     &match,
+					OnTimeout: true,
+				}},
+			},
+		},
+	}
+}
+
+func TestGraph_renderMermaid(t *testing.T) {
+	g, err := NewGraph(renderWorkflow())
+	require.NoError(t, err)
+
+	out, err := g.Render(GraphFormatMermaid)
+	require.NoError(t, err)
+	require.Equal(t, `flowchart TD
+  trigger(["user/signup if event.data.plan != #quot;free#quot;<br>cron: 0 * * * *"])
+  step1["follow-up"]
+  step2["Nudge<br>(nudge)"]
+  step3["Send welcome<br>(welcome)"]
+  trigger --> step3
+  step3 -->|"if: steps.welcome.sent == true<br>wait: 24h"| step1
+  step3 -.->|"async: user/upgraded, ttl 7d, on timeout<br>match: async.data.id == event.data.id"| step2
+`, out)
+}
+
+func TestGraph_renderMermaidEscaping(t *testing.T) {
+	expr := "event.data.n<10 && event.data.m>2"
+	g, err := NewGraph(Workflow{
+		ID:       "escaping",
+		Triggers: []Trigger{{EventTrigger: &EventTrigger{Event: "test/event", Expression: &expr}}},
+		Steps:    []Step{{ID: "first", Name: "<first>"}},
+		Edges: []Edge{
+			{Outgoing: TriggerName, Incoming: "first", Metadata: &EdgeMetadata{If: `event.data.a == "b" || event.data.n>=5`}},
+		},
+	})
+	require.NoError(t, err)
+
+	out, err := g.Render(GraphFormatMermaid)
+	require.NoError(t, err)
+	require.Equal(t, `flowchart TD
+  trigger(["test/event if event.data.n#lt;10 #amp;#amp; event.data.m#gt;2"])
+  step1["#lt;first#gt;<br>(first)"]
+  trigger -->|"if: event.data.a == #quot;b#quot; || event.data.n#gt;=5"| step1
+`, out)
+}
+
+func TestGraph_renderDot(t *testing.T) {
+	g, err := NewGraph(renderWorkflow())
+	require.NoError(t, err)
+
+	out, err := g.Render(GraphFormatDot)
+	require.NoError(t, err)
+	require.Equal(t, `digraph "onboarding" {
+  "$trigger" [label="user/signup if event.data.plan != \"free\"\ncron: 0 * * * *", shape=oval];
+  "follow-up" [label="follow-up", shape=box];
+  "nudge" [label="Nudge\n(nudge)", shape=box];
+  "welcome" [label="Send welcome\n(welcome)", shape=box];
+  "$trigger" -> "welcome";
+  "welcome" -> "follow-up" [label="if: steps.welcome.sent == true\nwait: 24h"];
+  "welcome" -> "nudge" [label="async: user/upgraded, ttl 7d, on timeout\nmatch: async.data.id == event.data.id", style=dashed];
+}
+`, out)
+
+	_, err = g.Render("svg")
+	require.Error(t, err)
+}
@@ -2,12 +2,18 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"regexp"
+	"strconv"
+	"time"
 
+	"github.com/inngest/inngest/pkg/api/ratelimit"
 	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
 	"github.com/inngest/inngest/pkg/event"
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
@@ -20,16 +26,48 @@ type Options struct {
 
 	EventHandler EventHandler
 	Logger       *zerolog.Logger
+
+	// SourceKeys loads source keys which aren't defined within config.
+	SourceKeys coredata.SourceKeyReader
+	// Limiter rate limits events sent with each key.  If nil, events are
+	// not rate limited.
+	Limiter ratelimit.Limiter
+	// Events reads events recorded by the runner, used to return the runs
+	// created by each event in synchronous mode.
+	Events coredata.EventReader
+	// Functions loads the functions triggered by each event, used to validate
+	// events against each function's event definitions.  If nil, events
+	// are not validated.
+	Functions coredata.ExecutionFunctionLoader
 }
 
 const (
 	// DefaultMaxSize represents the maximum size of the event payload we process,
 	// currently 256KB.
 	DefaultMaxSize = 256 * 1024
+
+	// DefaultSyncTimeout is the default time to wait for runs to be created
+	// when sending events with ?sync=true.
+	DefaultSyncTimeout = 5 * time.Second
+	// MaxSyncTimeout is the maximum time to wait for runs to be created when
+	// sending events with ?sync=true.
+	MaxSyncTimeout = 30 * time.Second
+
+	// syncPollInterval is the interval used to check for created runs.
+	syncPollInterval = 20 * time.Millisecond
+
+	// anonymousBucket is the rate limit bucket shared by all requests sent
+	// with unknown keys.  This can't collide with a source key, as keys
+	// can't contain "$".
+	anonymousBucket = "$anonymous"
 )
 
 var (
 	EventPathRegex = regexp.MustCompile("^/e/([a-zA-Z0-9-_]+)$")
+	// CloudEventPathRegex matches the path used to send CloudEvents.
+	CloudEventPathRegex = regexp.MustCompile("^/ce/([a-zA-Z0-9-_]+)$")
+	// WebhookPathRegex matches the path used to send payloads to webhooks.
+	WebhookPathRegex = regexp.MustCompile("^/w/([a-zA-Z0-9-_]+)$")
 )
 
 func NewAPI(o Options) (*API, error) {
@@ -39,15 +77,26 @@ func NewAPI(o Options) (*API, error) {
 		o.Config.EventAPI.MaxSize = DefaultMaxSize
 	}
 
+	webhooks, err := newWebhooks(context.Background(), o.Config.EventAPI.Webhooks)
+	if err != nil {
+		return nil, err
+	}
+
 	api := &API{
-		config:  o.Config,
-		handler: o.EventHandler,
-		log:     &logger,
+		config:   o.Config,
+		handler:  o.EventHandler,
+		log:      &logger,
+		keys:     newSourceKeys(o.Config.EventAPI.Keys, o.SourceKeys, o.Config.EventAPI.RequireKeys),
+		ingester: NewIngester(o),
+		events:   o.Events,
+		webhooks: webhooks,
 	}
 
 	http.HandleFunc("/", api.HealthCheck)
 	http.HandleFunc("/health", api.HealthCheck)
 	http.HandleFunc("/e/", api.ReceiveEvent)
+	http.HandleFunc("/ce/", api.ReceiveCloudEvent)
+	http.HandleFunc("/w/", api.ReceiveWebhook)
 
 	return api, nil
 }
@@ -55,8 +104,12 @@ func NewAPI(o Options) (*API, error) {
 type API struct {
 	config config.Config
 
-	handler EventHandler
-	log     *zerolog.Logger
+	handler  EventHandler
+	log      *zerolog.Logger
+	keys     *sourceKeys
+	ingester *Ingester
+	events   coredata.EventReader
+	webhooks map[string]webhook
 
 	server *http.Server
 }
@@ -85,24 +138,60 @@ func (a API) HealthCheck(w http.ResponseWriter, r *http.Request) {
 func (a API) ReceiveEvent(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
+	key, body, ok := a.readRequest(w, r, EventPathRegex)
+	if !ok {
+		return
+	}
+
+	events, err := parseBody(body)
+	if err != nil {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusBadRequest,
+			Error:      "Unable to process event payload",
+		})
+		return
+	}
+
+	a.handleEvents(w, r, limitBucket(key), key, events)
+}
+
+// readRequest authenticates the key within the request's path using the given
+// regular expression, then reads the request body.  If the request is invalid
+// this writes the response and returns false.
+func (a API) readRequest(w http.ResponseWriter, r *http.Request, path *regexp.Regexp) (*coredata.SourceKey, []byte, bool) {
 	if r.ContentLength > int64(a.config.EventAPI.MaxSize) {
 		a.writeResponse(w, apiResponse{
 			StatusCode: http.StatusRequestEntityTooLarge,
 			Error:      "Payload larger than maximum allowed",
 		})
-		return
+		return nil, nil, false
 	}
 
-	matches := EventPathRegex.FindStringSubmatch(r.URL.Path)
+	matches := path.FindStringSubmatch(r.URL.Path)
 	if matches == nil || len(matches) != 2 {
 		a.writeResponse(w, apiResponse{
 			StatusCode: http.StatusUnauthorized,
 			Error:      "API Key is required",
 		})
-		return
+		return nil, nil, false
 	}
 
-	// TODO: Implement key matching from core data loader.
+	key, err := a.keys.authenticate(r.Context(), matches[1])
+	if errors.Is(err, ErrUnknownKey) {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusUnauthorized,
+			Error:      "Invalid API key",
+		})
+		return nil, nil, false
+	}
+	if err != nil {
+		a.log.Error().Err(err).Msg("error loading source key")
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusInternalServerError,
+			Error:      "Unable to authenticate API key",
+		})
+		return nil, nil, false
+	}
 
 	body, err := io.ReadAll(io.LimitReader(r.Body, int64(a.config.EventAPI.MaxSize)))
 	if err != nil {
@@ -110,18 +199,62 @@ func (a API) ReceiveEvent(w http.ResponseWriter, r *http.Request) {
 			StatusCode: http.StatusBadRequest,
 			Error:      "Could not read event payload",
 		})
+		return nil, nil, false
+	}
+
+	return key, body, true
+}
+
+// limitBucket returns the rate limit bucket for events sent with the given
+// source key.  Requests with unknown keys share a single bucket, ensuring that
+// arbitrary keys can't be used to avoid limits.
+func limitBucket(key *coredata.SourceKey) string {
+	if key == nil {
+		return anonymousBucket
+	}
+	return key.Key
+}
+
+// handleEvents processes events sent with the given key, writing the response.
+// This prepares the events using the ingester, which authorizes each event
+// against the source key and checks rate limits using the given bucket, then
+// publishes each event via the handler.
+func (a API) handleEvents(w http.ResponseWriter, r *http.Request, bucket string, key *coredata.SourceKey, events []*event.Event) {
+	sync, _ := strconv.ParseBool(r.URL.Query().Get("sync"))
+	if sync && a.events == nil {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusBadRequest,
+			Error:      "Synchronous mode is not available",
+		})
 		return
 	}
 
-	events, err := parseBody(body)
+	invalid, err := a.ingester.Ingest(r.Context(), bucket, key, events)
+	var ierr *IngestError
+	if errors.As(err, &ierr) {
+		if ierr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(ierr.RetryAfter.Seconds()))))
+		}
+		a.writeResponse(w, apiResponse{
+			StatusCode:       ierr.StatusCode,
+			Error:            ierr.Message,
+			ValidationErrors: ierr.ValidationErrors,
+		})
+		return
+	}
 	if err != nil {
 		a.writeResponse(w, apiResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      "Unable to process event payload",
+			StatusCode: http.StatusInternalServerError,
+			Error:      err.Error(),
 		})
 		return
 	}
 
+	ids := make([]string, len(events))
+	for n, evt := range events {
+		ids[n] = evt.ID
+	}
+
 	eg := &errgroup.Group{}
 	for _, evt := range events {
 		copied := evt
@@ -138,11 +271,82 @@ func (a API) ReceiveEvent(w http.ResponseWriter, r *http.Request) {
 		a.writeResponse(w, apiResponse{
 			StatusCode: http.StatusBadRequest,
 			Error:      err.Error(),
+			IDs:        ids,
+
+			ValidationErrors: invalid,
 		})
+		return
 	}
 
-	a.writeResponse(w, apiResponse{
+	resp := apiResponse{
 		StatusCode: http.StatusOK,
 		Message:    fmt.Sprintf("Received %d events", len(events)),
-	})
+		IDs:        ids,
+
+		ValidationErrors: invalid,
+	}
+
+	if sync {
+		resp = a.waitForRuns(r, resp)
+	}
+
+	a.writeResponse(w, resp)
+}
+
+// waitForRuns waits for the runner to record the runs created by each event
+// in the response, adding the runs to the response.  If the runs aren't
+// recorded before the timeout elapses, this returns a 202 (Accepted) with
+// the runs recorded so far.  This must only be called when the API has an
+// event store.
+func (a API) waitForRuns(r *http.Request, resp apiResponse) apiResponse {
+	timeout := DefaultSyncTimeout
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			timeout = d
+		}
+	}
+	if timeout > MaxSyncTimeout {
+		timeout = MaxSyncTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	resp.Runs = map[string][]runResponse{}
+	pending := append([]string{}, resp.IDs...)
+
+	ticker := time.NewTicker(syncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		remaining := []string{}
+		for _, id := range pending {
+			evt, err := a.events.Event(ctx, id)
+			if err != nil {
+				if !errors.Is(err, coredata.ErrEventNotFound) && ctx.Err() == nil {
+					a.log.Error().Err(err).Str("id", id).Msg("error loading event")
+				}
+				remaining = append(remaining, id)
+				continue
+			}
+			runs := make([]runResponse, len(evt.Runs))
+			for n, run := range evt.Runs {
+				runs[n] = runResponse{FunctionID: run.FunctionID, RunID: run.Identifier.RunID.String()}
+			}
+			resp.Runs[id] = runs
+		}
+
+		pending = remaining
+		if len(pending) == 0 {
+			return resp
+		}
+
+		select {
+		case <-ctx.Done():
+			resp.StatusCode = http.StatusAccepted
+			resp.Message = fmt.Sprintf("Received %d events; timed out waiting for runs", len(resp.IDs))
+			return resp
+		case <-ticker.C:
+		}
+	}
 }
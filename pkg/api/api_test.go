@@ -0,0 +1,396 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/api/ratelimit"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records all events handled by the API.
+type recorder struct {
+	lock   sync.Mutex
+	events []event.Event
+}
+
+func (r *recorder) handle(ctx context.Context, evt *event.Event) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, *evt)
+	return nil
+}
+
+func newTestAPI(t *testing.T, c config.Config, o Options) (*API, *recorder) {
+	t.Helper()
+	if c.EventAPI.MaxSize == 0 {
+		c.EventAPI.MaxSize = DefaultMaxSize
+	}
+	rec := &recorder{}
+	log := zerolog.Nop()
+	webhooks, err := newWebhooks(context.Background(), c.EventAPI.Webhooks)
+	require.NoError(t, err)
+	o.Config = c
+	o.Logger = &log
+	return &API{
+		config:   c,
+		handler:  rec.handle,
+		log:      &log,
+		keys:     newSourceKeys(c.EventAPI.Keys, o.SourceKeys, c.EventAPI.RequireKeys),
+		ingester: NewIngester(o),
+		events:   o.Events,
+		webhooks: webhooks,
+	}, rec
+}
+
+func send(a *API, path string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	a.ReceiveEvent(w, r)
+	return w
+}
+
+func TestReceiveEvent_sourceKeys(t *testing.T) {
+	ctx := context.Background()
+
+	store := inmemorydatastore.NewInMemorySourceKeyStore()
+	require.NoError(t, store.CreateSourceKey(ctx, coredata.SourceKey{
+		Key:  "stored-key",
+		Name: "stored",
+	}))
+
+	c := config.Config{}
+	c.EventAPI.Keys = []coredata.SourceKey{
+		{Key: "config-key", Name: "billing", EventPrefixes: []string{"billing/"}},
+	}
+	a, rec := newTestAPI(t, c, Options{SourceKeys: store})
+
+	t.Run("unknown keys are rejected", func(t *testing.T) {
+		w := send(a, "/e/unknown", `{"name":"billing/paid","data":{}}`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Empty(t, rec.events)
+	})
+
+	t.Run("config keys attach the source", func(t *testing.T) {
+		w := send(a, "/e/config-key", `{"name":"billing/paid","data":{},"source":"spoofed"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, rec.events, 1)
+		require.Equal(t, "billing", rec.events[0].Source)
+	})
+
+	t.Run("keys are restricted to event prefixes", func(t *testing.T) {
+		w := send(a, "/e/config-key", `[{"name":"billing/paid","data":{}},{"name":"user/created","data":{}}]`)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Len(t, rec.events, 1)
+	})
+
+	t.Run("datastore keys are accepted", func(t *testing.T) {
+		w := send(a, "/e/stored-key", `{"name":"user/created","data":{}}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, rec.events, 2)
+		require.Equal(t, "stored", rec.events[1].Source)
+	})
+}
+
+func TestReceiveEvent_datastoreKeys(t *testing.T) {
+	ctx := context.Background()
+
+	// Keys aren't required until a key is stored within the datastore.
+	store := inmemorydatastore.NewInMemorySourceKeyStore()
+	a, rec := newTestAPI(t, config.Config{}, Options{SourceKeys: store})
+
+	w := send(a, "/e/unknown", `{"name":"user/created","data":{}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, rec.events, 1)
+
+	// Once stored, keys are required even if no keys are defined within
+	// config.
+	require.NoError(t, store.CreateSourceKey(ctx, coredata.SourceKey{Key: "stored-key", Name: "stored"}))
+
+	w = send(a, "/e/unknown", `{"name":"user/created","data":{}}`)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Len(t, rec.events, 1)
+
+	w = send(a, "/e/stored-key", `{"name":"user/created","data":{}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, rec.events, 2)
+}
+
+func TestReceiveEvent_keysNotRequired(t *testing.T) {
+	a, rec := newTestAPI(t, config.Config{}, Options{})
+
+	w := send(a, "/e/any", `{"name":"user/created","data":{},"source":"spoofed"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, rec.events, 1)
+	require.Equal(t, "", rec.events[0].Source)
+
+	required := config.Config{}
+	required.EventAPI.RequireKeys = true
+	a, _ = newTestAPI(t, required, Options{})
+	w = send(a, "/e/any", `{"name":"user/created","data":{}}`)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestReceiveEvent_rateLimit(t *testing.T) {
+	c := config.Config{}
+	c.EventAPI.RateLimit = &config.RateLimit{Rate: 1, Burst: 2, PerEvent: true}
+	c.EventAPI.Keys = []coredata.SourceKey{
+		{Key: "key", Name: "key"},
+		{Key: "other", Name: "other"},
+		{Key: "another", Name: "another"},
+	}
+	a, rec := newTestAPI(t, c, Options{Limiter: ratelimit.NewInMemory(1, 2)})
+
+	w := send(a, "/e/key", `[{"name":"a","data":{}},{"name":"a","data":{}},{"name":"b","data":{}}]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, rec.events, 3)
+
+	w = send(a, "/e/key", `{"name":"a","data":{}}`)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+	require.Len(t, rec.events, 3)
+
+	// Limits apply to each event name.
+	w = send(a, "/e/key", `{"name":"b","data":{}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Limits apply to each key.
+	w = send(a, "/e/other", `{"name":"a","data":{}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Batches larger than the burst size can never be sent.
+	w = send(a, "/e/another", `[{"name":"a","data":{}},{"name":"a","data":{}},{"name":"a","data":{}}]`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Empty(t, w.Header().Get("Retry-After"))
+
+	// Rejected requests don't take tokens from any bucket.
+	w = send(a, "/e/other", `[{"name":"b","data":{}},{"name":"a","data":{}},{"name":"a","data":{}}]`)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	w = send(a, "/e/other", `[{"name":"b","data":{}},{"name":"b","data":{}}]`)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReceiveEvent_rateLimitUnknownKeys(t *testing.T) {
+	c := config.Config{}
+	c.EventAPI.RateLimit = &config.RateLimit{Rate: 1, Burst: 1}
+	a, _ := newTestAPI(t, c, Options{Limiter: ratelimit.NewInMemory(1, 1)})
+
+	// Unknown keys share a single bucket, so random keys can't avoid limits.
+	w := send(a, "/e/random-1", `{"name":"a","data":{}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = send(a, "/e/random-2", `{"name":"a","data":{}}`)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestReceiveEvent_ids(t *testing.T) {
+	a, rec := newTestAPI(t, config.Config{}, Options{})
+
+	before := time.Now().UnixMilli()
+	w := send(a, "/e/key", `[{"name":"a","data":{}},{"id":"custom","name":"b","data":{},"ts":1}]`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	resp := apiResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "Received 2 events", resp.Message)
+	require.Len(t, resp.IDs, 2)
+	require.Equal(t, "custom", resp.IDs[1])
+	require.Nil(t, resp.Runs)
+
+	// The generated ID and timestamp are published with the event.
+	require.Len(t, rec.events, 2)
+	for _, evt := range rec.events {
+		switch evt.Name {
+		case "a":
+			require.Equal(t, resp.IDs[0], evt.ID)
+			_, err := ulid.Parse(evt.ID)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, evt.Timestamp, before)
+		case "b":
+			require.Equal(t, "custom", evt.ID)
+			require.EqualValues(t, 1, evt.Timestamp)
+		}
+	}
+}
+
+func TestReceiveEvent_maxDelay(t *testing.T) {
+	a, rec := newTestAPI(t, config.Config{
+		Execution: config.Execution{MaxDelay: time.Hour},
+	}, Options{})
+
+	future := time.Now().Add(2 * time.Hour).UnixMilli()
+	w := send(a, "/e/key", fmt.Sprintf(`[{"name":"a","data":{}},{"name":"b","data":{},"ts":%d}]`, future))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "Event timestamp is more than 1h0m0s in the future: b")
+	require.Empty(t, rec.events)
+
+	soon := time.Now().Add(30 * time.Minute).UnixMilli()
+	w = send(a, "/e/key", fmt.Sprintf(`{"name":"b","data":{},"ts":%d}`, soon))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, rec.events, 1)
+}
+
+func TestReceiveEvent_sync(t *testing.T) {
+	store := inmemorydatastore.NewInMemoryEventStore()
+	a, _ := newTestAPI(t, config.Config{}, Options{Events: store})
+
+	runID := ulid.MustNew(ulid.Now(), nil)
+	a.handler = func(ctx context.Context, evt *event.Event) error {
+		// Record the event asynchronously, as the runner would.
+		go func() {
+			<-time.After(50 * time.Millisecond)
+			_ = store.SaveEvent(context.Background(), coredata.Event{
+				ID:    evt.ID,
+				Name:  evt.Name,
+				Event: *evt,
+				Runs: []coredata.EventRun{
+					{FunctionID: "fn", Identifier: state.Identifier{RunID: runID}},
+				},
+			})
+		}()
+		return nil
+	}
+
+	w := send(a, "/e/key?sync=true", `{"name":"a","data":{}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp := apiResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.IDs, 1)
+	require.Equal(t, map[string][]runResponse{
+		resp.IDs[0]: {{FunctionID: "fn", RunID: runID.String()}},
+	}, resp.Runs)
+
+	t.Run("it times out waiting for runs", func(t *testing.T) {
+		a.handler = func(ctx context.Context, evt *event.Event) error { return nil }
+		w := send(a, "/e/key?sync=true&timeout=30ms", `{"name":"a","data":{}}`)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		resp := apiResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.IDs, 1)
+		require.Empty(t, resp.Runs)
+	})
+
+	t.Run("it rejects sync mode without an event store before publishing", func(t *testing.T) {
+		a, rec := newTestAPI(t, config.Config{}, Options{})
+		w := send(a, "/e/key?sync=true", `{"name":"a","data":{}}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Empty(t, rec.events)
+	})
+}
+
+func TestReceiveEvent_validation(t *testing.T) {
+	ctx := context.Background()
+
+	loader := &inmemorydatastore.MemoryExecutionLoader{}
+	require.NoError(t, loader.SetFunctions(ctx, []*function.Function{
+		{
+			ID:   "signup",
+			Name: "signup",
+			Triggers: []function.Trigger{
+				{EventTrigger: &function.EventTrigger{
+					Event: "test/signup",
+					Definition: &function.EventDefinition{
+						Format: function.FormatCue,
+						Def:    `{ name: "test/signup", data: { email: string } }`,
+					},
+				}},
+			},
+			Steps: map[string]function.Step{
+				"first": {
+					ID:      "first",
+					Name:    "first",
+					Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+					After:   []function.After{{Step: inngest.TriggerName}},
+				},
+			},
+		},
+	}))
+
+	valid := `{"name":"test/signup","data":{"email":"a@example.com"}}`
+	invalid := `{"name":"test/signup","data":{}}`
+	spoofed := `{"name":"test/signup","data":{"email":"a@example.com"},"metadata":{"validationErrors":{"signup":"spoofed"}}}`
+
+	for _, mode := range []config.ValidationMode{config.ValidationWarn, config.ValidationDrop, config.ValidationReject, config.ValidationOff} {
+		t.Run(string(mode), func(t *testing.T) {
+			c := config.Config{}
+			c.EventAPI.Validation = mode
+			a, rec := newTestAPI(t, c, Options{Functions: loader})
+
+			w := send(a, "/e/key", valid)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Len(t, rec.events, 1)
+			require.Nil(t, rec.events[0].ValidationErrors())
+
+			// Validation errors are never accepted from the client.
+			w = send(a, "/e/key", spoofed)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Len(t, rec.events, 2)
+			require.Nil(t, rec.events[1].ValidationErrors())
+
+			w = send(a, "/e/key", invalid)
+			resp := apiResponse{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			switch mode {
+			case config.ValidationOff:
+				require.Equal(t, http.StatusOK, w.Code)
+				require.Empty(t, resp.ValidationErrors)
+				require.Len(t, rec.events, 3)
+				require.Nil(t, rec.events[2].ValidationErrors())
+			case config.ValidationReject:
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				require.Len(t, resp.ValidationErrors, 1)
+				require.Len(t, rec.events, 2)
+			default:
+				require.Equal(t, http.StatusOK, w.Code)
+				require.Len(t, resp.ValidationErrors, 1)
+				require.Equal(t, "signup", resp.ValidationErrors[0].FunctionID)
+				require.Equal(t, resp.IDs[0], resp.ValidationErrors[0].EventID)
+				require.Contains(t, resp.ValidationErrors[0].Error, "email")
+
+				require.Len(t, rec.events, 3)
+				require.Contains(t, rec.events[2].ValidationErrors(), "signup")
+			}
+		})
+	}
+}
+
+func TestSchemaCache(t *testing.T) {
+	ctx := context.Background()
+	c := newSchemaCache()
+
+	fn := function.Function{ID: "signup"}
+	trigger := function.Trigger{EventTrigger: &function.EventTrigger{
+		Event:      "test/signup",
+		Definition: &function.EventDefinition{Format: function.FormatCue, Def: `{ data: { email: string } }`},
+	}}
+
+	a, err := c.schema(ctx, fn, trigger)
+	require.NoError(t, err)
+	b, err := c.schema(ctx, fn, trigger)
+	require.NoError(t, err)
+	require.Same(t, a, b, "Definitions must only be compiled once")
+
+	// New versions of the definition are recompiled.
+	trigger.Definition = &function.EventDefinition{Format: function.FormatCue, Def: `{ data: { id: string } }`}
+	b, err = c.schema(ctx, fn, trigger)
+	require.NoError(t, err)
+	require.NotSame(t, a, b)
+	require.NoError(t, b.ValidateEvent(event.Event{Name: "test/signup", Data: map[string]interface{}{"id": "1"}}))
+	require.Error(t, b.ValidateEvent(event.Event{Name: "test/signup", Data: map[string]interface{}{"email": "a"}}))
+}
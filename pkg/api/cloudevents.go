@@ -0,0 +1,229 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/inngest/inngest/pkg/event"
+)
+
+const (
+	// cloudEventsContentType is the content type of a CloudEvent sent in
+	// structured mode.
+	cloudEventsContentType = "application/cloudevents+json"
+	// cloudEventsBatchContentType is the content type of CloudEvents sent in
+	// batched mode.
+	cloudEventsBatchContentType = "application/cloudevents-batch+json"
+	// cloudEventsSpecVersion is the only CloudEvents spec version supported.
+	cloudEventsSpecVersion = "1.0"
+	// cloudEventsHeaderPrefix prefixes each attribute sent in binary mode.
+	cloudEventsHeaderPrefix = "ce-"
+)
+
+// ReceiveCloudEvent receives CloudEvents 1.0 sent via the HTTP protocol binding
+// in structured, binary, or batched mode.
+//
+// The CloudEvent's type, id, time and data are mapped to the event's name, ID,
+// timestamp and data.  Every other attribute, including extensions, is stored
+// within the event's metadata.
+func (a API) ReceiveCloudEvent(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	key, body, ok := a.readRequest(w, r, CloudEventPathRegex)
+	if !ok {
+		return
+	}
+
+	events, err := parseCloudEvents(r.Header, body)
+	if err != nil {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusBadRequest,
+			Error:      err.Error(),
+		})
+		return
+	}
+
+	a.handleEvents(w, r, limitBucket(key), key, events)
+}
+
+// parseCloudEvents parses CloudEvents from the request, using the content type
+// to determine whether the request is in structured, binary or batched mode.
+func parseCloudEvents(h http.Header, body []byte) ([]*event.Event, error) {
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+
+	switch mediaType {
+	case cloudEventsBatchContentType:
+		batch := []map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return nil, fmt.Errorf("Unable to process CloudEvents batch: %w", err)
+		}
+		events := make([]*event.Event, len(batch))
+		for n, attrs := range batch {
+			evt, err := parseStructuredCloudEvent(attrs)
+			if err != nil {
+				return nil, err
+			}
+			events[n] = evt
+		}
+		return events, nil
+	case cloudEventsContentType:
+		attrs := map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &attrs); err != nil {
+			return nil, fmt.Errorf("Unable to process CloudEvent: %w", err)
+		}
+		evt, err := parseStructuredCloudEvent(attrs)
+		if err != nil {
+			return nil, err
+		}
+		return []*event.Event{evt}, nil
+	default:
+		evt, err := parseBinaryCloudEvent(h, body)
+		if err != nil {
+			return nil, err
+		}
+		return []*event.Event{evt}, nil
+	}
+}
+
+// parseStructuredCloudEvent creates an event from a CloudEvent's JSON attributes.
+func parseStructuredCloudEvent(raw map[string]json.RawMessage) (*event.Event, error) {
+	attrs := map[string]interface{}{}
+	for k, v := range raw {
+		if k == "data" || k == "data_base64" {
+			continue
+		}
+		var val interface{}
+		if err := json.Unmarshal(v, &val); err != nil {
+			return nil, fmt.Errorf("Invalid CloudEvent attribute '%s': %w", k, err)
+		}
+		attrs[k] = val
+	}
+
+	evt, err := newCloudEvent(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	if b64, ok := raw["data_base64"]; ok {
+		var data string
+		if err := json.Unmarshal(b64, &data); err != nil {
+			return nil, fmt.Errorf("Invalid CloudEvent attribute 'data_base64': %w", err)
+		}
+		evt.Data = map[string]interface{}{"data_base64": data}
+		return evt, nil
+	}
+
+	if data, ok := raw["data"]; ok {
+		if evt.Data, err = cloudEventData(data); err != nil {
+			return nil, err
+		}
+	}
+	return evt, nil
+}
+
+// parseBinaryCloudEvent creates an event from a CloudEvent sent in binary mode,
+// with attributes sent as headers and the body containing the event data.
+func parseBinaryCloudEvent(h http.Header, body []byte) (*event.Event, error) {
+	attrs := map[string]interface{}{}
+	for k, v := range h {
+		k = strings.ToLower(k)
+		if !strings.HasPrefix(k, cloudEventsHeaderPrefix) || len(v) == 0 {
+			continue
+		}
+		// Header values are percent-encoded.
+		val, err := url.PathUnescape(v[0])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CloudEvent header '%s': %w", k, err)
+		}
+		attrs[strings.TrimPrefix(k, cloudEventsHeaderPrefix)] = val
+	}
+	if ct := h.Get("Content-Type"); ct != "" {
+		attrs["datacontenttype"] = ct
+	}
+
+	evt, err := newCloudEvent(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return evt, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	switch {
+	case mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		evt.Data, err = cloudEventData(body)
+	case strings.HasPrefix(mediaType, "text/"):
+		evt.Data = map[string]interface{}{"data": string(body)}
+	default:
+		evt.Data = map[string]interface{}{"data_base64": base64.StdEncoding.EncodeToString(body)}
+	}
+	return evt, err
+}
+
+// newCloudEvent creates an event from the given CloudEvent attributes, storing
+// all unmapped attributes within the event's metadata.
+func newCloudEvent(attrs map[string]interface{}) (*event.Event, error) {
+	for _, k := range []string{"specversion", "id", "source", "type"} {
+		if s, _ := attrs[k].(string); s == "" {
+			return nil, fmt.Errorf("CloudEvent is missing required attribute: %s", k)
+		}
+	}
+	if attrs["specversion"] != cloudEventsSpecVersion {
+		return nil, fmt.Errorf("Unsupported CloudEvents specversion: %s", attrs["specversion"])
+	}
+
+	evt := &event.Event{
+		Name: attrs["type"].(string),
+		ID:   attrs["id"].(string),
+		Data: map[string]interface{}{},
+	}
+
+	if t, ok := attrs["time"]; ok {
+		str, _ := t.(string)
+		ts, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CloudEvent time: %v", t)
+		}
+		evt.Timestamp = ts.UnixMilli()
+	}
+
+	metadata := map[string]interface{}{}
+	for k, v := range attrs {
+		switch k {
+		case "type", "id", "time":
+			continue
+		}
+		metadata[k] = v
+	}
+	evt.Metadata = map[string]interface{}{event.MetadataCloudEvent: metadata}
+
+	return evt, nil
+}
+
+// cloudEventData converts JSON CloudEvent data into event data.  Objects are
+// used as the event's data, and any other values are stored within the "data"
+// field.
+func cloudEventData(raw []byte) (map[string]interface{}, error) {
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("Invalid CloudEvent data: %w", err)
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case nil:
+		return map[string]interface{}{}, nil
+	default:
+		return map[string]interface{}{"data": v}, nil
+	}
+}
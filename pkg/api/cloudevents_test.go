@@ -0,0 +1,210 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCloudEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		events  []*event.Event
+		err     string
+	}{
+		{
+			name:    "structured",
+			headers: map[string]string{"Content-Type": "application/cloudevents+json; charset=utf-8"},
+			body: `{
+				"specversion": "1.0",
+				"type": "com.example.order.created",
+				"source": "/orders",
+				"id": "order-1",
+				"time": "2022-07-01T12:00:00.5Z",
+				"subject": "order",
+				"tenant": "acme",
+				"datacontenttype": "application/json",
+				"data": {"total": 10}
+			}`,
+			events: []*event.Event{
+				{
+					Name:      "com.example.order.created",
+					ID:        "order-1",
+					Timestamp: 1656676800500,
+					Data:      map[string]interface{}{"total": float64(10)},
+					Metadata: map[string]interface{}{
+						event.MetadataCloudEvent: map[string]interface{}{
+							"specversion":     "1.0",
+							"source":          "/orders",
+							"subject":         "order",
+							"tenant":          "acme",
+							"datacontenttype": "application/json",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "structured with base64 data",
+			headers: map[string]string{"Content-Type": "application/cloudevents+json"},
+			body:    `{"specversion":"1.0","type":"a","source":"s","id":"1","data_base64":"aGk="}`,
+			events: []*event.Event{
+				{
+					Name: "a",
+					ID:   "1",
+					Data: map[string]interface{}{"data_base64": "aGk="},
+					Metadata: map[string]interface{}{
+						event.MetadataCloudEvent: map[string]interface{}{"specversion": "1.0", "source": "s"},
+					},
+				},
+			},
+		},
+		{
+			name:    "batch",
+			headers: map[string]string{"Content-Type": "application/cloudevents-batch+json"},
+			body: `[
+				{"specversion":"1.0","type":"a","source":"s","id":"1","data":{"n":1}},
+				{"specversion":"1.0","type":"b","source":"s","id":"2","data":"text"}
+			]`,
+			events: []*event.Event{
+				{
+					Name: "a",
+					ID:   "1",
+					Data: map[string]interface{}{"n": float64(1)},
+					Metadata: map[string]interface{}{
+						event.MetadataCloudEvent: map[string]interface{}{"specversion": "1.0", "source": "s"},
+					},
+				},
+				{
+					Name: "b",
+					ID:   "2",
+					Data: map[string]interface{}{"data": "text"},
+					Metadata: map[string]interface{}{
+						event.MetadataCloudEvent: map[string]interface{}{"specversion": "1.0", "source": "s"},
+					},
+				},
+			},
+		},
+		{
+			name: "binary",
+			headers: map[string]string{
+				"Content-Type":   "application/json",
+				"Ce-Specversion": "1.0",
+				"Ce-Type":        "com.example.order.created",
+				"Ce-Source":      "/orders",
+				"Ce-Id":          "order-1",
+				"Ce-Time":        "2022-07-01T12:00:00Z",
+				"Ce-Tenant":      "acme%20corp",
+			},
+			body: `{"total": 10}`,
+			events: []*event.Event{
+				{
+					Name:      "com.example.order.created",
+					ID:        "order-1",
+					Timestamp: 1656676800000,
+					Data:      map[string]interface{}{"total": float64(10)},
+					Metadata: map[string]interface{}{
+						event.MetadataCloudEvent: map[string]interface{}{
+							"specversion":     "1.0",
+							"source":          "/orders",
+							"tenant":          "acme corp",
+							"datacontenttype": "application/json",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "binary with non-JSON data",
+			headers: map[string]string{
+				"Content-Type":   "application/octet-stream",
+				"Ce-Specversion": "1.0",
+				"Ce-Type":        "a",
+				"Ce-Source":      "s",
+				"Ce-Id":          "1",
+			},
+			body: "hi",
+			events: []*event.Event{
+				{
+					Name: "a",
+					ID:   "1",
+					Data: map[string]interface{}{"data_base64": "aGk="},
+					Metadata: map[string]interface{}{
+						event.MetadataCloudEvent: map[string]interface{}{
+							"specversion":     "1.0",
+							"source":          "s",
+							"datacontenttype": "application/octet-stream",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "missing attributes",
+			headers: map[string]string{"Content-Type": "application/cloudevents+json"},
+			body:    `{"specversion":"1.0","type":"a","id":"1"}`,
+			err:     "CloudEvent is missing required attribute: source",
+		},
+		{
+			name:    "unsupported spec version",
+			headers: map[string]string{"Content-Type": "application/cloudevents+json"},
+			body:    `{"specversion":"0.3","type":"a","source":"s","id":"1"}`,
+			err:     "Unsupported CloudEvents specversion: 0.3",
+		},
+		{
+			name:    "invalid time",
+			headers: map[string]string{"Content-Type": "application/cloudevents+json"},
+			body:    `{"specversion":"1.0","type":"a","source":"s","id":"1","time":"yesterday"}`,
+			err:     "Invalid CloudEvent time: yesterday",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range test.headers {
+				h.Set(k, v)
+			}
+			events, err := parseCloudEvents(h, []byte(test.body))
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.events, events)
+		})
+	}
+}
+
+func TestReceiveCloudEvent(t *testing.T) {
+	a, rec := newTestAPI(t, config.Config{}, Options{})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/ce/key", strings.NewReader(`{"total": 10}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Ce-Specversion", "1.0")
+	r.Header.Set("Ce-Type", "com.example.order.created")
+	r.Header.Set("Ce-Source", "/orders")
+	r.Header.Set("Ce-Id", "order-1")
+	a.ReceiveCloudEvent(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, rec.events, 1)
+	require.Equal(t, "com.example.order.created", rec.events[0].Name)
+	require.Equal(t, "order-1", rec.events[0].ID)
+	require.NotZero(t, rec.events[0].Timestamp)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/ce/key", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/cloudevents+json")
+	a.ReceiveCloudEvent(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Len(t, rec.events, 1)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/inngest/inngest/pkg/api/ratelimit"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog"
+)
+
+// Ingester prepares events for publishing in the same manner as the event API,
+// authorizing and normalizing each event, checking rate limits and validating
+// each event against the functions it triggers.  Other entry points which
+// publish events, such as the core API, use an Ingester so that events can't
+// bypass the event API's checks.
+type Ingester struct {
+	config    config.Config
+	log       *zerolog.Logger
+	limiter   ratelimit.Limiter
+	functions coredata.ExecutionFunctionLoader
+	schemas   *schemaCache
+}
+
+// NewIngester returns an Ingester using the config, logger, limiter and
+// functions within the given options.
+func NewIngester(o Options) *Ingester {
+	log := o.Logger
+	if log == nil {
+		nop := zerolog.Nop()
+		log = &nop
+	}
+	return &Ingester{
+		config:    o.Config,
+		log:       log,
+		limiter:   o.Limiter,
+		functions: o.Functions,
+		schemas:   newSchemaCache(),
+	}
+}
+
+// IngestError is returned when events can't be accepted, containing the HTTP
+// status code and message returned by the event API.
+type IngestError struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is the time to wait before sending rate limited events again.
+	RetryAfter time.Duration
+	// ValidationErrors lists the events which were rejected as they don't
+	// match their definitions.
+	ValidationErrors []ValidationError
+}
+
+func (e *IngestError) Error() string {
+	return e.Message
+}
+
+// Ingest prepares the given events sent with the given source key for
+// publishing, modifying each event in place.  Any source or validation errors
+// sent with each event are removed, ensuring that they can't be spoofed, and
+// each event is given an ID and timestamp if not set.  Rate limits are checked
+// using the given bucket;  if empty, the bucket for the key is used.
+//
+// This returns the events which don't match their definitions, or an
+// *IngestError if the events must not be published.
+func (i *Ingester) Ingest(ctx context.Context, bucket string, key *coredata.SourceKey, events []*event.Event) ([]ValidationError, error) {
+	if bucket == "" {
+		bucket = limitBucket(key)
+	}
+
+	now := time.Now()
+	for _, evt := range events {
+		// Always overwrite the source with the source key used, ensuring that
+		// the source cannot be spoofed.
+		evt.Source = ""
+		if key != nil {
+			if !key.Allows(evt.Name) {
+				return nil, &IngestError{
+					StatusCode: http.StatusForbidden,
+					Message:    fmt.Sprintf("API key is not allowed to send event: %s", evt.Name),
+				}
+			}
+			evt.Source = key.Name
+		}
+
+		if evt.ID == "" {
+			// Always ensure that the event has an ID, for idempotency.
+			evt.ID = ulid.MustNew(ulid.Timestamp(now), rand.Reader).String()
+		}
+		if evt.Timestamp == 0 {
+			evt.Timestamp = now.UnixMilli()
+		}
+		if max := i.config.Execution.MaxDelay; max > 0 && time.UnixMilli(evt.Timestamp).After(now.Add(max)) {
+			// Functions can't be scheduled past the max delay.
+			return nil, &IngestError{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("Event timestamp is more than %s in the future: %s", max, evt.Name),
+			}
+		}
+	}
+
+	if err := i.allow(ctx, bucket, events); err != nil {
+		return nil, err
+	}
+
+	invalid, err := i.validate(ctx, events)
+	if err != nil {
+		// Fail open, ensuring that events are never lost due to an
+		// unavailable datastore.
+		i.log.Error().Err(err).Msg("error validating events")
+	}
+	if len(invalid) > 0 && i.config.EventAPI.Validation == config.ValidationReject {
+		return nil, &IngestError{
+			StatusCode:       http.StatusBadRequest,
+			Message:          "Events do not match their definitions",
+			ValidationErrors: invalid,
+		}
+	}
+	return invalid, nil
+}
+
+// allow checks the rate limits for the given bucket, returning an error if
+// the events are over the limit.
+func (i *Ingester) allow(ctx context.Context, bucket string, events []*event.Event) error {
+	if i.limiter == nil {
+		return nil
+	}
+
+	// Count the tokens required for each bucket.
+	buckets := map[string]int{}
+	for _, evt := range events {
+		b := bucket
+		if i.config.EventAPI.RateLimit != nil && i.config.EventAPI.RateLimit.PerEvent {
+			b = bucket + ":" + evt.Name
+		}
+		buckets[b]++
+	}
+
+	ok, retry, err := i.limiter.Allow(ctx, buckets)
+	if errors.Is(err, ratelimit.ErrExceedsBurst) {
+		// Batches larger than the burst size can never succeed, so this
+		// must not be retried.
+		return &IngestError{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Message:    "Too many events sent at once",
+		}
+	}
+	if err != nil {
+		// Fail open, ensuring that events are never lost due to an
+		// unavailable limiter.
+		i.log.Error().Err(err).Msg("error checking rate limit")
+		return nil
+	}
+	if !ok {
+		return &IngestError{
+			StatusCode: http.StatusTooManyRequests,
+			Message:    "Rate limit exceeded",
+			RetryAfter: retry,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/inngest/inngest/pkg/coredata"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown key")
+)
+
+// sourceKeys authenticates source keys used to send events.  Keys are read
+// from config first, then from the data store.
+type sourceKeys struct {
+	keys  []coredata.SourceKey
+	store coredata.SourceKeyReader
+	// required is true if requests must use a known key.
+	required bool
+}
+
+// newSourceKeys returns a source key registry.  Keys are required if any keys
+// are defined within config or stored within the given store.
+func newSourceKeys(keys []coredata.SourceKey, store coredata.SourceKeyReader, required bool) *sourceKeys {
+	return &sourceKeys{
+		keys:     keys,
+		store:    store,
+		required: required || len(keys) > 0,
+	}
+}
+
+// authenticate returns the source key for the given key.  If keys are not
+// required and the key is unknown, this returns nil with no error.
+func (s *sourceKeys) authenticate(ctx context.Context, key string) (*coredata.SourceKey, error) {
+	// Compare every config key in constant time, so that response times
+	// don't leak how much of a key matches.
+	var found *coredata.SourceKey
+	for n := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(s.keys[n].Key), []byte(key)) == 1 {
+			found = &s.keys[n]
+		}
+	}
+	if found != nil {
+		k := *found
+		return &k, nil
+	}
+
+	if s.store != nil {
+		k, err := s.store.SourceKey(ctx, key)
+		if err == nil {
+			return k, nil
+		}
+		if !errors.Is(err, coredata.ErrSourceKeyNotFound) {
+			return nil, err
+		}
+	}
+
+	if s.required {
+		return nil, ErrUnknownKey
+	}
+	if s.store != nil {
+		// Keys are required once any key is stored.
+		exists, err := s.store.HasSourceKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrUnknownKey
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// NewInMemory returns a Limiter which stores buckets in memory.  This should
+// only be used with a single event API node.
+func NewInMemory(rate float64, burst int) Limiter {
+	return &mem{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+type mem struct {
+	lock    sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+	// swept is the time that idle buckets were last removed.
+	swept time.Time
+}
+
+func (m *mem) Allow(ctx context.Context, buckets map[string]int) (bool, time.Duration, error) {
+	for _, n := range buckets {
+		if float64(n) > m.burst {
+			return false, 0, ErrExceedsBurst
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	// Check every bucket prior to taking any tokens.
+	var retry time.Duration
+	for key, n := range buckets {
+		if tokens := m.tokens(key, now); tokens < float64(n) {
+			if w := wait(float64(n)-tokens, m.rate); w > retry {
+				retry = w
+			}
+		}
+	}
+	if retry > 0 {
+		return false, retry, nil
+	}
+
+	for key, n := range buckets {
+		m.buckets[key] = &bucket{tokens: m.tokens(key, now) - float64(n), at: now}
+	}
+	return true, 0, nil
+}
+
+// tokens returns the tokens available within the given bucket.  A missing
+// bucket is full.
+func (m *mem) tokens(key string, now time.Time) float64 {
+	b, ok := m.buckets[key]
+	if !ok {
+		return m.burst
+	}
+	elapsed := now.Sub(b.at)
+	if elapsed <= 0 {
+		return b.tokens
+	}
+	return math.Min(m.burst, b.tokens+elapsed.Seconds()*m.rate)
+}
+
+// sweep removes buckets which have refilled, preventing unbounded memory
+// growth.  Buckets are swept at most once per refill period.
+func (m *mem) sweep(now time.Time) {
+	ttl := wait(m.burst, m.rate)
+	if now.Sub(m.swept) < ttl {
+		return
+	}
+	m.swept = now
+	for key := range m.buckets {
+		if m.tokens(key, now) >= m.burst {
+			delete(m.buckets, key)
+		}
+	}
+}
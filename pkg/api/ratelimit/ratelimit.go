@@ -0,0 +1,76 @@
+// Package ratelimit provides token bucket rate limiting for the event API.
+//
+// Limiter state is pluggable:  the in-memory limiter is used for a single
+// event API node, whereas the Redis limiter shares state between many
+// horizontally scaled event API nodes.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/inngest/inngest/pkg/config"
+)
+
+var (
+	// ErrExceedsBurst is returned when attempting to take more tokens than
+	// a bucket can ever hold.
+	ErrExceedsBurst = errors.New("request exceeds the maximum burst size")
+)
+
+// Limiter is a token bucket rate limiter.
+type Limiter interface {
+	// Allow attempts to take tokens from each of the given buckets, keyed by
+	// bucket with the number of tokens to take.  Tokens are taken from every
+	// bucket or none.  If any bucket doesn't have enough tokens, this returns
+	// false along with the duration to wait until enough tokens are available.
+	Allow(ctx context.Context, buckets map[string]int) (bool, time.Duration, error)
+}
+
+// New returns a new Limiter for the given config.
+func New(ctx context.Context, c config.RateLimit) (Limiter, error) {
+	if c.Rate <= 0 {
+		return nil, fmt.Errorf("rate limits must be greater than zero")
+	}
+	if c.Burst < 1 {
+		c.Burst = 1
+	}
+
+	switch c.Service.Backend {
+	case "", "inmemory":
+		return NewInMemory(c.Rate, c.Burst), nil
+	case "redis":
+		opts, err := connectOpts(c.Service)
+		if err != nil {
+			return nil, err
+		}
+		r := redis.NewClient(opts)
+		if err := r.Ping(ctx).Err(); err != nil {
+			return nil, err
+		}
+		return NewRedis(r, c.Service.KeyPrefix, c.Rate, c.Burst), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend: %s", c.Service.Backend)
+	}
+}
+
+func connectOpts(c config.RateLimitService) (*redis.Options, error) {
+	if c.DSN != nil {
+		return redis.ParseURL(*c.DSN)
+	}
+	return &redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		DB:       c.DB,
+		Username: c.Username,
+		Password: c.Password,
+	}, nil
+}
+
+// wait returns the time taken to accrue the given number of tokens.
+func wait(tokens float64, rate float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / rate * float64(time.Second)))
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemory(t *testing.T) {
+	l := NewInMemory(2, 4).(*mem)
+	checkLimiter(t, l, func(now func() time.Time) { l.now = now })
+}
+
+func TestRedis(t *testing.T) {
+	r := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: r.Addr()})
+	l := NewRedis(rc, "", 2, 4).(*redisLimiter)
+	checkLimiter(t, l, func(now func() time.Time) { l.now = now })
+}
+
+// checkLimiter asserts that a limiter with a rate of 2/s and a burst of 4
+// behaves as a token bucket.
+func checkLimiter(t *testing.T, l Limiter, setNow func(func() time.Time)) {
+	ctx := context.Background()
+	now := time.Now()
+	setNow(func() time.Time { return now })
+
+	// The full burst is available immediately.
+	ok, _, err := l.Allow(ctx, map[string]int{"a": 3})
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, _, err = l.Allow(ctx, map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The bucket is now empty.
+	ok, retry, err := l.Allow(ctx, map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, retry)
+
+	// Other keys have their own buckets.
+	ok, _, err = l.Allow(ctx, map[string]int{"b": 4})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Tokens refill at the given rate.
+	now = now.Add(time.Second)
+	ok, _, err = l.Allow(ctx, map[string]int{"a": 2})
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, _, err = l.Allow(ctx, map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Buckets never exceed the burst size.
+	now = now.Add(time.Hour)
+	ok, _, err = l.Allow(ctx, map[string]int{"a": 4})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, _, err = l.Allow(ctx, map[string]int{"c": 5})
+	require.ErrorIs(t, err, ErrExceedsBurst)
+
+	// Tokens are only taken if every bucket has enough tokens.
+	ok, retry, err = l.Allow(ctx, map[string]int{"d": 2, "a": 1})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, retry)
+	ok, _, err = l.Allow(ctx, map[string]int{"d": 4})
+	require.NoError(t, err)
+	require.True(t, ok, "Rejected requests must not take tokens from other buckets")
+}
+
+func TestInMemory_sweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := NewInMemory(2, 4).(*mem)
+	l.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		ok, _, err := l.Allow(ctx, map[string]int{key: 1})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.Len(t, l.buckets, 3)
+
+	// Buckets are removed once they've refilled.
+	now = now.Add(2 * time.Second)
+	ok, _, err := l.Allow(ctx, map[string]int{"d": 1})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, l.buckets, 1)
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tokenBucket atomically refills and takes tokens from each bucket, stored as
+// hashes.  Tokens are taken from every bucket or none.  This returns whether
+// the tokens were taken and, if not, the number of milliseconds until enough
+// tokens are available within every bucket.
+var tokenBucket = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local available = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local n = tonumber(ARGV[4 + i])
+	local data = redis.call("HMGET", key, "tokens", "at")
+	local tokens = tonumber(data[1])
+	local at = tonumber(data[2])
+	if tokens == nil or at == nil then
+		tokens = burst
+		at = now
+	end
+	if now > at then
+		tokens = math.min(burst, tokens + ((now - at) / 1000 * rate))
+	end
+	available[i] = tokens
+	if tokens < n then
+		wait = math.max(wait, math.ceil((n - tokens) / rate * 1000))
+	end
+end
+
+if wait > 0 then
+	return {0, wait}
+end
+
+for i, key in ipairs(KEYS) do
+	local n = tonumber(ARGV[4 + i])
+	redis.call("HSET", key, "tokens", tostring(available[i] - n), "at", tostring(now))
+	redis.call("PEXPIRE", key, ttl)
+end
+return {1, 0}
+`)
+
+// NewRedis returns a Limiter which stores buckets in Redis, allowing rate
+// limits to be shared between many event API nodes.
+func NewRedis(r redis.UniversalClient, prefix string, rate float64, burst int) Limiter {
+	if prefix == "" {
+		prefix = "ratelimit"
+	}
+	return &redisLimiter{
+		r:      r,
+		prefix: prefix,
+		rate:   rate,
+		burst:  burst,
+		now:    time.Now,
+	}
+}
+
+type redisLimiter struct {
+	r      redis.UniversalClient
+	prefix string
+	rate   float64
+	burst  int
+	now    func() time.Time
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, buckets map[string]int) (bool, time.Duration, error) {
+	// Expire buckets once they would have refilled, as a missing bucket is
+	// treated as full.
+	ttl := int64(math.Ceil(float64(l.burst)/l.rate*1000)) + 1000
+
+	keys := make([]string, 0, len(buckets))
+	args := []interface{}{l.rate, l.burst, l.now().UnixMilli(), ttl}
+	for key, n := range buckets {
+		if n > l.burst {
+			return false, 0, ErrExceedsBurst
+		}
+		keys = append(keys, fmt.Sprintf("%s:%s", l.prefix, key))
+		args = append(args, n)
+	}
+
+	res, err := tokenBucket.Run(ctx, l.r, keys, args...).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("error checking rate limit: %w", err)
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit response: %v", res)
+	}
+
+	if res[0] == 1 {
+		return true, 0, nil
+	}
+	return false, time.Duration(res[1]) * time.Millisecond, nil
+}
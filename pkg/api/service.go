@@ -2,30 +2,47 @@ package api
 
 import (
 	"context"
-	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"time"
 
+	"github.com/inngest/inngest/pkg/api/ratelimit"
 	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
 	"github.com/inngest/inngest/pkg/event"
 	"github.com/inngest/inngest/pkg/logger"
 	"github.com/inngest/inngest/pkg/pubsub"
 	"github.com/inngest/inngest/pkg/service"
-	"github.com/oklog/ulid/v2"
 )
 
-func NewService(c config.Config) service.Service {
-	return &apiServer{
+type Opt func(s *apiServer)
+
+// WithoutDatastoreKeys disables reading source keys from the datastore.  Events
+// sent with unknown keys are then accepted unless keys are listed or required
+// within config.  This is used by the dev server, which has no key registry.
+func WithoutDatastoreKeys() Opt {
+	return func(s *apiServer) {
+		s.withoutDatastoreKeys = true
+	}
+}
+
+func NewService(c config.Config, opts ...Opt) service.Service {
+	s := &apiServer{
 		config: c,
 	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
 }
 
 type apiServer struct {
 	config    config.Config
 	api       *API
 	publisher pubsub.Publisher
+
+	withoutDatastoreKeys bool
 }
 
 func (a *apiServer) Name() string {
@@ -35,10 +52,32 @@ func (a *apiServer) Name() string {
 func (a *apiServer) Pre(ctx context.Context) error {
 	var err error
 
+	rw, err := a.config.DataStore.Service.Concrete.ReadWriter(ctx)
+	if err != nil {
+		return err
+	}
+
+	var limiter ratelimit.Limiter
+	if a.config.EventAPI.RateLimit != nil {
+		limiter, err = ratelimit.New(ctx, *a.config.EventAPI.RateLimit)
+		if err != nil {
+			return err
+		}
+	}
+
+	var keys coredata.SourceKeyReader = rw
+	if a.withoutDatastoreKeys {
+		keys = nil
+	}
+
 	a.api, err = NewAPI(Options{
 		Config:       a.config,
 		Logger:       logger.From(ctx),
 		EventHandler: a.handleEvent,
+		SourceKeys:   keys,
+		Limiter:      limiter,
+		Events:       rw,
+		Functions:    rw,
 	})
 
 	if err != nil {
@@ -69,13 +108,6 @@ func (a *apiServer) handleEvent(ctx context.Context, e *event.Event) error {
 
 	l.Debug().Str("event", e.Name).Msg("handling event")
 
-	if e.ID == "" {
-		// Always ensure that the event has an ID, for idempotency.
-		e.ID = ulid.MustNew(ulid.Now(), rand.Reader).String()
-	}
-
-	// TODO: Move this into the API itself, once we've yanked out the dev
-	// server's logic and replaced with multiple services.
 	byt, err := json.Marshal(e)
 	if err != nil {
 		return err
@@ -87,8 +119,7 @@ func (a *apiServer) handleEvent(ctx context.Context, e *event.Event) error {
 		ctx,
 		a.config.EventStream.Service.TopicName(),
 		pubsub.Message{
-			// TODO: Move this into a const.
-			Name:      "event/event.received",
+			Name:      event.EventReceivedName,
 			Data:      string(byt),
 			Timestamp: time.Now(),
 		},
@@ -96,5 +127,8 @@ func (a *apiServer) handleEvent(ctx context.Context, e *event.Event) error {
 }
 
 func (a *apiServer) Stop(ctx context.Context) error {
-	return a.api.Stop(ctx)
+	if err := a.api.Stop(ctx); err != nil {
+		return err
+	}
+	return pubsub.Close(a.publisher)
 }
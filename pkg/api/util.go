@@ -13,6 +13,19 @@ type apiResponse struct {
 	StatusCode int    `json:"status"`
 	Message    string `json:"message"`
 	Error      string `json:"error,omitempty"`
+	// IDs lists the ID of each event received, in the order sent.
+	IDs []string `json:"ids,omitempty"`
+	// Runs lists the runs created by each event, keyed by event ID.  This
+	// is only returned in synchronous mode.
+	Runs map[string][]runResponse `json:"runs,omitempty"`
+	// ValidationErrors lists events which don't match the event definitions
+	// of the functions they trigger.
+	ValidationErrors []ValidationError `json:"validationErrors,omitempty"`
+}
+
+type runResponse struct {
+	FunctionID string `json:"functionID"`
+	RunID      string `json:"runID"`
 }
 
 func parseBody(body []byte) ([]*event.Event, error) {
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/function"
+)
+
+// ValidationError reports an event which doesn't match the event definition of
+// a function it triggers.
+type ValidationError struct {
+	EventID    string `json:"eventID"`
+	Name       string `json:"name"`
+	FunctionID string `json:"functionID"`
+	Error      string `json:"error"`
+}
+
+// validate validates each event against the event definitions of every function
+// the event triggers, tagging invalid events with their validation errors.
+func (i *Ingester) validate(ctx context.Context, events []*event.Event) ([]ValidationError, error) {
+	// Always remove validation tags from incoming events, ensuring that they
+	// can't be spoofed to skip functions.
+	for _, evt := range events {
+		delete(evt.Metadata, event.MetadataValidationErrors)
+	}
+
+	if i.functions == nil || i.config.EventAPI.Validation == config.ValidationOff {
+		return nil, nil
+	}
+
+	// Load functions once per event name within the request.
+	byName := map[string][]function.Function{}
+
+	result := []ValidationError{}
+	for _, evt := range events {
+		fns, ok := byName[evt.Name]
+		if !ok {
+			var err error
+			if fns, err = i.functions.FunctionsByTrigger(ctx, evt.Name); err != nil {
+				return nil, fmt.Errorf("error loading functions by trigger: %w", err)
+			}
+			byName[evt.Name] = fns
+		}
+
+		tags := map[string]interface{}{}
+		for _, fn := range fns {
+			if err := i.schemas.validateFunction(ctx, fn, *evt); err != nil {
+				tags[fn.ID] = err.Error()
+				result = append(result, ValidationError{
+					EventID:    evt.ID,
+					Name:       evt.Name,
+					FunctionID: fn.ID,
+					Error:      err.Error(),
+				})
+				i.log.Warn().
+					Str("event", evt.Name).
+					Str("id", evt.ID).
+					Str("function", fn.ID).
+					Err(err).
+					Msg("event does not match definition")
+			}
+		}
+
+		if len(tags) > 0 {
+			if evt.Metadata == nil {
+				evt.Metadata = map[string]interface{}{}
+			}
+			evt.Metadata[event.MetadataValidationErrors] = tags
+		}
+	}
+
+	return result, nil
+}
+
+// schemaCache caches compiled event definitions for each function trigger,
+// ensuring that each version of a definition is compiled once instead of for
+// every event.  Only the latest definition for each trigger is kept.
+type schemaCache struct {
+	l       sync.Mutex
+	schemas map[string]cachedSchema
+}
+
+type cachedSchema struct {
+	def    function.EventDefinition
+	schema *function.Schema
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{schemas: map[string]cachedSchema{}}
+}
+
+// schema returns the compiled definition for the given function's trigger.
+func (c *schemaCache) schema(ctx context.Context, fn function.Function, t function.Trigger) (*function.Schema, error) {
+	if c == nil {
+		return t.Definition.Schema(ctx)
+	}
+
+	key := fn.ID + ":" + t.Event
+
+	c.l.Lock()
+	cached, ok := c.schemas[key]
+	c.l.Unlock()
+	if ok && cached.def.Format == t.Definition.Format && cached.def.Def == t.Definition.Def {
+		return cached.schema, nil
+	}
+
+	schema, err := t.Definition.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.l.Lock()
+	c.schemas[key] = cachedSchema{def: *t.Definition, schema: schema}
+	c.l.Unlock()
+	return schema, nil
+}
+
+// validateFunction validates the event against the definitions of each of the
+// function's triggers for the event.
+func (c *schemaCache) validateFunction(ctx context.Context, fn function.Function, evt event.Event) error {
+	for _, t := range fn.Triggers {
+		if t.EventTrigger == nil || t.Event != evt.Name || t.Definition == nil {
+			continue
+		}
+		schema, err := c.schema(ctx, fn, t)
+		if err != nil {
+			return err
+		}
+		if err := schema.ValidateEvent(evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/expressions"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// webhook is a configured webhook endpoint with its transform precompiled.
+type webhook struct {
+	config.Webhook
+	transform expressions.Evaluable
+}
+
+// newWebhooks compiles the transform of each configured webhook, returning
+// webhooks keyed by ID.
+func newWebhooks(ctx context.Context, hooks []config.Webhook) (map[string]webhook, error) {
+	result := map[string]webhook{}
+	for _, h := range hooks {
+		eval, err := expressions.NewExpressionEvaluator(ctx, h.Transform)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transform for webhook '%s': %w", h.ID, err)
+		}
+		result[h.ID] = webhook{Webhook: h, transform: eval}
+	}
+	return result, nil
+}
+
+// ReceiveWebhook receives arbitrary JSON sent to a configured webhook, using the
+// webhook's transform to create events from the request.
+func (a API) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	matches := WebhookPathRegex.FindStringSubmatch(r.URL.Path)
+	if matches == nil || len(matches) != 2 {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusNotFound,
+			Error:      "Webhook not found",
+		})
+		return
+	}
+	hook, ok := a.webhooks[matches[1]]
+	if !ok {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusNotFound,
+			Error:      "Webhook not found",
+		})
+		return
+	}
+
+	if r.ContentLength > int64(a.config.EventAPI.MaxSize) {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Error:      "Payload larger than maximum allowed",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(a.config.EventAPI.MaxSize)))
+	if err != nil {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusBadRequest,
+			Error:      "Could not read webhook payload",
+		})
+		return
+	}
+
+	if err := hook.verify(r.Header, body); err != nil {
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusUnauthorized,
+			Error:      "Invalid webhook signature",
+		})
+		return
+	}
+
+	events, err := hook.events(r.Context(), r.Header, body)
+	if err != nil {
+		a.log.Warn().Err(err).Str("webhook", hook.ID).Msg("error transforming webhook")
+		a.writeResponse(w, apiResponse{
+			StatusCode: http.StatusBadRequest,
+			Error:      err.Error(),
+		})
+		return
+	}
+
+	// Rate limit webhooks separately from keys;  keys cannot contain colons
+	// so these never conflict.
+	key := &coredata.SourceKey{Name: hook.Name}
+	a.handleEvents(w, r, "webhook:"+hook.ID, key, events)
+}
+
+// verify checks the HMAC signature of the request body, if the webhook has
+// signature verification configured.
+func (h webhook) verify(headers http.Header, body []byte) error {
+	sig := h.Signature
+	if sig == nil {
+		return nil
+	}
+
+	var fn func() hash.Hash
+	switch sig.Algorithm {
+	case "sha1":
+		fn = sha1.New
+	case "sha512":
+		fn = sha512.New
+	default:
+		fn = sha256.New
+	}
+
+	value := strings.TrimPrefix(headers.Get(sig.Header), sig.Prefix)
+	if value == "" {
+		return ErrInvalidSignature
+	}
+
+	var (
+		actual []byte
+		err    error
+	)
+	switch sig.Encoding {
+	case "base64":
+		actual, err = base64.StdEncoding.DecodeString(value)
+	default:
+		actual, err = hex.DecodeString(value)
+	}
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(fn, []byte(sig.Secret))
+	_, _ = mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), actual) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// events runs the webhook's transform against the request, returning the
+// events created.
+func (h webhook) events(ctx context.Context, headers http.Header, body []byte) ([]*event.Event, error) {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("Unable to process webhook payload")
+	}
+
+	lowered := map[string]interface{}{}
+	for k, v := range headers {
+		if len(v) > 0 {
+			lowered[strings.ToLower(k)] = v[0]
+		}
+	}
+
+	result, err := h.transform.Value(ctx, expressions.NewData(map[string]interface{}{
+		"body":    payload,
+		"headers": lowered,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("Error transforming webhook payload: %w", err)
+	}
+
+	// The transform may return a single event or a list of events.
+	list, ok := result.([]interface{})
+	if !ok {
+		list = []interface{}{result}
+	}
+
+	events := make([]*event.Event, len(list))
+	for n, item := range list {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return nil, errors.New("Webhook transform must return an event or a list of events")
+		}
+		byt, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		evt := &event.Event{}
+		if err := json.Unmarshal(byt, evt); err != nil {
+			return nil, fmt.Errorf("Webhook transform returned an invalid event: %w", err)
+		}
+		if evt.Name == "" {
+			return nil, errors.New("Webhook transform returned an event without a name")
+		}
+		if evt.Data == nil {
+			evt.Data = map[string]interface{}{}
+		}
+		events[n] = evt
+	}
+	return events, nil
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiveWebhook(t *testing.T) {
+	c := config.Config{}
+	c.EventAPI.Webhooks = []config.Webhook{
+		{
+			ID:        "github",
+			Name:      "GitHub",
+			Transform: `{"name": "github/" + headers["x-github-event"], "data": body, "id": headers["x-github-delivery"]}`,
+			Signature: &config.WebhookSignature{
+				Secret:    "shh",
+				Header:    "X-Hub-Signature-256",
+				Prefix:    "sha256=",
+				Algorithm: "sha256",
+				Encoding:  "hex",
+			},
+		},
+		{
+			ID:        "batch",
+			Name:      "Batch",
+			Transform: `body.items.map(i, {"name": "item/" + i.type, "data": i})`,
+		},
+		{
+			ID:        "invalid",
+			Name:      "Invalid",
+			Transform: `body.items`,
+		},
+	}
+	a, rec := newTestAPI(t, c, Options{})
+
+	sendWebhook := func(path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		a.ReceiveWebhook(w, r)
+		return w
+	}
+
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("shh"))
+		_, _ = mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	t.Run("unknown webhooks", func(t *testing.T) {
+		w := sendWebhook("/w/unknown", `{}`, nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("signatures are verified", func(t *testing.T) {
+		body := `{"ref":"main"}`
+		w := sendWebhook("/w/github", body, map[string]string{"X-GitHub-Event": "push"})
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = sendWebhook("/w/github", body, map[string]string{
+			"X-GitHub-Event":      "push",
+			"X-Hub-Signature-256": sign(`{"ref":"other"}`),
+		})
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Empty(t, rec.events)
+
+		w = sendWebhook("/w/github", body, map[string]string{
+			"X-GitHub-Event":      "push",
+			"X-GitHub-Delivery":   "delivery-1",
+			"X-Hub-Signature-256": sign(body),
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, rec.events, 1)
+		require.Equal(t, "github/push", rec.events[0].Name)
+		require.Equal(t, "delivery-1", rec.events[0].ID)
+		require.Equal(t, "GitHub", rec.events[0].Source)
+		require.Equal(t, map[string]interface{}{"ref": "main"}, rec.events[0].Data)
+	})
+
+	t.Run("transforms may return many events", func(t *testing.T) {
+		w := sendWebhook("/w/batch", `{"items":[{"type":"a"},{"type":"b"}]}`, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, rec.events, 3)
+	})
+
+	t.Run("invalid transform results are rejected", func(t *testing.T) {
+		w := sendWebhook("/w/invalid", `{"items":[1, 2]}`, nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = sendWebhook("/w/batch", `not json`, nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Len(t, rec.events, 3)
+	})
+}
+
+func TestNewWebhooks_invalidTransform(t *testing.T) {
+	_, err := newWebhooks(context.Background(), []config.Webhook{{ID: "bad", Transform: `{"name": `}})
+	require.Error(t, err)
+}
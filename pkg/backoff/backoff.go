@@ -7,9 +7,15 @@ import (
 )
 
 func LinearJitterBackoff(attemptNum int) time.Time {
+	return time.Now().Add(LinearJitterDelay(attemptNum))
+}
+
+// LinearJitterDelay returns the delay before the given attempt, allowing
+// callers to schedule the attempt using their own clock.
+func LinearJitterDelay(attemptNum int) time.Duration {
 	backoff := float64(uint(1) << (uint(attemptNum) - 1))
 	backoff += backoff * (0.15 * rand.Float64())
 	// Increase by a factor of 10 to get 10 second breaks at minimum.
 	backoff = backoff * 10
-	return time.Now().Add(time.Second * time.Duration(backoff))
+	return time.Second * time.Duration(backoff)
 }
@@ -12,12 +12,15 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/config"
 	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
 	"github.com/inngest/inngest/pkg/event"
 	"github.com/inngest/inngest/pkg/execution/executor"
+	"github.com/inngest/inngest/pkg/execution/queue/inmemoryqueue"
 	"github.com/inngest/inngest/pkg/execution/runner"
 	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/execution/state/inmemory"
 	"github.com/inngest/inngest/pkg/function"
 	"github.com/inngest/inngest/pkg/service"
 	"github.com/muesli/reflow/wrap"
@@ -30,6 +33,9 @@ type RunUIOpts struct {
 	Function  function.Function
 	LogBuffer *bytes.Buffer
 	Verbose   bool
+	// FastForward runs the function using a virtual clock, which is advanced
+	// to the next wait or pause timeout whenever the run is idle.
+	FastForward bool
 }
 
 func NewRunUI(ctx context.Context, opts RunUIOpts) (*RunUI, error) {
@@ -41,6 +47,9 @@ func NewRunUI(ctx context.Context, opts RunUIOpts) (*RunUI, error) {
 		logBuf:  opts.LogBuffer,
 		verbose: opts.Verbose,
 	}
+	if opts.FastForward {
+		r.clock = clock.NewVirtual(time.Now())
+	}
 	return r, nil
 }
 
@@ -81,6 +90,10 @@ type RunUI struct {
 
 	// Used to decide whether to print more information when running the command.
 	verbose bool
+
+	// clock is the virtual clock used to fast-forward runs, or nil if the
+	// system clock is used.
+	clock *clock.Virtual
 }
 
 type RunUIExecution struct {
@@ -138,12 +151,29 @@ func (r *RunUI) run(ctx context.Context) {
 		return
 	}
 
+	var clk clock.Clock = clock.New()
+	if r.clock != nil {
+		clk = r.clock
+		qc, ok := c.Queue.Service.Concrete.(*inmemoryqueue.Config)
+		if !ok {
+			r.err = fmt.Errorf("fast-forwarding requires the in-memory queue")
+			return
+		}
+		qc.Clock = clk
+		if sc, ok := c.State.Service.Concrete.(*inmemory.Config); ok {
+			sc.Clock = clk
+		}
+	}
+
 	// Create a singleton queue for initializing the fn.
 	q, err := c.Queue.Service.Concrete.Producer()
 	if err != nil {
 		r.err = err
 		return
 	}
+	// idle reports whether the queue only has items scheduled for the
+	// future, in which case the virtual clock can be advanced.
+	idle, _ := q.(inmemoryqueue.MemoryQueue)
 	// Return the in-memory state manager that was created from our
 	// derived default config.
 	//
@@ -158,7 +188,7 @@ func (r *RunUI) run(ctx context.Context) {
 	// In order to execute the function we need to create a new executor
 	// service to execute the steps of our function.  We'll manually initialize
 	// a new function run.
-	exec := executor.NewService(*c, executor.WithExecutionLoader(el))
+	exec := executor.NewService(*c, executor.WithExecutionLoader(el), executor.WithClock(clk))
 	go func() {
 		if err := service.Start(ctx, exec); err != nil {
 			r.err = err
@@ -180,7 +210,7 @@ func (r *RunUI) run(ctx context.Context) {
 
 			var runId *state.Identifier
 
-			runId, err = runner.Initialize(ctx, r.fn, event, r.sm, q)
+			runId, err = runner.InitializeAt(ctx, r.fn, event, clk.Now(), r.sm, q)
 			if err != nil {
 				r.err = err
 				return
@@ -249,6 +279,12 @@ func (r *RunUI) run(ctx context.Context) {
 					*execution.done = true
 					return
 				}
+
+				// When fast-forwarding, advance the clock to the next wait
+				// or pause timeout once no steps are running.
+				if r.clock != nil && idle != nil && idle.Idle() {
+					r.clock.AdvanceToNext()
+				}
 				<-time.After(time.Millisecond * 5)
 			}
 		}(evt)
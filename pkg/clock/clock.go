@@ -0,0 +1,180 @@
+// Package clock provides an injectable source of time for the queue, executor
+// and runner.  Services use the system clock by default;  tests and local runs
+// may use a virtual clock to fast-forward through waits, pause timeouts and
+// cron schedules deterministically.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock returns the current time and notifies callers once durations elapse.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer returns a timer which sends the current time on its channel
+	// once the duration elapses.  Unlike After, the timer can be stopped to
+	// release it before it fires.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event created by a clock.
+type Timer interface {
+	// C returns the channel on which the time is sent when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, returning false if the timer has
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// New returns a clock which uses the system time.
+func New() Clock {
+	return system{}
+}
+
+type system struct{}
+
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+func (system) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (system) NewTimer(d time.Duration) Timer {
+	return systemTimer{t: time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (s systemTimer) C() <-chan time.Time {
+	return s.t.C
+}
+
+func (s systemTimer) Stop() bool {
+	return s.t.Stop()
+}
+
+// Virtual is a clock whose time only changes when advanced.  Timers created
+// via After fire once the clock is advanced past their deadline, in deadline
+// order.
+type Virtual struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+type timer struct {
+	v  *Virtual
+	at time.Time
+	c  chan time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop removes the timer from the clock, so that it never fires.
+func (t *timer) Stop() bool {
+	t.v.lock.Lock()
+	defer t.v.lock.Unlock()
+
+	for n, pending := range t.v.timers {
+		if pending == t {
+			t.v.timers = append(t.v.timers[:n], t.v.timers[n+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// NewVirtual returns a virtual clock starting at the given time.
+func NewVirtual(now time.Time) *Virtual {
+	return &Virtual{now: now}
+}
+
+func (v *Virtual) Now() time.Time {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.now
+}
+
+// After returns a channel which receives the clock's time once the clock is
+// advanced by at least d.  Durations of zero or less fire immediately.
+func (v *Virtual) After(d time.Duration) <-chan time.Time {
+	return v.NewTimer(d).C()
+}
+
+// NewTimer returns a timer which fires once the clock is advanced by at least
+// d.  Durations of zero or less fire immediately.
+func (v *Virtual) NewTimer(d time.Duration) Timer {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	t := &timer{v: v, at: v.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- v.now
+		return t
+	}
+	v.timers = append(v.timers, t)
+	sort.SliceStable(v.timers, func(i, j int) bool {
+		return v.timers[i].at.Before(v.timers[j].at)
+	})
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer whose deadline has
+// passed.
+func (v *Virtual) Advance(d time.Duration) {
+	v.Set(v.Now().Add(d))
+}
+
+// Set moves the clock to the given time, firing every timer whose deadline
+// has passed.  The clock never moves backwards;  times before the clock's
+// current time are ignored.
+func (v *Virtual) Set(t time.Time) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if t.Before(v.now) {
+		return
+	}
+	v.now = t
+
+	n := 0
+	for n < len(v.timers) && !v.timers[n].at.After(t) {
+		v.timers[n].c <- t
+		n++
+	}
+	v.timers = v.timers[n:]
+}
+
+// Next returns the deadline of the earliest pending timer, or false if no
+// timers are pending.
+func (v *Virtual) Next() (time.Time, bool) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if len(v.timers) == 0 {
+		return time.Time{}, false
+	}
+	return v.timers[0].at, true
+}
+
+// AdvanceToNext moves the clock to the deadline of the earliest pending timer,
+// firing it.  This returns false if no timers are pending.
+func (v *Virtual) AdvanceToNext() bool {
+	next, ok := v.Next()
+	if ok {
+		v.Set(next)
+	}
+	return ok
+}
@@ -0,0 +1,110 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestVirtual(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := NewVirtual(start)
+	require.Equal(t, start, v.Now())
+
+	// Non-positive durations fire immediately.
+	at, ok := fired(v.After(0))
+	require.True(t, ok)
+	require.Equal(t, start, at)
+
+	hour := v.After(time.Hour)
+	day := v.After(24 * time.Hour)
+	minute := v.After(time.Minute)
+
+	next, ok := v.Next()
+	require.True(t, ok)
+	require.Equal(t, start.Add(time.Minute), next)
+
+	v.Advance(30 * time.Second)
+	_, ok = fired(minute)
+	require.False(t, ok)
+
+	// Advancing past multiple deadlines fires each timer.
+	v.Advance(2 * time.Hour)
+	require.Equal(t, start.Add(2*time.Hour+30*time.Second), v.Now())
+	at, ok = fired(minute)
+	require.True(t, ok)
+	require.Equal(t, v.Now(), at)
+	_, ok = fired(hour)
+	require.True(t, ok)
+	_, ok = fired(day)
+	require.False(t, ok)
+
+	// The clock never moves backwards.
+	v.Set(start)
+	require.Equal(t, start.Add(2*time.Hour+30*time.Second), v.Now())
+
+	require.True(t, v.AdvanceToNext())
+	require.Equal(t, start.Add(24*time.Hour), v.Now())
+	_, ok = fired(day)
+	require.True(t, ok)
+
+	require.False(t, v.AdvanceToNext())
+	_, ok = v.Next()
+	require.False(t, ok)
+}
+
+func TestVirtual_timers(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := NewVirtual(start)
+
+	stopped := v.NewTimer(time.Minute)
+	hour := v.NewTimer(time.Hour)
+
+	// Stopped timers are removed from the clock and never fire.
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+	next, ok := v.Next()
+	require.True(t, ok)
+	require.Equal(t, start.Add(time.Hour), next)
+
+	v.Advance(time.Hour)
+	_, ok = fired(stopped.C())
+	require.False(t, ok)
+	at, ok := fired(hour.C())
+	require.True(t, ok)
+	require.Equal(t, start.Add(time.Hour), at)
+
+	// Timers which have fired can't be stopped.
+	require.False(t, hour.Stop())
+	_, ok = v.Next()
+	require.False(t, ok)
+
+	immediate := v.NewTimer(0)
+	_, ok = fired(immediate.C())
+	require.True(t, ok)
+	require.False(t, immediate.Stop())
+}
+
+func TestSystem_timers(t *testing.T) {
+	c := New()
+	stopped := c.NewTimer(time.Hour)
+	require.True(t, stopped.Stop())
+
+	timer := c.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("timer didn't fire")
+	}
+	require.False(t, timer.Stop())
+}
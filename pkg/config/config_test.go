@@ -1,10 +1,13 @@
 package config
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/inngest/inngest/pkg/config/registration"
+	"github.com/inngest/inngest/pkg/coredata"
 	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
 	"github.com/inngest/inngest/pkg/execution/driver/dockerdriver"
 	"github.com/inngest/inngest/pkg/execution/driver/httpdriver"
@@ -25,6 +28,10 @@ func defaultConfig() *Config {
 			Addr:    "0.0.0.0",
 			Port:    8288,
 			MaxSize: 524288,
+			Keys:    []coredata.SourceKey{},
+
+			Validation: ValidationWarn,
+			Webhooks:   []Webhook{},
 		},
 		CoreAPI: CoreAPI{
 			Addr: "0.0.0.0",
@@ -35,6 +42,7 @@ func defaultConfig() *Config {
 				"docker": &dockerdriver.Config{},
 				"http":   &httpdriver.Config{},
 			},
+			MaxDelay: 720 * time.Hour,
 		},
 		EventStream: EventStream{
 			Service: MessagingService{
@@ -60,6 +68,11 @@ func defaultConfig() *Config {
 				Concrete: &inmemorydatastore.Config{},
 			},
 		},
+		Publisher: Publisher{
+			Subscriptions: []Subscription{},
+			MaxAttempts:   5,
+			Topic:         "publisher",
+		},
 	}
 
 	return base
@@ -190,6 +203,115 @@ config.#Config & {
 				}, redis)
 			},
 		},
+		{
+			name: "redis messaging",
+			input: []byte(`package main
+
+import (
+	config "inngest.com/defs/config"
+)
+
+config.#Config & {
+  eventstream: {
+    service: {
+      backend: "redis"
+      topic: "events"
+    }
+  }
+}
+`),
+			config: func() *Config {
+				c := defaultConfig()
+				c.EventStream.Service.Backend = "redis"
+				c.EventStream.Service.Concrete = &RedisMessaging{
+					Topic:         "events",
+					Host:          "localhost",
+					Port:          6379,
+					KeyPrefix:     "inngest:stream",
+					Group:         "inngest",
+					ReclaimAfter:  60,
+					MaxDeliveries: 10,
+					MaxLen:        100000,
+				}
+				return c
+			},
+		},
+		{
+			name: "event api rate limits, with redis",
+			input: []byte(`package main
+
+import (
+	config "inngest.com/defs/config"
+)
+
+config.#Config & {
+  eventAPI: {
+    rateLimit: {
+      rate: 10
+      perEvent: true
+      service: { backend: "redis" }
+    }
+  }
+}
+`),
+			config: func() *Config {
+				c := defaultConfig()
+				c.EventAPI.RateLimit = &RateLimit{
+					Rate:     10,
+					Burst:    10,
+					PerEvent: true,
+					Service: RateLimitService{
+						Backend:   "redis",
+						Host:      "localhost",
+						Port:      6379,
+						KeyPrefix: "ratelimit",
+					},
+				}
+				return c
+			},
+		},
+		{
+			name: "event api webhooks",
+			input: []byte(`package main
+
+import (
+	config "inngest.com/defs/config"
+)
+
+config.#Config & {
+  eventAPI: {
+    webhooks: [{
+      id: "github"
+      name: "GitHub"
+      transform: "{'name': 'github/push', 'data': body}"
+      signature: {
+        secret: "shh"
+        header: "X-Hub-Signature-256"
+        prefix: "sha256="
+      }
+    }]
+  }
+}
+`),
+			config: func() *Config {
+				c := defaultConfig()
+				c.EventAPI.Webhooks = []Webhook{
+					{
+						ID:        "github",
+						Name:      "GitHub",
+						Transform: "{'name': 'github/push', 'data': body}",
+						Signature: &WebhookSignature{
+							Secret:    "shh",
+							Header:    "X-Hub-Signature-256",
+							Prefix:    "sha256=",
+							Algorithm: "sha256",
+							Encoding:  "hex",
+						},
+					},
+				}
+				return c
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -208,3 +330,28 @@ config.#Config & {
 		})
 	}
 }
+
+func TestTopicURL(t *testing.T) {
+	sqs := SQSMessaging{
+		Region:   "us-east-1",
+		Topic:    "events",
+		QueueURL: "https://sqs.us-east-1.amazonaws.com/123/events",
+	}
+	require.Equal(t, "awssqs://sqs.us-east-1.amazonaws.com/123/events?region=us-east-1", sqs.TopicURL("events", URLTypeSubscribe))
+	require.Equal(t, "awssqs://sqs.us-east-1.amazonaws.com/123/run-updates?region=us-east-1", sqs.TopicURL("run-updates", URLTypePublish))
+
+	sqs.QueueURL = "http://localhost:4566/000/events?awssdk=v1"
+	require.Equal(t, "awssqs://localhost:4566/000/run-updates?awssdk=v1&region=us-east-1", sqs.TopicURL("run-updates", URLTypeSubscribe))
+
+	gcp := GCPPubSubMessaging{Project: "p", Topic: "events"}
+	require.Equal(t, "gcppubsub://projects/p/topics/events", gcp.TopicURL("events", URLTypePublish))
+	require.Equal(t, "gcppubsub://projects/p/topics/run-updates", gcp.TopicURL("run-updates", URLTypePublish))
+	require.Equal(t, "gcppubsub://projects/p/subscriptions/run-updates", gcp.TopicURL("run-updates", URLTypeSubscribe))
+}
+
+func TestDev(t *testing.T) {
+	c, err := Dev(context.Background())
+	require.NoError(t, err)
+	// The dev server streams live run updates to its UI.
+	require.Equal(t, "run-updates", c.EventStream.LifecycleTopic)
+}
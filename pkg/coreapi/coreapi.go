@@ -7,10 +7,15 @@ import (
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/inngest/inngest/pkg/api"
 	"github.com/inngest/inngest/pkg/config"
 	"github.com/inngest/inngest/pkg/coreapi/generated"
 	"github.com/inngest/inngest/pkg/coreapi/graph/resolvers"
 	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/execution/lifecycle"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/pubsub"
 	"github.com/rs/zerolog"
 )
 
@@ -18,6 +23,14 @@ type Options struct {
 	Config        config.Config
 	Logger        *zerolog.Logger
 	APIReadWriter coredata.APIReadWriter
+	State         state.Manager
+	Queue         queue.Producer
+	Publisher     pubsub.Publisher
+	// Ingester prepares events sent via the API in the same manner as the
+	// event API.
+	Ingester *api.Ingester
+	// Updates broadcasts run lifecycle updates to GraphQL subscriptions.
+	Updates *lifecycle.Hub
 }
 
 func NewCoreApi(o Options) (*CoreAPI, error) {
@@ -30,6 +43,12 @@ func NewCoreApi(o Options) (*CoreAPI, error) {
 
 	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: &resolvers.Resolver{
 		APIReadWriter: o.APIReadWriter,
+		State:         o.State,
+		Queue:         o.Queue,
+		Publisher:     o.Publisher,
+		EventTopic:    o.Config.EventStream.Service.TopicName(),
+		Ingester:      o.Ingester,
+		Updates:       o.Updates,
 	}}))
 
 	// TODO - Add option for enabling GraphQL Playground
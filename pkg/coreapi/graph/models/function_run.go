@@ -0,0 +1,12 @@
+package models
+
+import (
+	"github.com/inngest/inngest/pkg/execution/state"
+)
+
+// FunctionRun is a single run of a function along with the run's state, as
+// loaded from the state store.
+type FunctionRun struct {
+	FunctionID string
+	State      state.State
+}
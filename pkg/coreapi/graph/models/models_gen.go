@@ -2,6 +2,13 @@
 
 package models
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
 type ActionVersionQuery struct {
 	Dsn          string `json:"dsn"`
 	VersionMajor *int   `json:"versionMajor"`
@@ -22,6 +29,14 @@ type DeployFunctionInput struct {
 	Live   *bool        `json:"live"`
 }
 
+type EventsQuery struct {
+	ID    *string    `json:"id"`
+	Name  *string    `json:"name"`
+	From  *time.Time `json:"from"`
+	To    *time.Time `json:"to"`
+	Limit *int       `json:"limit"`
+}
+
 type ExecutionConfig struct {
 	Drivers *ExecutionDriversConfig `json:"drivers"`
 }
@@ -35,9 +50,162 @@ type ExecutionDriversConfig struct {
 	Docker *ExecutionDockerDriverConfig `json:"docker"`
 }
 
+type FunctionRunsQuery struct {
+	FunctionID *string `json:"functionId"`
+	// Return only runs with the given status.
+	Status *RunStatus `json:"status"`
+	Limit  *int       `json:"limit"`
+}
+
+type StepState struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// The output of the step as JSON, if the step has completed.
+	Output *string `json:"output"`
+	// The last error of the step, if the step has errored.
+	Error *string `json:"error"`
+	// The number of times the step has been attempted.
+	Attempts int `json:"attempts"`
+}
+
 type UpdateActionVersionInput struct {
 	Dsn          string `json:"dsn"`
 	VersionMajor int    `json:"versionMajor"`
 	VersionMinor int    `json:"versionMinor"`
 	Enabled      *bool  `json:"enabled"`
 }
+
+type GraphFormat string
+
+const (
+	// A Mermaid flowchart.
+	GraphFormatMermaid GraphFormat = "MERMAID"
+	// A Graphviz graph, using the DOT language.
+	GraphFormatDot GraphFormat = "DOT"
+)
+
+var AllGraphFormat = []GraphFormat{
+	GraphFormatMermaid,
+	GraphFormatDot,
+}
+
+func (e GraphFormat) IsValid() bool {
+	switch e {
+	case GraphFormatMermaid, GraphFormatDot:
+		return true
+	}
+	return false
+}
+
+func (e GraphFormat) String() string {
+	return string(e)
+}
+
+func (e *GraphFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = GraphFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid GraphFormat", str)
+	}
+	return nil
+}
+
+func (e GraphFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RunStatus string
+
+const (
+	RunStatusScheduled RunStatus = "SCHEDULED"
+	RunStatusRunning   RunStatus = "RUNNING"
+	RunStatusCompleted RunStatus = "COMPLETED"
+	RunStatusFailed    RunStatus = "FAILED"
+	RunStatusCancelled RunStatus = "CANCELLED"
+)
+
+var AllRunStatus = []RunStatus{
+	RunStatusScheduled,
+	RunStatusRunning,
+	RunStatusCompleted,
+	RunStatusFailed,
+	RunStatusCancelled,
+}
+
+func (e RunStatus) IsValid() bool {
+	switch e {
+	case RunStatusScheduled, RunStatusRunning, RunStatusCompleted, RunStatusFailed, RunStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (e RunStatus) String() string {
+	return string(e)
+}
+
+func (e *RunStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RunStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RunStatus", str)
+	}
+	return nil
+}
+
+func (e RunStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RunUpdateKind string
+
+const (
+	RunUpdateKindStepStarted  RunUpdateKind = "STEP_STARTED"
+	RunUpdateKindStepFinished RunUpdateKind = "STEP_FINISHED"
+	RunUpdateKindStepFailed   RunUpdateKind = "STEP_FAILED"
+	RunUpdateKindRunCompleted RunUpdateKind = "RUN_COMPLETED"
+)
+
+var AllRunUpdateKind = []RunUpdateKind{
+	RunUpdateKindStepStarted,
+	RunUpdateKindStepFinished,
+	RunUpdateKindStepFailed,
+	RunUpdateKindRunCompleted,
+}
+
+func (e RunUpdateKind) IsValid() bool {
+	switch e {
+	case RunUpdateKindStepStarted, RunUpdateKindStepFinished, RunUpdateKindStepFailed, RunUpdateKindRunCompleted:
+		return true
+	}
+	return false
+}
+
+func (e RunUpdateKind) String() string {
+	return string(e)
+}
+
+func (e *RunUpdateKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RunUpdateKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RunUpdateKind", str)
+	}
+	return nil
+}
+
+func (e RunUpdateKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,94 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/inngest/inngest/pkg/coreapi/graph/models"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/pubsub"
+)
+
+func (r *queryResolver) Event(ctx context.Context, id string) (*coredata.Event, error) {
+	evt, err := r.APIReadWriter.Event(ctx, id)
+	if errors.Is(err, coredata.ErrEventNotFound) {
+		return nil, nil
+	}
+	return evt, err
+}
+
+func (r *queryResolver) Events(ctx context.Context, query *models.EventsQuery) ([]*coredata.Event, error) {
+	q := coredata.EventQuery{}
+	if query != nil {
+		q.ID = query.ID
+		q.Name = query.Name
+		q.From = query.From
+		q.To = query.To
+		if query.Limit != nil {
+			q.Limit = *query.Limit
+		}
+	}
+
+	events, err := r.APIReadWriter.Events(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*coredata.Event, len(events))
+	for n := range events {
+		result[n] = &events[n]
+	}
+	return result, nil
+}
+
+func (r *eventResolver) Payload(ctx context.Context, obj *coredata.Event) (string, error) {
+	byt, err := json.Marshal(obj.Event)
+	return string(byt), err
+}
+
+func (r *eventRunResolver) WorkflowID(ctx context.Context, obj *coredata.EventRun) (string, error) {
+	return obj.Identifier.WorkflowID.String(), nil
+}
+
+func (r *eventRunResolver) RunID(ctx context.Context, obj *coredata.EventRun) (string, error) {
+	return obj.Identifier.RunID.String(), nil
+}
+
+// SendEvent publishes the event to the event stream in the same manner as the
+// event API.  The event is prepared using the event API's ingester, which
+// removes any source or validation errors sent with the event, ensures that the
+// event has an ID and timestamp, and enforces rate limits and event definitions.
+func (r *mutationResolver) SendEvent(ctx context.Context, payload string) (string, error) {
+	evt := &event.Event{}
+	if err := json.Unmarshal([]byte(payload), evt); err != nil {
+		return "", fmt.Errorf("invalid event payload: %w", err)
+	}
+	if evt.Name == "" {
+		return "", fmt.Errorf("event name must be present")
+	}
+
+	// Events sent via the core API have no source key.
+	if _, err := r.Ingester.Ingest(ctx, "", nil, []*event.Event{evt}); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	byt, err := json.Marshal(evt)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.Publisher.Publish(ctx, r.EventTopic, pubsub.Message{
+		Name:      event.EventReceivedName,
+		Data:      string(byt),
+		Timestamp: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return evt.ID, nil
+}
@@ -0,0 +1,74 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/inngest/inngest/pkg/api"
+	"github.com/inngest/inngest/pkg/api/ratelimit"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records every message published.
+type recordingPublisher struct {
+	topics   []string
+	messages []pubsub.Message
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, m pubsub.Message) error {
+	p.topics = append(p.topics, topic)
+	p.messages = append(p.messages, m)
+	return nil
+}
+
+func TestSendEvent(t *testing.T) {
+	ctx := context.Background()
+	p := &recordingPublisher{}
+	m := &mutationResolver{&Resolver{
+		Publisher:  p,
+		EventTopic: "events",
+		Ingester: api.NewIngester(api.Options{
+			Limiter: ratelimit.NewInMemory(0.001, 2),
+		}),
+	}}
+
+	_, err := m.SendEvent(ctx, `{"data":{}}`)
+	require.ErrorContains(t, err, "event name")
+	_, err = m.SendEvent(ctx, `{`)
+	require.ErrorContains(t, err, "invalid event payload")
+	require.Len(t, p.messages, 0)
+
+	id, err := m.SendEvent(ctx, `{"name":"test/event","data":{"ok":true}}`)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	require.Equal(t, []string{"events"}, p.topics)
+	require.Equal(t, event.EventReceivedName, p.messages[0].Name)
+
+	// The event is given an ID and timestamp.
+	evt := event.Event{}
+	require.NoError(t, json.Unmarshal([]byte(p.messages[0].Data), &evt))
+	require.Equal(t, id, evt.ID)
+	require.Equal(t, "test/event", evt.Name)
+	require.Equal(t, true, evt.Data["ok"])
+	require.NotZero(t, evt.Timestamp)
+
+	// Sources and validation errors sent by the client are removed.
+	_, err = m.SendEvent(ctx, `{"name":"test/event","source":"spoofed","metadata":{"validationErrors":{"fn":"spoofed"}}}`)
+	require.NoError(t, err)
+	require.Len(t, p.messages, 2)
+	evt = event.Event{}
+	require.NoError(t, json.Unmarshal([]byte(p.messages[1].Data), &evt))
+	require.Empty(t, evt.Source)
+	require.Nil(t, evt.ValidationErrors())
+
+	// Events are rate limited in the same manner as the event API.
+	_, err = m.SendEvent(ctx, `{"name":"test/event"}`)
+	ierr := &api.IngestError{}
+	require.ErrorAs(t, err, &ierr)
+	require.Equal(t, http.StatusTooManyRequests, ierr.StatusCode)
+	require.Len(t, p.messages, 2)
+}
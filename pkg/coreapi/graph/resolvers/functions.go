@@ -2,9 +2,14 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/inngest/inngest/inngest"
 	"github.com/inngest/inngest/pkg/coreapi/graph/models"
+	"github.com/inngest/inngest/pkg/coredata"
 	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/logger"
 )
 
 // Deploy a function creating a new function version
@@ -14,6 +19,12 @@ func (r *mutationResolver) DeployFunction(ctx context.Context, input models.Depl
 	if err != nil {
 		return nil, err
 	}
+	if err := f.ValidateGraph(ctx); err != nil {
+		return nil, fmt.Errorf("The function is not valid: %w", err)
+	}
+	for _, w := range function.Warnings(ctx, []*function.Function{f}, r.knownEvent(ctx, f.ID)) {
+		logger.From(ctx).Warn().Str("function_id", f.ID).Msg(w)
+	}
 
 	// TODO - Move default environment to config
 	env := "prod"
@@ -33,3 +44,106 @@ func (r *mutationResolver) DeployFunction(ctx context.Context, input models.Depl
 	fv.Config = string(config)
 	return &fv, nil
 }
+
+// knownEvent returns a func reporting whether an event is produced outside of
+// the given function:  by triggering another live function, by a source key
+// restricted to the event's prefix, or by having been received previously.
+// Events are assumed to be known if they can't be looked up, preventing
+// spurious warnings.
+func (r *mutationResolver) knownEvent(ctx context.Context, functionID string) func(event string) bool {
+	fns, err := r.APIReadWriter.Functions(ctx)
+	if err != nil {
+		return func(string) bool { return true }
+	}
+	triggers := map[string]struct{}{}
+	for _, fn := range fns {
+		if fn.ID == functionID {
+			// The function is being replaced by the new version.
+			continue
+		}
+		for _, t := range fn.Triggers {
+			if t.EventTrigger != nil {
+				triggers[t.Event] = struct{}{}
+			}
+		}
+	}
+
+	prefixes := []string{}
+	if keys, ok := r.APIReadWriter.(coredata.SourceKeyReader); ok {
+		if prefixes, err = keys.SourceKeyPrefixes(ctx); err != nil {
+			return func(string) bool { return true }
+		}
+	}
+
+	return func(event string) bool {
+		if _, ok := triggers[event]; ok {
+			return true
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(event, p) {
+				return true
+			}
+		}
+		evts, err := r.APIReadWriter.Events(ctx, coredata.EventQuery{Name: &event, Limit: 1})
+		return err != nil || len(evts) > 0
+	}
+}
+
+func (r *mutationResolver) RollbackFunction(ctx context.Context, functionID string, version int) (*function.FunctionVersion, error) {
+	if version < 1 {
+		return nil, coredata.ErrFunctionVersionNotFound
+	}
+	fv, err := r.APIReadWriter.RollbackFunction(ctx, functionID, uint(version))
+	if err != nil {
+		return nil, err
+	}
+	return &fv, nil
+}
+
+func (r *queryResolver) Functions(ctx context.Context) ([]*function.Function, error) {
+	fns, err := r.APIReadWriter.Functions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*function.Function, len(fns))
+	for n := range fns {
+		result[n] = &fns[n]
+	}
+	return result, nil
+}
+
+func (r *queryResolver) FunctionVersions(ctx context.Context, functionID string) ([]*function.FunctionVersion, error) {
+	versions, err := r.APIReadWriter.FunctionVersions(ctx, functionID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*function.FunctionVersion, len(versions))
+	for n := range versions {
+		result[n] = &versions[n]
+	}
+	return result, nil
+}
+
+func (r *functionResolver) Config(ctx context.Context, obj *function.Function) (string, error) {
+	config, err := function.MarshalCUE(*obj)
+	return string(config), err
+}
+
+// Graph renders the version's steps and edges, using the same renderer as
+// `inngest fn graph`.
+func (r *functionVersionResolver) Graph(ctx context.Context, obj *function.FunctionVersion, format *models.GraphFormat) (string, error) {
+	f := models.GraphFormatMermaid
+	if format != nil {
+		f = *format
+	}
+
+	w, err := obj.Function.Workflow(ctx)
+	if err != nil {
+		return "", err
+	}
+	g, err := inngest.NewGraph(*w)
+	if err != nil {
+		return "", err
+	}
+	return g.Render(strings.ToLower(f.String()))
+}
@@ -0,0 +1,141 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/coreapi/graph/models"
+	"github.com/inngest/inngest/pkg/coredata"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackFunction(t *testing.T) {
+	ctx := context.Background()
+	data, err := inmemorydatastore.New(ctx)
+	require.NoError(t, err)
+	r := &Resolver{APIReadWriter: data}
+
+	f := function.Function{
+		ID:   "fn",
+		Name: "fn",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/event"}},
+		},
+		Steps: map[string]function.Step{
+			"step": {
+				ID:      "step",
+				Name:    "Step",
+				Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+			},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		_, err := data.CreateFunctionVersion(ctx, f, true, "prod")
+		require.NoError(t, err)
+	}
+
+	_, err = r.Mutation().RollbackFunction(ctx, f.ID, 0)
+	require.ErrorIs(t, err, coredata.ErrFunctionVersionNotFound)
+
+	fv, err := r.Mutation().RollbackFunction(ctx, f.ID, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, fv.Version)
+
+	versions, err := r.Query().FunctionVersions(ctx, f.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.NotNil(t, versions[0].ValidTo)
+	require.Nil(t, versions[1].ValidTo)
+}
+
+func TestFunctionVersionGraph(t *testing.T) {
+	ctx := context.Background()
+	data, err := inmemorydatastore.New(ctx)
+	require.NoError(t, err)
+	r := &Resolver{APIReadWriter: data}
+
+	wait := "1h"
+	f := function.Function{
+		ID:   "fn",
+		Name: "fn",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/event"}},
+		},
+		Steps: map[string]function.Step{
+			"first": {
+				ID:      "first",
+				Name:    "First",
+				Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+			},
+			"second": {
+				ID:      "second",
+				Name:    "Second",
+				Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+				After:   []function.After{{Step: "first", Wait: &wait}},
+			},
+		},
+	}
+	_, err = data.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	versions, err := r.Query().FunctionVersions(ctx, f.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	out, err := r.FunctionVersion().Graph(ctx, versions[0], nil)
+	require.NoError(t, err)
+	require.Contains(t, out, "flowchart TD\n")
+	require.Contains(t, out, `step1 -->|"wait: 1h"| step2`)
+
+	dot := models.GraphFormatDot
+	out, err = r.FunctionVersion().Graph(ctx, versions[0], &dot)
+	require.NoError(t, err)
+	require.Contains(t, out, `"first" -> "second" [label="wait: 1h"];`)
+}
+
+func TestKnownEvent(t *testing.T) {
+	ctx := context.Background()
+	data, err := inmemorydatastore.New(ctx)
+	require.NoError(t, err)
+	r := &mutationResolver{&Resolver{APIReadWriter: data}}
+
+	fn := func(id, event string) function.Function {
+		return function.Function{
+			ID:   id,
+			Name: id,
+			Triggers: []function.Trigger{
+				{EventTrigger: &function.EventTrigger{Event: event}},
+			},
+			Steps: map[string]function.Step{
+				"step": {
+					ID:      "step",
+					Name:    "Step",
+					Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+				},
+			},
+		}
+	}
+	_, err = data.CreateFunctionVersion(ctx, fn("live", "user/live"), true, "prod")
+	require.NoError(t, err)
+	_, err = data.CreateFunctionVersion(ctx, fn("draft", "user/draft"), false, "prod")
+	require.NoError(t, err)
+	_, err = data.CreateFunctionVersion(ctx, fn("replaced", "user/replaced"), true, "prod")
+	require.NoError(t, err)
+	require.NoError(t, data.CreateSourceKey(ctx, coredata.SourceKey{
+		Key:           "key",
+		Name:          "stripe",
+		EventPrefixes: []string{"stripe/"},
+	}))
+	require.NoError(t, data.SaveEvent(ctx, coredata.Event{ID: "evt", Name: "user/received"}))
+
+	known := r.knownEvent(ctx, "replaced")
+	require.True(t, known("user/live"))
+	require.True(t, known("stripe/charge.succeeded"))
+	require.True(t, known("user/received"))
+	require.False(t, known("user/draft"))
+	require.False(t, known("user/replaced"))
+	require.False(t, known("user/unknown"))
+}
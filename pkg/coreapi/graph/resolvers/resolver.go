@@ -3,19 +3,69 @@ package resolvers
 // THIS CODE IS A STARTING POINT ONLY. IT WILL NOT BE UPDATED WITH SCHEMA CHANGES.
 
 import (
+	"github.com/inngest/inngest/pkg/api"
 	"github.com/inngest/inngest/pkg/coreapi/generated"
 	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/execution/lifecycle"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/pubsub"
 )
 
 type Resolver struct {
 	APIReadWriter coredata.APIReadWriter
+	// State loads and mutates the state of function runs.
+	State state.Manager
+	// Queue enqueues steps when rerunning steps and resuming pauses.
+	Queue queue.Producer
+	// Publisher publishes events to the event stream topic, EventTopic.
+	Publisher  pubsub.Publisher
+	EventTopic string
+	// Ingester prepares sent events in the same manner as the event API.
+	Ingester *api.Ingester
+	// Updates broadcasts step and run lifecycle updates to subscriptions.
+	Updates *lifecycle.Hub
+}
+
+// Event returns generated.EventResolver implementation.
+func (r *Resolver) Event() generated.EventResolver { return &eventResolver{r} }
+
+// EventRun returns generated.EventRunResolver implementation.
+func (r *Resolver) EventRun() generated.EventRunResolver { return &eventRunResolver{r} }
+
+// Function returns generated.FunctionResolver implementation.
+func (r *Resolver) Function() generated.FunctionResolver { return &functionResolver{r} }
+
+// FunctionRun returns generated.FunctionRunResolver implementation.
+func (r *Resolver) FunctionRun() generated.FunctionRunResolver { return &functionRunResolver{r} }
+
+// FunctionVersion returns generated.FunctionVersionResolver implementation.
+func (r *Resolver) FunctionVersion() generated.FunctionVersionResolver {
+	return &functionVersionResolver{r}
 }
 
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// Pause returns generated.PauseResolver implementation.
+func (r *Resolver) Pause() generated.PauseResolver { return &pauseResolver{r} }
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// RunUpdate returns generated.RunUpdateResolver implementation.
+func (r *Resolver) RunUpdate() generated.RunUpdateResolver { return &runUpdateResolver{r} }
+
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
+type eventResolver struct{ *Resolver }
+type eventRunResolver struct{ *Resolver }
+type functionResolver struct{ *Resolver }
+type functionRunResolver struct{ *Resolver }
+type functionVersionResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type pauseResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type runUpdateResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
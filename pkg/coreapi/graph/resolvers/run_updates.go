@@ -0,0 +1,99 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/inngest/inngest/pkg/coreapi/graph/models"
+	"github.com/inngest/inngest/pkg/execution/lifecycle"
+	"github.com/oklog/ulid/v2"
+)
+
+func (r *subscriptionResolver) RunUpdates(ctx context.Context, runID *string, functionID *string) (<-chan *lifecycle.Update, error) {
+	if r.Updates == nil {
+		return nil, fmt.Errorf("run updates are not available")
+	}
+
+	f := lifecycle.Filter{FunctionID: functionID}
+	if runID != nil {
+		id, err := ulid.Parse(*runID)
+		if err != nil {
+			return nil, err
+		}
+		f.RunID = &id
+	}
+
+	// The hub closes its channel once the subscription's context is
+	// cancelled, which in turn closes ours.
+	updates := r.Updates.Subscribe(ctx, f)
+	ch := make(chan *lifecycle.Update)
+	go func() {
+		defer close(ch)
+		for u := range updates {
+			u := u
+			select {
+			case ch <- &u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (r *runUpdateResolver) Kind(ctx context.Context, obj *lifecycle.Update) (models.RunUpdateKind, error) {
+	switch obj.Kind {
+	case lifecycle.KindStepStarted:
+		return models.RunUpdateKindStepStarted, nil
+	case lifecycle.KindStepFinished:
+		return models.RunUpdateKindStepFinished, nil
+	case lifecycle.KindStepFailed:
+		return models.RunUpdateKindStepFailed, nil
+	case lifecycle.KindRunCompleted:
+		return models.RunUpdateKindRunCompleted, nil
+	}
+	return "", fmt.Errorf("unknown update kind: %s", obj.Kind)
+}
+
+func (r *runUpdateResolver) RunID(ctx context.Context, obj *lifecycle.Update) (string, error) {
+	return obj.RunID.String(), nil
+}
+
+func (r *runUpdateResolver) StepID(ctx context.Context, obj *lifecycle.Update) (*string, error) {
+	if obj.StepID == "" {
+		return nil, nil
+	}
+	return &obj.StepID, nil
+}
+
+func (r *runUpdateResolver) Status(ctx context.Context, obj *lifecycle.Update) (*models.RunStatus, error) {
+	if obj.Status == "" {
+		return nil, nil
+	}
+	status := models.RunStatus(strings.ToUpper(string(obj.Status)))
+	if !status.IsValid() {
+		return nil, fmt.Errorf("unknown run status: %s", obj.Status)
+	}
+	return &status, nil
+}
+
+func (r *runUpdateResolver) Output(ctx context.Context, obj *lifecycle.Update) (*string, error) {
+	if obj.Kind != lifecycle.KindStepFinished {
+		return nil, nil
+	}
+	byt, err := json.Marshal(obj.Output)
+	if err != nil {
+		return nil, err
+	}
+	output := string(byt)
+	return &output, nil
+}
+
+func (r *runUpdateResolver) Error(ctx context.Context, obj *lifecycle.Update) (*string, error) {
+	if obj.Error == "" {
+		return nil, nil
+	}
+	return &obj.Error, nil
+}
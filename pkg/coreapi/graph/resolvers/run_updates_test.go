@@ -0,0 +1,94 @@
+package resolvers
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/coreapi/graph/models"
+	"github.com/inngest/inngest/pkg/execution/lifecycle"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUpdates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := lifecycle.NewHub()
+	r := &Resolver{Updates: hub}
+
+	_, err := (&Resolver{}).Subscription().RunUpdates(ctx, nil, nil)
+	require.Error(t, err)
+
+	bad := "nope"
+	_, err = r.Subscription().RunUpdates(ctx, &bad, nil)
+	require.Error(t, err)
+
+	runID := ulid.MustNew(ulid.Now(), rand.Reader)
+	id := runID.String()
+	updates, err := r.Subscription().RunUpdates(ctx, &id, nil)
+	require.NoError(t, err)
+
+	// Updates for other runs are filtered out.
+	hub.Broadcast(lifecycle.Update{Kind: lifecycle.KindStepStarted, RunID: ulid.MustNew(ulid.Now(), rand.Reader)})
+
+	finished := lifecycle.Update{
+		Kind:       lifecycle.KindStepFinished,
+		FunctionID: "fn",
+		RunID:      runID,
+		StepID:     "step",
+		Output:     map[string]interface{}{"ok": true},
+		At:         time.Now(),
+	}
+	hub.Broadcast(finished)
+	completed := lifecycle.Update{
+		Kind:       lifecycle.KindRunCompleted,
+		FunctionID: "fn",
+		RunID:      runID,
+		Status:     state.RunStatusFailed,
+		At:         time.Now(),
+	}
+	hub.Broadcast(completed)
+
+	u := <-updates
+	require.Equal(t, finished, *u)
+	kind, err := r.RunUpdate().Kind(ctx, u)
+	require.NoError(t, err)
+	require.Equal(t, models.RunUpdateKindStepFinished, kind)
+	step, err := r.RunUpdate().StepID(ctx, u)
+	require.NoError(t, err)
+	require.Equal(t, "step", *step)
+	output, err := r.RunUpdate().Output(ctx, u)
+	require.NoError(t, err)
+	require.Equal(t, `{"ok":true}`, *output)
+	status, err := r.RunUpdate().Status(ctx, u)
+	require.NoError(t, err)
+	require.Nil(t, status)
+
+	u = <-updates
+	require.Equal(t, completed, *u)
+	kind, err = r.RunUpdate().Kind(ctx, u)
+	require.NoError(t, err)
+	require.Equal(t, models.RunUpdateKindRunCompleted, kind)
+	step, err = r.RunUpdate().StepID(ctx, u)
+	require.NoError(t, err)
+	require.Nil(t, step)
+	output, err = r.RunUpdate().Output(ctx, u)
+	require.NoError(t, err)
+	require.Nil(t, output)
+	status, err = r.RunUpdate().Status(ctx, u)
+	require.NoError(t, err)
+	require.Equal(t, models.RunStatusFailed, *status)
+
+	// Cancelling the subscription closes the channel.
+	cancel()
+	select {
+	case _, ok := <-updates:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		require.Fail(t, "subscription not closed")
+	}
+}
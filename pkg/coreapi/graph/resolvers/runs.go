@@ -0,0 +1,375 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/coreapi/graph/models"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/runner"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/logger"
+	"github.com/oklog/ulid/v2"
+)
+
+func (r *queryResolver) FunctionRuns(ctx context.Context, query *models.FunctionRunsQuery) ([]*models.FunctionRun, error) {
+	q := coredata.RunQuery{}
+	var status *models.RunStatus
+	if query != nil {
+		q.FunctionID = query.FunctionID
+		if query.Limit != nil {
+			q.Limit = *query.Limit
+		}
+		status = query.Status
+	}
+	limit := q.Size()
+	if status != nil {
+		// A run's status is only known once its state is loaded, so page
+		// through runs, newest first, until enough runs match the status.
+		q.Limit = runStatusPageSize
+	}
+
+	now := time.Now()
+	result := []*models.FunctionRun{}
+	for {
+		runs, err := r.APIReadWriter.Runs(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, run := range r.loadRuns(ctx, runs) {
+			if len(result) == limit {
+				return result, nil
+			}
+			if status != nil && runStatus(run.State, now) != *status {
+				continue
+			}
+			result = append(result, run)
+		}
+
+		if status == nil || len(result) == limit || len(runs) < q.Size() {
+			return result, nil
+		}
+		q.Before = &runs[len(runs)-1].Identifier.RunID
+	}
+}
+
+func (r *queryResolver) FunctionRun(ctx context.Context, id string) (*models.FunctionRun, error) {
+	runID, err := ulid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	run, err := r.run(ctx, runID)
+	if err == errRunNotFound {
+		return nil, nil
+	}
+	return run, err
+}
+
+func (r *mutationResolver) CancelRun(ctx context.Context, runID string) (*models.FunctionRun, error) {
+	run, err := r.parseRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	md := run.State.Metadata()
+	if md.Cancelled {
+		return run, nil
+	}
+	if md.Pending == 0 {
+		return nil, fmt.Errorf("run has already finished")
+	}
+
+	c, ok := r.State.(state.RunCanceller)
+	if !ok {
+		return nil, fmt.Errorf("the state store doesn't support cancelling runs")
+	}
+	id := run.State.Identifier()
+	if err := c.Cancel(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.loadRun(ctx, coredata.EventRun{FunctionID: run.FunctionID, Identifier: id})
+}
+
+func (r *mutationResolver) RerunStep(ctx context.Context, runID string, stepID string) (*models.FunctionRun, error) {
+	run, err := r.parseRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.State.Metadata().Cancelled {
+		return nil, state.ErrFunctionCancelled
+	}
+	w := run.State.Workflow()
+	if !hasStep(w, stepID) {
+		return nil, fmt.Errorf("step not found: %s", stepID)
+	}
+
+	id := run.State.Identifier()
+
+	// Clear the results of the step and every step after it, so that the
+	// executor runs each step again rather than reusing prior output.  State
+	// stores which can't clear results can only rerun incomplete steps.
+	if sr, ok := r.State.(state.StepResetter); ok {
+		if err := sr.ResetSteps(ctx, id, descendants(w, stepID)); err != nil {
+			return nil, err
+		}
+	} else if run.State.ActionComplete(stepID) {
+		return nil, fmt.Errorf("step has already completed: %s", stepID)
+	}
+
+	// Increase the pending count prior to enqueueing, so that the run isn't
+	// finished if the step is finalized before we record that it's scheduled.
+	if err := r.State.Scheduled(ctx, id, stepID); err != nil {
+		return nil, err
+	}
+	err = r.Queue.Enqueue(ctx, queue.Item{
+		Kind:       queue.KindEdge,
+		Identifier: id,
+		FunctionID: run.FunctionID,
+		Payload: queue.PayloadEdge{
+			Edge: inngest.Edge{Incoming: stepID},
+		},
+	}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return r.loadRun(ctx, coredata.EventRun{FunctionID: run.FunctionID, Identifier: id})
+}
+
+func (r *mutationResolver) ResumePause(ctx context.Context, pauseID string, data *string) (*models.FunctionRun, error) {
+	id, err := uuid.Parse(pauseID)
+	if err != nil {
+		return nil, err
+	}
+
+	async := map[string]interface{}{}
+	if data != nil {
+		if err := json.Unmarshal([]byte(*data), &async); err != nil {
+			return nil, fmt.Errorf("invalid pause data: %w", err)
+		}
+	}
+
+	pause, err := r.State.PauseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pause.Expires.Before(time.Now()) {
+		return nil, fmt.Errorf("pause has expired")
+	}
+	if pause.OnTimeout {
+		return nil, fmt.Errorf("pause only resumes on timeout")
+	}
+
+	resumed, err := runner.ResumePause(ctx, *pause, async, r.State, r.Queue)
+	if err != nil {
+		return nil, err
+	}
+	if !resumed {
+		return nil, fmt.Errorf("pause expression does not match data")
+	}
+	return r.run(ctx, pause.Identifier.RunID)
+}
+
+func hasStep(w inngest.Workflow, stepID string) bool {
+	for _, step := range w.Steps {
+		if step.ID == stepID {
+			return true
+		}
+	}
+	return false
+}
+
+// descendants returns the given step and every step reachable from it.
+func descendants(w inngest.Workflow, stepID string) []string {
+	seen := map[string]bool{stepID: true}
+	ids := []string{stepID}
+	for n := 0; n < len(ids); n++ {
+		for _, edge := range w.Edges {
+			if edge.Outgoing == ids[n] && !seen[edge.Incoming] {
+				seen[edge.Incoming] = true
+				ids = append(ids, edge.Incoming)
+			}
+		}
+	}
+	return ids
+}
+
+// runStatusPageSize is the number of runs loaded at a time when filtering
+// runs by status.
+var runStatusPageSize = 100
+
+// errRunNotFound is returned when a run isn't found in the event store.
+var errRunNotFound = errors.New("run not found")
+
+// parseRun loads the run with the given ID.
+func (r *Resolver) parseRun(ctx context.Context, runID string) (*models.FunctionRun, error) {
+	id, err := ulid.Parse(runID)
+	if err != nil {
+		return nil, err
+	}
+	return r.run(ctx, id)
+}
+
+// run loads the run with the given ID, or returns errRunNotFound.
+func (r *Resolver) run(ctx context.Context, runID ulid.ULID) (*models.FunctionRun, error) {
+	runs, err := r.APIReadWriter.Runs(ctx, coredata.RunQuery{RunID: &runID, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, errRunNotFound
+	}
+	return r.loadRun(ctx, runs[0])
+}
+
+// loadRuns loads the state for each of the given runs, in a single batch if
+// the state store supports it.  Runs whose state can't be loaded, eg. as it
+// has expired, are logged and skipped so that they don't prevent listing the
+// remaining runs.
+func (r *Resolver) loadRuns(ctx context.Context, runs []coredata.EventRun) []*models.FunctionRun {
+	ids := make([]state.Identifier, len(runs))
+	for n, run := range runs {
+		ids[n] = run.Identifier
+	}
+
+	var (
+		states []state.State
+		errs   []error
+	)
+	if ml, ok := r.State.(state.MultiLoader); ok {
+		states, errs = ml.LoadMany(ctx, ids)
+	} else {
+		states, errs = make([]state.State, len(ids)), make([]error, len(ids))
+		for n, id := range ids {
+			states[n], errs[n] = r.State.Load(ctx, id)
+		}
+	}
+
+	result := []*models.FunctionRun{}
+	for n, run := range runs {
+		if errs[n] != nil {
+			logger.From(ctx).Warn().
+				Err(errs[n]).
+				Str("run_id", run.Identifier.RunID.String()).
+				Msg("error loading run state")
+			continue
+		}
+		result = append(result, &models.FunctionRun{FunctionID: run.FunctionID, State: states[n]})
+	}
+	return result
+}
+
+// loadRun loads the state for the given run.
+func (r *Resolver) loadRun(ctx context.Context, run coredata.EventRun) (*models.FunctionRun, error) {
+	s, err := r.State.Load(ctx, run.Identifier)
+	if err != nil {
+		return nil, err
+	}
+	return &models.FunctionRun{FunctionID: run.FunctionID, State: s}, nil
+}
+
+func (r *functionRunResolver) ID(ctx context.Context, obj *models.FunctionRun) (string, error) {
+	return obj.State.RunID().String(), nil
+}
+
+func (r *functionRunResolver) WorkflowID(ctx context.Context, obj *models.FunctionRun) (string, error) {
+	return obj.State.WorkflowID().String(), nil
+}
+
+func (r *functionRunResolver) Status(ctx context.Context, obj *models.FunctionRun) (models.RunStatus, error) {
+	return runStatus(obj.State, time.Now()), nil
+}
+
+func (r *functionRunResolver) StartedAt(ctx context.Context, obj *models.FunctionRun) (*time.Time, error) {
+	at := obj.State.Metadata().StartedAt
+	return &at, nil
+}
+
+func (r *functionRunResolver) ScheduledAt(ctx context.Context, obj *models.FunctionRun) (*time.Time, error) {
+	at := obj.State.Metadata().ScheduledAt
+	return &at, nil
+}
+
+func (r *functionRunResolver) Pending(ctx context.Context, obj *models.FunctionRun) (int, error) {
+	return obj.State.Metadata().Pending, nil
+}
+
+func (r *functionRunResolver) Event(ctx context.Context, obj *models.FunctionRun) (string, error) {
+	byt, err := json.Marshal(obj.State.Event())
+	return string(byt), err
+}
+
+func (r *functionRunResolver) Steps(ctx context.Context, obj *models.FunctionRun) ([]*models.StepState, error) {
+	actions := obj.State.Actions()
+	errs := obj.State.Errors()
+	attempts := map[string]int{}
+	if ag, ok := obj.State.(state.AttemptGetter); ok {
+		attempts = ag.Attempts()
+	}
+
+	steps := []*models.StepState{}
+	for _, step := range obj.State.Workflow().Steps {
+		output, hasOutput := actions[step.ID]
+		err, hasErr := errs[step.ID]
+		if !hasOutput && !hasErr {
+			continue
+		}
+
+		s := &models.StepState{ID: step.ID, Name: step.Name, Attempts: attempts[step.ID]}
+		if hasOutput {
+			byt, err := json.Marshal(output)
+			if err != nil {
+				return nil, err
+			}
+			str := string(byt)
+			s.Output = &str
+		}
+		if hasErr {
+			str := err.Error()
+			s.Error = &str
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+func (r *functionRunResolver) Pauses(ctx context.Context, obj *models.FunctionRun) ([]*state.Pause, error) {
+	// Pauses are stored for each outgoing step of an edge, including the
+	// trigger.
+	ids := []string{inngest.TriggerName}
+	for _, step := range obj.State.Workflow().Steps {
+		ids = append(ids, step.ID)
+	}
+
+	if rpg, ok := r.State.(state.RunPauseGetter); ok {
+		return rpg.PausesByRun(ctx, obj.State.Identifier(), ids)
+	}
+
+	pauses := []*state.Pause{}
+	for _, id := range ids {
+		pause, err := r.State.PauseByStep(ctx, obj.State.Identifier(), id)
+		if errors.Is(err, state.ErrPauseNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, pause)
+	}
+	return pauses, nil
+}
+
+func (r *pauseResolver) ID(ctx context.Context, obj *state.Pause) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// runStatus returns the GraphQL status of the given run.
+func runStatus(s state.State, now time.Time) models.RunStatus {
+	return models.RunStatus(strings.ToUpper(string(state.Status(s, now))))
+}
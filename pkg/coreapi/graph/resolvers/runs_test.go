@@ -0,0 +1,394 @@
+package resolvers
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/coreapi/graph/models"
+	"github.com/inngest/inngest/pkg/coredata"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/execution/state/inmemory"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionRun(t *testing.T) {
+	ctx := context.Background()
+	data, err := inmemorydatastore.New(ctx)
+	require.NoError(t, err)
+	sm := inmemory.NewStateManager()
+	expired := state.Identifier{WorkflowID: uuid.New(), RunID: ulid.MustNew(ulid.Now(), rand.Reader)}
+	r := &Resolver{APIReadWriter: data, State: failingLoader{Manager: sm, fail: expired}}
+
+	w := inngest.Workflow{
+		UUID: uuid.New(),
+		Steps: []inngest.Step{
+			{ID: "first", Name: "First"},
+			{ID: "second", Name: "Second"},
+			{ID: "third", Name: "Third"},
+		},
+	}
+	id := state.Identifier{
+		WorkflowID: w.UUID,
+		RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+	}
+	_, err = sm.New(ctx, w, id, map[string]any{"name": "test/event"})
+	require.NoError(t, err)
+
+	evtID := ulid.MustNew(ulid.Now(), rand.Reader).String()
+	err = data.SaveEvent(ctx, coredata.Event{
+		ID:         evtID,
+		Name:       "test/event",
+		Event:      event.Event{ID: evtID, Name: "test/event"},
+		ReceivedAt: time.Now(),
+		Runs:       []coredata.EventRun{{FunctionID: "fn", Identifier: id}},
+	})
+	require.NoError(t, err)
+
+	_, err = sm.SaveResponse(ctx, id, state.DriverResponse{
+		Step:   w.Steps[0],
+		Output: map[string]any{"ok": true},
+	}, 0)
+	require.NoError(t, err)
+	_, err = sm.SaveResponse(ctx, id, state.DriverResponse{
+		Step: w.Steps[1],
+		Err:  fmt.Errorf("retrying"),
+	}, 0)
+	require.NoError(t, err)
+
+	pause := state.Pause{
+		ID:         uuid.New(),
+		Identifier: id,
+		Outgoing:   "first",
+		Incoming:   "third",
+		Expires:    time.Now().Add(time.Hour),
+	}
+	require.NoError(t, sm.SavePause(ctx, pause))
+
+	// Runs whose state can't be loaded, eg. as it expired, are skipped.
+	expiredID := ulid.MustNew(ulid.Now(), rand.Reader).String()
+	err = data.SaveEvent(ctx, coredata.Event{
+		ID:         expiredID,
+		Name:       "test/event",
+		Event:      event.Event{ID: expiredID, Name: "test/event"},
+		ReceivedAt: time.Now(),
+		Runs:       []coredata.EventRun{{FunctionID: "fn", Identifier: expired}},
+	})
+	require.NoError(t, err)
+
+	q := &queryResolver{r}
+	fnID := "fn"
+	runs, err := q.FunctionRuns(ctx, &models.FunctionRunsQuery{FunctionID: &fnID})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, id, runs[0].State.Identifier())
+
+	// Runs can be filtered by status.
+	running, completed := models.RunStatusRunning, models.RunStatusCompleted
+	runs, err = q.FunctionRuns(ctx, &models.FunctionRunsQuery{Status: &running})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	runs, err = q.FunctionRuns(ctx, &models.FunctionRunsQuery{Status: &completed})
+	require.NoError(t, err)
+	require.Len(t, runs, 0)
+
+	// Filtering by status pages through every run.
+	defer func(size int) { runStatusPageSize = size }(runStatusPageSize)
+	runStatusPageSize = 1
+	runs, err = q.FunctionRuns(ctx, &models.FunctionRunsQuery{Status: &running})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, id, runs[0].State.Identifier())
+
+	run, err := q.FunctionRun(ctx, id.RunID.String())
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	require.Equal(t, "fn", run.FunctionID)
+
+	fr := &functionRunResolver{r}
+	status, err := fr.Status(ctx, run)
+	require.NoError(t, err)
+	require.Equal(t, models.RunStatusRunning, status)
+
+	evt, err := fr.Event(ctx, run)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"test/event"}`, evt)
+
+	steps, err := fr.Steps(ctx, run)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	require.Equal(t, "first", steps[0].ID)
+	require.JSONEq(t, `{"ok":true}`, *steps[0].Output)
+	require.Nil(t, steps[0].Error)
+	require.Equal(t, 1, steps[0].Attempts)
+	require.Equal(t, "second", steps[1].ID)
+	require.Nil(t, steps[1].Output)
+	require.Equal(t, "retrying", *steps[1].Error)
+
+	pauses, err := fr.Pauses(ctx, run)
+	require.NoError(t, err)
+	require.Len(t, pauses, 1)
+	require.Equal(t, pause.ID, pauses[0].ID)
+
+	missing, err := q.FunctionRun(ctx, ulid.MustNew(ulid.Now(), rand.Reader).String())
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
+
+// failingLoader errors when loading the given run's state.
+type failingLoader struct {
+	state.Manager
+	fail state.Identifier
+}
+
+func (f failingLoader) Load(ctx context.Context, id state.Identifier) (state.State, error) {
+	if id == f.fail {
+		return nil, fmt.Errorf("state expired")
+	}
+	return f.Manager.Load(ctx, id)
+}
+
+func TestRunStatus(t *testing.T) {
+	ctx := context.Background()
+	sm := inmemory.NewStateManager()
+	now := time.Now()
+
+	w := inngest.Workflow{UUID: uuid.New(), Steps: []inngest.Step{{ID: "1"}}}
+	newRun := func(at time.Time) state.Identifier {
+		id := state.Identifier{WorkflowID: w.UUID, RunID: ulid.MustNew(ulid.Now(), rand.Reader)}
+		_, err := sm.(state.ScheduledMutater).NewAt(ctx, w, id, map[string]any{}, at)
+		require.NoError(t, err)
+		return id
+	}
+	status := func(id state.Identifier) models.RunStatus {
+		s, err := sm.Load(ctx, id)
+		require.NoError(t, err)
+		return runStatus(s, now)
+	}
+
+	require.Equal(t, models.RunStatusScheduled, status(newRun(now.Add(time.Hour))))
+	require.Equal(t, models.RunStatusRunning, status(newRun(now)))
+
+	completed := newRun(now)
+	require.NoError(t, sm.Finalized(ctx, completed, inngest.TriggerName))
+	require.Equal(t, models.RunStatusCompleted, status(completed))
+
+	failed := newRun(now)
+	_, err := sm.SaveResponse(ctx, failed, state.DriverResponse{
+		Step: w.Steps[0],
+		Err:  fmt.Errorf("failed"),
+	}, 0)
+	require.NoError(t, err)
+	require.NoError(t, sm.Finalized(ctx, failed, inngest.TriggerName))
+	require.Equal(t, models.RunStatusFailed, status(failed))
+}
+
+// recordingQueue records every item enqueued.
+type recordingQueue struct {
+	items []queue.Item
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, item queue.Item, at time.Time) error {
+	q.items = append(q.items, item)
+	return nil
+}
+
+func TestRunMutations(t *testing.T) {
+	ctx := context.Background()
+	data, err := inmemorydatastore.New(ctx)
+	require.NoError(t, err)
+	sm := inmemory.NewStateManager()
+	q := &recordingQueue{}
+	r := &Resolver{APIReadWriter: data, State: sm, Queue: q}
+	m := &mutationResolver{r}
+
+	w := inngest.Workflow{
+		UUID: uuid.New(),
+		Steps: []inngest.Step{
+			{ID: "first", Name: "First"},
+			{ID: "second", Name: "Second"},
+		},
+		Edges: []inngest.Edge{
+			{Outgoing: inngest.TriggerName, Incoming: "first"},
+			{Outgoing: "first", Incoming: "second"},
+		},
+	}
+	newRun := func() state.Identifier {
+		id := state.Identifier{
+			WorkflowID: w.UUID,
+			RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+		}
+		_, err = sm.New(ctx, w, id, map[string]any{})
+		require.NoError(t, err)
+
+		evtID := ulid.MustNew(ulid.Now(), rand.Reader).String()
+		err = data.SaveEvent(ctx, coredata.Event{
+			ID:         evtID,
+			Name:       "test/event",
+			Event:      event.Event{ID: evtID, Name: "test/event"},
+			ReceivedAt: time.Now(),
+			Runs:       []coredata.EventRun{{FunctionID: "fn", Identifier: id}},
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	t.Run("it reruns incomplete steps", func(t *testing.T) {
+		id := newRun()
+		_, err = sm.SaveResponse(ctx, id, state.DriverResponse{
+			Step:   w.Steps[0],
+			Output: map[string]any{"ok": true},
+		}, 0)
+		require.NoError(t, err)
+
+		_, err = sm.SaveResponse(ctx, id, state.DriverResponse{
+			Step: w.Steps[1],
+			Err:  fmt.Errorf("second failed"),
+		}, 0)
+		require.NoError(t, err)
+
+		_, err = m.RerunStep(ctx, id.RunID.String(), "missing")
+		require.ErrorContains(t, err, "step not found")
+
+		q.items = nil
+		run, err := m.RerunStep(ctx, id.RunID.String(), "second")
+		require.NoError(t, err)
+		require.Equal(t, 2, run.State.Metadata().Pending)
+		require.Len(t, q.items, 1)
+		edge, err := queue.GetEdge(q.items[0])
+		require.NoError(t, err)
+		require.Equal(t, "second", edge.Incoming)
+		// The step's prior error is cleared.
+		require.Empty(t, run.State.Errors())
+		require.True(t, run.State.ActionComplete("first"))
+	})
+
+	t.Run("it reruns completed steps and the steps after them", func(t *testing.T) {
+		id := newRun()
+		for _, step := range w.Steps {
+			_, err = sm.SaveResponse(ctx, id, state.DriverResponse{
+				Step:   step,
+				Output: map[string]any{"ok": true},
+			}, 0)
+			require.NoError(t, err)
+		}
+
+		// Stores which can't clear step results can't rerun completed steps.
+		_, err = (&mutationResolver{&Resolver{APIReadWriter: data, State: failingLoader{Manager: sm}, Queue: q}}).
+			RerunStep(ctx, id.RunID.String(), "first")
+		require.ErrorContains(t, err, "already completed")
+
+		q.items = nil
+		run, err := m.RerunStep(ctx, id.RunID.String(), "first")
+		require.NoError(t, err)
+		require.Len(t, q.items, 1)
+		require.False(t, run.State.ActionComplete("first"))
+		require.False(t, run.State.ActionComplete("second"))
+		require.Empty(t, run.State.(state.AttemptGetter).Attempts())
+	})
+
+	t.Run("it cancels runs", func(t *testing.T) {
+		id := newRun()
+		run, err := m.CancelRun(ctx, id.RunID.String())
+		require.NoError(t, err)
+		require.True(t, run.State.Metadata().Cancelled)
+		require.Equal(t, models.RunStatusCancelled, runStatus(run.State, time.Now()))
+
+		_, err = m.RerunStep(ctx, id.RunID.String(), "second")
+		require.ErrorIs(t, err, state.ErrFunctionCancelled)
+
+		finished := newRun()
+		require.NoError(t, sm.Finalized(ctx, finished, inngest.TriggerName))
+		_, err = m.CancelRun(ctx, finished.RunID.String())
+		require.ErrorContains(t, err, "already finished")
+
+		_, err = m.CancelRun(ctx, ulid.MustNew(ulid.Now(), rand.Reader).String())
+		require.ErrorIs(t, err, errRunNotFound)
+
+		// Cancelling is optional for state stores.
+		unsupported := &mutationResolver{&Resolver{APIReadWriter: data, State: struct{ state.Manager }{sm}, Queue: q}}
+		_, err = unsupported.CancelRun(ctx, newRun().RunID.String())
+		require.ErrorContains(t, err, "doesn't support cancelling runs")
+	})
+
+	t.Run("it resumes pauses with data", func(t *testing.T) {
+		id := newRun()
+		expr := "async.data.ok == true"
+		pause := state.Pause{
+			ID:         uuid.New(),
+			Identifier: id,
+			Outgoing:   "first",
+			Incoming:   "second",
+			Expires:    time.Now().Add(time.Hour),
+			Expression: &expr,
+		}
+		require.NoError(t, sm.SavePause(ctx, pause))
+
+		notOK := `{"data":{"ok":false}}`
+		_, err := m.ResumePause(ctx, pause.ID.String(), &notOK)
+		require.ErrorContains(t, err, "does not match")
+
+		q.items = nil
+		ok := `{"data":{"ok":true}}`
+		run, err := m.ResumePause(ctx, pause.ID.String(), &ok)
+		require.NoError(t, err)
+		require.Equal(t, id, run.State.Identifier())
+		require.Len(t, q.items, 1)
+		edge, err := queue.GetEdge(q.items[0])
+		require.NoError(t, err)
+		require.Equal(t, "second", edge.Incoming)
+
+		// The data is stored as the async event for the incoming step.
+		require.Equal(t, map[string]interface{}{
+			"data": map[string]interface{}{"ok": true},
+		}, run.State.Actions()[state.AsyncID("second")])
+
+		// The pause is consumed.
+		_, err = sm.PauseByID(ctx, pause.ID)
+		require.ErrorIs(t, err, state.ErrPauseNotFound)
+		_, err = m.ResumePause(ctx, pause.ID.String(), &ok)
+		require.ErrorIs(t, err, state.ErrPauseNotFound)
+	})
+
+	t.Run("it doesn't modify pauses which can't be resumed", func(t *testing.T) {
+		id := newRun()
+		timeout := state.Pause{
+			ID:         uuid.New(),
+			Identifier: id,
+			Outgoing:   "first",
+			Incoming:   "second",
+			Expires:    time.Now().Add(time.Hour),
+			OnTimeout:  true,
+		}
+		require.NoError(t, sm.SavePause(ctx, timeout))
+		leased := state.Pause{
+			ID:         uuid.New(),
+			Identifier: id,
+			Outgoing:   "first",
+			Incoming:   "third",
+			Expires:    time.Now().Add(time.Hour),
+		}
+		require.NoError(t, sm.SavePause(ctx, leased))
+		require.NoError(t, sm.LeasePause(ctx, leased.ID))
+
+		q.items = nil
+		_, err := m.ResumePause(ctx, timeout.ID.String(), nil)
+		require.ErrorContains(t, err, "only resumes on timeout")
+		_, err = m.ResumePause(ctx, leased.ID.String(), nil)
+		require.ErrorIs(t, err, state.ErrPauseLeased)
+
+		require.Empty(t, q.items)
+		for _, p := range []state.Pause{timeout, leased} {
+			_, err = sm.PauseByID(ctx, p.ID)
+			require.NoError(t, err, "pause must not be consumed")
+		}
+	})
+}
@@ -2,13 +2,21 @@ package coreapi
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"net/http"
 
+	"github.com/inngest/inngest/pkg/api"
+	"github.com/inngest/inngest/pkg/api/ratelimit"
 	"github.com/inngest/inngest/pkg/config"
 	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/execution/lifecycle"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/state"
 	"github.com/inngest/inngest/pkg/logger"
+	"github.com/inngest/inngest/pkg/pubsub"
 	"github.com/inngest/inngest/pkg/service"
+	"github.com/oklog/ulid/v2"
 )
 
 type Opt func(s *svc)
@@ -27,11 +35,28 @@ func WithAPIReadWriter(rw coredata.APIReadWriter) func(s *svc) {
 	}
 }
 
+// WithState configures the state manager used to load function runs.
+func WithState(sm state.Manager) func(s *svc) {
+	return func(s *svc) {
+		s.state = sm
+	}
+}
+
 type svc struct {
 	config config.Config
 	api    *CoreAPI
 	// data provides the ability to write and load data
 	data coredata.APIReadWriter
+	// state loads the state of function runs
+	state state.Manager
+	// queue enqueues steps when acting on runs
+	queue queue.Producer
+	// publisher publishes events to the event stream
+	publisher pubsub.Publisher
+	// subscriber subscribes to run lifecycle updates, broadcasting them to
+	// GraphQL subscriptions via updates.
+	subscriber pubsub.Subscriber
+	updates    *lifecycle.Hub
 }
 
 func (s *svc) Name() string {
@@ -39,16 +64,58 @@ func (s *svc) Name() string {
 }
 
 func (s *svc) Pre(ctx context.Context) (err error) {
-	s.data, err = s.config.DataStore.Service.Concrete.ReadWriter(ctx)
+	rw, err := s.config.DataStore.Service.Concrete.ReadWriter(ctx)
 	if err != nil {
 		return err
 	}
+	s.data = rw
+
+	if s.state == nil {
+		s.state, err = s.config.State.Service.Concrete.Manager(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.queue, err = s.config.Queue.Service.Concrete.Producer()
+	if err != nil {
+		return err
+	}
+
+	s.publisher, err = pubsub.NewPublisher(ctx, s.config.EventStream.Service)
+	if err != nil {
+		return err
+	}
+
+	s.subscriber, err = pubsub.NewSubscriber(ctx, s.config.EventStream.Service)
+	if err != nil {
+		return err
+	}
+	s.updates = lifecycle.NewHub()
+
+	var limiter ratelimit.Limiter
+	if s.config.EventAPI.RateLimit != nil {
+		limiter, err = ratelimit.New(ctx, *s.config.EventAPI.RateLimit)
+		if err != nil {
+			return err
+		}
+	}
 
 	// TODO - Configure API with correct ports, etc., set up routes
 	s.api, err = NewCoreApi(Options{
 		Config:        s.config,
 		Logger:        logger.From(ctx),
 		APIReadWriter: s.data,
+		State:         s.state,
+		Queue:         s.queue,
+		Publisher:     s.publisher,
+		Ingester: api.NewIngester(api.Options{
+			Config:    s.config,
+			Logger:    logger.From(ctx),
+			Limiter:   limiter,
+			Functions: rw,
+		}),
+		Updates: s.updates,
 	})
 
 	if err != nil {
@@ -58,13 +125,41 @@ func (s *svc) Pre(ctx context.Context) (err error) {
 }
 
 func (s *svc) Run(ctx context.Context) error {
+	go s.subscribeUpdates(ctx)
+
 	err := s.api.Start(ctx)
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
 	return err
 }
+
+// subscribeUpdates subscribes to run lifecycle updates until the context is
+// cancelled.  Every instance of the API streams all updates to its own
+// subscriptions, so each subscribes using a unique consumer group which is
+// removed when the API stops.
+func (s *svc) subscribeUpdates(ctx context.Context) {
+	topic := s.config.EventStream.LifecycleTopic
+	if topic == "" {
+		return
+	}
+
+	group := s.Name() + "-" + ulid.MustNew(ulid.Now(), rand.Reader).String()
+
+	logger.From(ctx).Info().Str("topic", topic).Msg("subscribing to run updates")
+	err := s.subscriber.Subscribe(pubsub.WithEphemeralConsumerGroup(ctx, group), topic, s.updates.Handle)
+	if err != nil {
+		logger.From(ctx).Error().Err(err).Str("topic", topic).Msg("error subscribing to run updates")
+	}
+}
+
 func (s *svc) Stop(ctx context.Context) error {
 	// TODO - Gracefully shut down server, remove connection to coredata database
-	return s.api.Stop(ctx)
+	if err := s.api.Stop(ctx); err != nil {
+		return err
+	}
+	if err := pubsub.Close(s.subscriber); err != nil {
+		return err
+	}
+	return pubsub.Close(s.publisher)
 }
@@ -12,6 +12,9 @@ import (
 type ReadWriter interface {
 	APIReadWriter
 	ExecutionLoader
+	EventStore
+	SourceKeyStore
+	DeliveryStore
 }
 
 // ExecutionLoader is an interface which specifies all functions required to run
@@ -37,14 +40,27 @@ type ExecutionActionLoader interface {
 }
 
 type APIReadWriter interface {
+	APIFunctionReader
 	APIFunctionWriter
 	APIActionReader
 	APIActionWriter
+	EventReader
+}
+
+type APIFunctionReader interface {
+	// Functions returns the live version of all deployed functions.
+	Functions(ctx context.Context) ([]function.Function, error)
+	// FunctionVersions returns every version of the given function, newest first.
+	FunctionVersions(ctx context.Context, functionID string) ([]function.FunctionVersion, error)
 }
 
 type APIFunctionWriter interface {
 	// Create a new function
 	CreateFunctionVersion(ctx context.Context, f function.Function, live bool, env string) (function.FunctionVersion, error)
+	// RollbackFunction atomically makes the given prior version of a function live,
+	// replacing the current live version.  This returns ErrFunctionVersionNotFound
+	// if the version doesn't exist.
+	RollbackFunction(ctx context.Context, functionID string, version uint) (function.FunctionVersion, error)
 }
 type APIActionReader interface {
 	// Find a given action by an exact version number
@@ -58,3 +74,5 @@ type APIActionWriter interface {
 }
 
 var ErrActionVersionNotFound error = errors.New("action version not found")
+
+var ErrFunctionVersionNotFound = errors.New("function version not found")
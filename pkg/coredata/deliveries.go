@@ -0,0 +1,84 @@
+package coredata
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultDeliveryQueryLimit is the number of deliveries returned by
+// default when querying deliveries.
+const DefaultDeliveryQueryLimit = 50
+
+// DeliveryStore stores a log of each attempt to deliver events to outbound
+// webhook subscriptions.
+type DeliveryStore interface {
+	DeliveryReader
+	DeliveryWriter
+}
+
+type DeliveryReader interface {
+	// Deliveries returns delivery attempts matching the given query, ordered
+	// by most recent first.
+	Deliveries(ctx context.Context, q DeliveryQuery) ([]Delivery, error)
+}
+
+type DeliveryWriter interface {
+	// SaveDelivery records a delivery attempt.
+	SaveDelivery(ctx context.Context, d Delivery) error
+}
+
+// Delivery records a single attempt to deliver an event to a subscription.
+type Delivery struct {
+	// ID is the ID of the delivery, which is the same for every attempt
+	// to deliver the same event to the same subscription.
+	ID string `json:"id"`
+	// SubscriptionID is the ID of the subscription the event was sent to.
+	SubscriptionID string `json:"subscriptionID"`
+	// URL is the URL the event was sent to.
+	URL string `json:"url"`
+	// EventID and EventName are the ID and name of the event delivered.
+	EventID   string `json:"eventID"`
+	EventName string `json:"eventName"`
+	// Attempt is the attempt number, starting from 1.
+	Attempt int `json:"attempt"`
+	// StatusCode is the HTTP status code of the response, or 0 if no
+	// response was received.
+	StatusCode int `json:"statusCode"`
+	// Error describes why the attempt failed, if the attempt failed.
+	Error string `json:"error,omitempty"`
+	// At is the time of the attempt.
+	At time.Time `json:"at"`
+}
+
+// Succeeded returns whether the attempt succeeded.
+func (d Delivery) Succeeded() bool {
+	return d.Error == ""
+}
+
+// DeliveryQuery filters deliveries.  All fields are optional.
+type DeliveryQuery struct {
+	SubscriptionID *string
+	EventID        *string
+	// Limit is the maximum number of deliveries returned, defaulting to
+	// DefaultDeliveryQueryLimit.
+	Limit int
+}
+
+// Matches returns whether the given delivery matches the query's filters.
+func (q DeliveryQuery) Matches(d Delivery) bool {
+	if q.SubscriptionID != nil && *q.SubscriptionID != d.SubscriptionID {
+		return false
+	}
+	if q.EventID != nil && *q.EventID != d.EventID {
+		return false
+	}
+	return true
+}
+
+// Size returns the maximum number of deliveries to return.
+func (q DeliveryQuery) Size() int {
+	if q.Limit <= 0 {
+		return DefaultDeliveryQueryLimit
+	}
+	return q.Limit
+}
@@ -0,0 +1,142 @@
+package coredata
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/oklog/ulid/v2"
+)
+
+var ErrEventNotFound = errors.New("event not found")
+
+// EventStore persists every event received along with the function runs that
+// each event created.  This provides an audit log of events for debugging, and
+// allows events to be replayed at a later date.
+type EventStore interface {
+	EventReader
+	EventWriter
+}
+
+type EventWriter interface {
+	// SaveEvent stores the given event.  Saving an event which already exists
+	// appends the given runs to the stored event, ignoring any runs which have
+	// already been recorded.  This allows the runner to retry events without
+	// losing or duplicating run information.
+	SaveEvent(ctx context.Context, e Event) error
+}
+
+type EventReader interface {
+	// Event returns a single event by its ID, or ErrEventNotFound.
+	Event(ctx context.Context, id string) (*Event, error)
+	// Events returns all events matching the given query, ordered by the
+	// time they were received, newest first.
+	Events(ctx context.Context, q EventQuery) ([]Event, error)
+	// Runs returns all function runs matching the given query, newest
+	// first.
+	Runs(ctx context.Context, q RunQuery) ([]EventRun, error)
+}
+
+// Event represents a stored event.
+type Event struct {
+	// ID is the ID of the event, as assigned by the event API.
+	ID string `json:"id"`
+	// Name is the name of the event.
+	Name string `json:"name"`
+	// Event is the full event payload.
+	Event event.Event `json:"event"`
+	// ReceivedAt is the time that the event was received.
+	ReceivedAt time.Time `json:"receivedAt"`
+	// Runs lists every function run initialized by the event.
+	Runs []EventRun `json:"runs"`
+}
+
+// EventRun represents a single function run created by an event.
+type EventRun struct {
+	FunctionID string           `json:"functionID"`
+	Identifier state.Identifier `json:"identifier"`
+}
+
+// DefaultEventQueryLimit is the number of events returned from an event
+// query if no limit is specified.
+const DefaultEventQueryLimit = 50
+
+// EventQuery filters events.  Each field is optional;  nil fields do not
+// filter events.
+type EventQuery struct {
+	// ID returns only the event with the given ID.
+	ID *string
+	// Name returns only events with the given name.
+	Name *string
+	// From returns only events received at or after the given time.
+	From *time.Time
+	// To returns only events received before the given time.
+	To *time.Time
+	// Limit limits the number of events returned, defaulting to
+	// DefaultEventQueryLimit.
+	Limit int
+}
+
+// Matches returns whether the given event matches the query, ignoring limits.
+func (q EventQuery) Matches(e Event) bool {
+	if q.ID != nil && e.ID != *q.ID {
+		return false
+	}
+	if q.Name != nil && e.Name != *q.Name {
+		return false
+	}
+	if q.From != nil && e.ReceivedAt.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && !e.ReceivedAt.Before(*q.To) {
+		return false
+	}
+	return true
+}
+
+// Size returns the maximum number of events to return for the query.
+func (q EventQuery) Size() int {
+	if q.Limit <= 0 {
+		return DefaultEventQueryLimit
+	}
+	return q.Limit
+}
+
+// RunQuery filters function runs.  Each field is optional;  nil fields do not
+// filter runs.
+type RunQuery struct {
+	// FunctionID returns only runs of the given function.
+	FunctionID *string
+	// RunID returns only the run with the given ID.
+	RunID *ulid.ULID
+	// Before returns only runs created before the run with the given ID,
+	// allowing callers to page through runs.
+	Before *ulid.ULID
+	// Limit limits the number of runs returned, defaulting to
+	// DefaultEventQueryLimit.
+	Limit int
+}
+
+// Matches returns whether the given run matches the query, ignoring limits.
+func (q RunQuery) Matches(r EventRun) bool {
+	if q.FunctionID != nil && r.FunctionID != *q.FunctionID {
+		return false
+	}
+	if q.RunID != nil && r.Identifier.RunID != *q.RunID {
+		return false
+	}
+	if q.Before != nil && r.Identifier.RunID.Compare(*q.Before) >= 0 {
+		return false
+	}
+	return true
+}
+
+// Size returns the maximum number of runs to return for the query.
+func (q RunQuery) Size() int {
+	if q.Limit <= 0 {
+		return DefaultEventQueryLimit
+	}
+	return q.Limit
+}
@@ -0,0 +1,41 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/inngest/inngest/pkg/coredata"
+)
+
+// MemoryDeliveryStore is an in-memory coredata.DeliveryStore, for development
+// and testing only.
+type MemoryDeliveryStore struct {
+	lock       sync.RWMutex
+	deliveries []coredata.Delivery
+}
+
+func NewInMemoryDeliveryStore() *MemoryDeliveryStore {
+	return &MemoryDeliveryStore{}
+}
+
+func (m *MemoryDeliveryStore) SaveDelivery(ctx context.Context, d coredata.Delivery) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *MemoryDeliveryStore) Deliveries(ctx context.Context, q coredata.DeliveryQuery) ([]coredata.Delivery, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	// Deliveries are appended in order, so iterate in reverse to return
+	// the most recent first.
+	result := []coredata.Delivery{}
+	for i := len(m.deliveries) - 1; i >= 0 && len(result) < q.Size(); i-- {
+		if q.Matches(m.deliveries[i]) {
+			result = append(result, m.deliveries[i])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/inngest/inngest/pkg/coredata"
+)
+
+// MemoryEventStore is an in-memory coredata.EventStore, for development
+// and testing only.
+type MemoryEventStore struct {
+	lock   sync.RWMutex
+	events map[string]*coredata.Event
+}
+
+func NewInMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{
+		events: map[string]*coredata.Event{},
+	}
+}
+
+func (m *MemoryEventStore) SaveEvent(ctx context.Context, e coredata.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	existing, ok := m.events[e.ID]
+	if !ok {
+		copied := e
+		copied.Runs = append([]coredata.EventRun{}, e.Runs...)
+		m.events[e.ID] = &copied
+		return nil
+	}
+
+	for _, run := range e.Runs {
+		found := false
+		for _, r := range existing.Runs {
+			if r.Identifier.RunID == run.Identifier.RunID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing.Runs = append(existing.Runs, run)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryEventStore) Event(ctx context.Context, id string) (*coredata.Event, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	e, ok := m.events[id]
+	if !ok {
+		return nil, coredata.ErrEventNotFound
+	}
+	copied := *e
+	copied.Runs = append([]coredata.EventRun{}, e.Runs...)
+	return &copied, nil
+}
+
+func (m *MemoryEventStore) Events(ctx context.Context, q coredata.EventQuery) ([]coredata.Event, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	events := []coredata.Event{}
+	for _, e := range m.events {
+		if !q.Matches(*e) {
+			continue
+		}
+		copied := *e
+		copied.Runs = append([]coredata.EventRun{}, e.Runs...)
+		events = append(events, copied)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].ReceivedAt.Equal(events[j].ReceivedAt) {
+			// Event IDs are ULIDs, which are lexicographically sortable.
+			return events[i].ID > events[j].ID
+		}
+		return events[i].ReceivedAt.After(events[j].ReceivedAt)
+	})
+
+	if len(events) > q.Size() {
+		events = events[:q.Size()]
+	}
+	return events, nil
+}
+
+func (m *MemoryEventStore) Runs(ctx context.Context, q coredata.RunQuery) ([]coredata.EventRun, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	runs := []coredata.EventRun{}
+	for _, e := range m.events {
+		for _, r := range e.Runs {
+			if q.Matches(r) {
+				runs = append(runs, r)
+			}
+		}
+	}
+
+	// Run IDs are ULIDs, so sorting by ID sorts runs by creation time.
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Identifier.RunID.Compare(runs[j].Identifier.RunID) > 0
+	})
+
+	if len(runs) > q.Size() {
+		runs = runs[:q.Size()]
+	}
+	return runs, nil
+}
@@ -0,0 +1,132 @@
+package inmemory
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryEventStore()
+
+	now := time.Now()
+	newEvent := func(name string, at time.Time) coredata.Event {
+		id := ulid.MustNew(ulid.Timestamp(at), rand.Reader).String()
+		return coredata.Event{
+			ID:         id,
+			Name:       name,
+			Event:      event.Event{ID: id, Name: name},
+			ReceivedAt: at,
+		}
+	}
+
+	a := newEvent("test/a", now.Add(-time.Hour))
+	b := newEvent("test/b", now.Add(-time.Minute))
+	c := newEvent("test/a", now)
+	for _, e := range []coredata.Event{a, b, c} {
+		require.NoError(t, store.SaveEvent(ctx, e))
+	}
+
+	_, err := store.Event(ctx, "missing")
+	require.ErrorIs(t, err, coredata.ErrEventNotFound)
+
+	t.Run("it lists events newest first", func(t *testing.T) {
+		events, err := store.Events(ctx, coredata.EventQuery{})
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		require.Equal(t, c.ID, events[0].ID)
+		require.Equal(t, b.ID, events[1].ID)
+		require.Equal(t, a.ID, events[2].ID)
+	})
+
+	t.Run("it filters by name", func(t *testing.T) {
+		name := "test/a"
+		events, err := store.Events(ctx, coredata.EventQuery{Name: &name})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, c.ID, events[0].ID)
+		require.Equal(t, a.ID, events[1].ID)
+	})
+
+	t.Run("it filters by time range", func(t *testing.T) {
+		from, to := now.Add(-2*time.Minute), now
+		events, err := store.Events(ctx, coredata.EventQuery{From: &from, To: &to})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, b.ID, events[0].ID)
+	})
+
+	t.Run("it filters by ID and limits", func(t *testing.T) {
+		events, err := store.Events(ctx, coredata.EventQuery{ID: &b.ID})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		events, err = store.Events(ctx, coredata.EventQuery{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, c.ID, events[0].ID)
+	})
+
+	t.Run("it appends runs without duplicates", func(t *testing.T) {
+		run := coredata.EventRun{
+			FunctionID: "fn",
+			Identifier: state.Identifier{
+				WorkflowID: uuid.New(),
+				RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+				Key:        a.ID,
+			},
+		}
+		a.Runs = []coredata.EventRun{run}
+		require.NoError(t, store.SaveEvent(ctx, a))
+		require.NoError(t, store.SaveEvent(ctx, a))
+
+		found, err := store.Event(ctx, a.ID)
+		require.NoError(t, err)
+		require.Equal(t, []coredata.EventRun{run}, found.Runs)
+	})
+
+	t.Run("it lists runs newest first", func(t *testing.T) {
+		newRun := func(fnID string, at time.Time) coredata.EventRun {
+			return coredata.EventRun{
+				FunctionID: fnID,
+				Identifier: state.Identifier{
+					WorkflowID: uuid.New(),
+					RunID:      ulid.MustNew(ulid.Timestamp(at), rand.Reader),
+				},
+			}
+		}
+		x := newRun("runs", now.Add(-time.Minute))
+		y := newRun("runs", now)
+		other := newRun("other", now)
+		b.Runs = []coredata.EventRun{x, other}
+		c.Runs = []coredata.EventRun{y}
+		require.NoError(t, store.SaveEvent(ctx, b))
+		require.NoError(t, store.SaveEvent(ctx, c))
+
+		fnID := "runs"
+		runs, err := store.Runs(ctx, coredata.RunQuery{FunctionID: &fnID})
+		require.NoError(t, err)
+		require.Equal(t, []coredata.EventRun{y, x}, runs)
+
+		runs, err = store.Runs(ctx, coredata.RunQuery{RunID: &other.Identifier.RunID})
+		require.NoError(t, err)
+		require.Equal(t, []coredata.EventRun{other}, runs)
+
+		runs, err = store.Runs(ctx, coredata.RunQuery{FunctionID: &fnID, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, []coredata.EventRun{y}, runs)
+
+		runs, err = store.Runs(ctx, coredata.RunQuery{FunctionID: &fnID, Before: &y.Identifier.RunID})
+		require.NoError(t, err)
+		require.Equal(t, []coredata.EventRun{x}, runs)
+	})
+}
@@ -22,25 +22,50 @@ func init() {
 	registration.RegisterDataStore(func() any { return &Config{} })
 }
 
-type Config struct{}
+// Config registers the configuration for the in-memory data store.  The
+// ReadWriter is a singleton per config instance so that services running
+// within the same process share data.
+type Config struct {
+	l  sync.Mutex
+	rw *ReadWriter
+}
 
-func (c Config) DataStoreName() string {
+func (c *Config) DataStoreName() string {
 	return "inmemory"
 }
 
-func (c Config) ReadWriter(ctx context.Context) (coredata.ReadWriter, error) {
-	return New(ctx)
+func (c *Config) ReadWriter(ctx context.Context) (coredata.ReadWriter, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.rw == nil {
+		rw, err := New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.rw = rw
+	}
+	return c.rw, nil
 }
 
 type ReadWriter struct {
 	*MemoryAPIReadWriter
 	*MemoryExecutionLoader
+	*MemoryEventStore
+	*MemorySourceKeyStore
+	*MemoryDeliveryStore
 }
 
 func New(ctx context.Context) (*ReadWriter, error) {
+	// Share the execution loader with the API so that deployed functions
+	// are executed.
+	api := NewInMemoryAPIReadWriter()
 	return &ReadWriter{
-		MemoryAPIReadWriter:   NewInMemoryAPIReadWriter(),
-		MemoryExecutionLoader: &MemoryExecutionLoader{},
+		MemoryAPIReadWriter:   api,
+		MemoryExecutionLoader: api.MemoryExecutionLoader,
+		MemoryEventStore:      NewInMemoryEventStore(),
+		MemorySourceKeyStore:  NewInMemorySourceKeyStore(),
+		MemoryDeliveryStore:   NewInMemoryDeliveryStore(),
 	}, nil
 }
 
@@ -56,6 +81,11 @@ type FSLoader struct {
 	root string
 }
 
+// Root returns the absolute path of the root directory that functions are loaded from.
+func (f *FSLoader) Root() string {
+	return f.root
+}
+
 // ReadDir recursively reads the root directory, loading all functions
 // into the loader.
 func (f *FSLoader) ReadDir(ctx context.Context) error {
@@ -68,16 +98,13 @@ func (f *FSLoader) ReadDir(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	for _, fn := range fns {
-		f.functions = append(f.functions, *fn)
-	}
 
 	return f.MemoryExecutionLoader.SetFunctions(ctx, fns)
 }
 
 // NewFSLoader returns an ExecutionLoader which reads functions from the given
 // path, recursively.
-func NewFSLoader(ctx context.Context, path string) (coredata.ExecutionLoader, error) {
+func NewFSLoader(ctx context.Context, path string) (*FSLoader, error) {
 	// XXX: This should probably be a singleton;  this is primarily used
 	// for the dev server.  in this case, a single process hosts the
 	// runner and the executor together - and we don't want to process
@@ -105,12 +132,16 @@ type MemoryExecutionLoader struct {
 
 	// actions stores all actions parsed and read from functions within the filesystem.
 	actions []inngest.ActionVersion
+
+	// lock guards functions, actions and the action loader, which are replaced
+	// together whenever functions are set.
+	lock sync.RWMutex
 }
 
+// SetFunctions validates the given functions and replaces all functions and
+// actions within the loader.  Functions are replaced atomically, and the
+// loader is left unchanged if any function is invalid.
 func (m *MemoryExecutionLoader) SetFunctions(ctx context.Context, f []*function.Function) error {
-	m.functions = []function.Function{}
-	m.actions = []inngest.ActionVersion{}
-
 	// Validate all functions.
 	eg := &errgroup.Group{}
 	for _, fn := range f {
@@ -123,34 +154,57 @@ func (m *MemoryExecutionLoader) SetFunctions(ctx context.Context, f []*function.
 		return err
 	}
 
+	functions := []function.Function{}
+	actions := []inngest.ActionVersion{}
 	for _, fn := range f {
-		actions, _, _ := fn.Actions(ctx)
-		m.actions = append(m.actions, actions...)
-		m.functions = append(m.functions, *fn)
+		fnActions, _, _ := fn.Actions(ctx)
+		actions = append(actions, fnActions...)
+		functions = append(functions, *fn)
 	}
 
 	// recreate the in-memory action loader.
-	m.memactionloader = &memactionloader{
-		Actions: make(map[string][]inngest.ActionVersion),
-		lock:    &sync.RWMutex{},
-	}
-	for _, a := range m.actions {
-		m.memactionloader.Add(a)
+	loader := NewInMemoryActionLoader()
+	for _, a := range actions {
+		loader.Add(a)
 	}
 
+	m.lock.Lock()
+	m.functions = functions
+	m.actions = actions
+	m.memactionloader = loader
+	m.lock.Unlock()
+
 	logger.From(ctx).
 		Debug().
-		Int("len", len(m.functions)).
+		Int("len", len(functions)).
 		Msg("added functions")
 
 	return nil
 }
 
+// Action returns an action from the functions within the loader, given its DSN and
+// optional version constraint.
+func (m *MemoryExecutionLoader) Action(ctx context.Context, dsn string, version *inngest.VersionConstraint) (*inngest.ActionVersion, error) {
+	m.lock.RLock()
+	loader := m.memactionloader
+	m.lock.RUnlock()
+
+	if loader == nil {
+		return nil, fmt.Errorf("action not found: %s", dsn)
+	}
+	return loader.Action(ctx, dsn, version)
+}
+
 func (m *MemoryExecutionLoader) Functions(ctx context.Context) ([]function.Function, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	return m.functions, nil
 }
 
 func (m *MemoryExecutionLoader) FunctionsScheduled(ctx context.Context) ([]function.Function, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	fns := []function.Function{}
 	for _, fn := range m.functions {
 		for _, t := range fn.Triggers {
@@ -164,6 +218,9 @@ func (m *MemoryExecutionLoader) FunctionsScheduled(ctx context.Context) ([]funct
 }
 
 func (m *MemoryExecutionLoader) FunctionsByTrigger(ctx context.Context, eventName string) ([]function.Function, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	fns := []function.Function{}
 	for _, fn := range m.functions {
 		for _, t := range fn.Triggers {
@@ -252,27 +309,141 @@ func (l memactionloader) Action(ctx context.Context, dsn string, version *innges
 
 type MemoryAPIFunctionWriter struct {
 	*MemoryExecutionLoader
+
+	lock sync.RWMutex
+	// versions stores every version of each function, oldest first, keyed
+	// by function ID.
+	versions map[string][]function.FunctionVersion
 }
 
 func NewInMemoryAPIFunctionWriter() *MemoryAPIFunctionWriter {
-	loader := &MemoryAPIFunctionWriter{}
+	loader := &MemoryAPIFunctionWriter{
+		versions: map[string][]function.FunctionVersion{},
+	}
 	loader.MemoryExecutionLoader = &MemoryExecutionLoader{}
 	return loader
 }
 
 func (m *MemoryAPIFunctionWriter) CreateFunctionVersion(ctx context.Context, f function.Function, live bool, env string) (function.FunctionVersion, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	config, err := function.MarshalCUE(f)
+	if err != nil {
+		return function.FunctionVersion{}, err
+	}
+
 	now := time.Now()
+	existing := m.versions[f.ID]
 	fv := function.FunctionVersion{
 		FunctionID: f.ID,
-		Version:    uint(1),
+		Version:    uint(len(existing) + 1),
+		Config:     string(config),
 		Function:   f,
-		ValidFrom:  &now,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
+	if !live {
+		m.versions[f.ID] = append(existing, fv)
+		return fv, nil
+	}
+
+	// Only one version of a function is live at a time.
+	fv.ValidFrom = &now
+	updated := copyVersions(existing)
+	expireLive(updated, now)
+	m.versions[f.ID] = append(updated, fv)
+
+	if err := m.setLive(ctx); err != nil {
+		m.restore(f.ID, existing)
+		return function.FunctionVersion{}, err
+	}
 	return fv, nil
 }
 
+func (m *MemoryAPIFunctionWriter) RollbackFunction(ctx context.Context, functionID string, version uint) (function.FunctionVersion, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	existing := m.versions[functionID]
+	if version == 0 || int(version) > len(existing) {
+		return function.FunctionVersion{}, coredata.ErrFunctionVersionNotFound
+	}
+	versions := copyVersions(existing)
+
+	n := version - 1
+	if versions[n].ValidFrom != nil && versions[n].ValidTo == nil {
+		// This version is already live.
+		return versions[n], nil
+	}
+
+	now := time.Now()
+	expireLive(versions, now)
+	versions[n].ValidFrom = &now
+	versions[n].ValidTo = nil
+	versions[n].UpdatedAt = now
+	m.versions[functionID] = versions
+
+	if err := m.setLive(ctx); err != nil {
+		m.restore(functionID, existing)
+		return function.FunctionVersion{}, err
+	}
+	return versions[n], nil
+}
+
+// restore replaces a function's versions with the versions prior to a failed
+// change.  This must be called with the lock held.
+func (m *MemoryAPIFunctionWriter) restore(functionID string, versions []function.FunctionVersion) {
+	if versions == nil {
+		delete(m.versions, functionID)
+		return
+	}
+	m.versions[functionID] = versions
+}
+
+// copyVersions copies the given versions, so that they can be changed without
+// altering the originals.
+func copyVersions(versions []function.FunctionVersion) []function.FunctionVersion {
+	return append(make([]function.FunctionVersion, 0, len(versions)+1), versions...)
+}
+
+// setLive updates the execution loader so that the live version of each
+// function is executed.  This must be called with the lock held.
+func (m *MemoryAPIFunctionWriter) setLive(ctx context.Context) error {
+	fns := []*function.Function{}
+	for _, versions := range m.versions {
+		for _, v := range versions {
+			if v.ValidFrom != nil && v.ValidTo == nil {
+				copied := v.Function
+				fns = append(fns, &copied)
+			}
+		}
+	}
+	return m.MemoryExecutionLoader.SetFunctions(ctx, fns)
+}
+
+// expireLive marks any live version within the given versions as no longer live.
+func expireLive(versions []function.FunctionVersion, at time.Time) {
+	for n, v := range versions {
+		if v.ValidFrom != nil && v.ValidTo == nil {
+			versions[n].ValidTo = &at
+			versions[n].UpdatedAt = at
+		}
+	}
+}
+
+func (m *MemoryAPIFunctionWriter) FunctionVersions(ctx context.Context, functionID string) ([]function.FunctionVersion, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	versions := m.versions[functionID]
+	result := make([]function.FunctionVersion, len(versions))
+	for n, v := range versions {
+		result[len(versions)-1-n] = v
+	}
+	return result, nil
+}
+
 type MemoryAPIReadWriter struct {
 	*MemoryAPIFunctionWriter
 	*MemoryAPIActionLoader
@@ -0,0 +1,216 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionVersions(t *testing.T) {
+	ctx := context.Background()
+	rw, err := New(ctx)
+	require.NoError(t, err)
+
+	f := function.Function{
+		Name: "test",
+		ID:   "test-fn",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/event"}},
+		},
+		Steps: map[string]function.Step{
+			"step-1": {
+				ID:   "step-1",
+				Name: "Step #1",
+				Runtime: inngest.RuntimeWrapper{
+					Runtime: inngest.RuntimeDocker{},
+				},
+			},
+		},
+	}
+
+	v1, err := rw.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, v1.Version)
+	require.NotEmpty(t, v1.Config)
+
+	v2, err := rw.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, v2.Version)
+
+	// Draft versions aren't live.
+	_, err = rw.CreateFunctionVersion(ctx, f, false, "prod")
+	require.NoError(t, err)
+
+	versions, err := rw.FunctionVersions(ctx, f.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	require.EqualValues(t, 3, versions[0].Version)
+	require.Nil(t, versions[0].ValidFrom)
+	require.EqualValues(t, 2, versions[1].Version)
+	require.Nil(t, versions[1].ValidTo)
+	require.EqualValues(t, 1, versions[2].Version)
+	require.NotNil(t, versions[2].ValidTo)
+
+	// Live functions are executed.
+	fns, err := rw.FunctionsByTrigger(ctx, "test/event")
+	require.NoError(t, err)
+	require.Len(t, fns, 1)
+	require.Equal(t, f.ID, fns[0].ID)
+}
+
+func TestRollbackFunction(t *testing.T) {
+	ctx := context.Background()
+	rw, err := New(ctx)
+	require.NoError(t, err)
+
+	f := function.Function{
+		Name: "test",
+		ID:   "test-fn",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/v1"}},
+		},
+		Steps: map[string]function.Step{
+			"step-1": {
+				ID:   "step-1",
+				Name: "Step #1",
+				Runtime: inngest.RuntimeWrapper{
+					Runtime: inngest.RuntimeDocker{},
+				},
+			},
+		},
+	}
+	_, err = rw.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	f.Triggers = []function.Trigger{{EventTrigger: &function.EventTrigger{Event: "test/v2"}}}
+	_, err = rw.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	_, err = rw.RollbackFunction(ctx, f.ID, 3)
+	require.ErrorIs(t, err, coredata.ErrFunctionVersionNotFound)
+	_, err = rw.RollbackFunction(ctx, "missing", 1)
+	require.ErrorIs(t, err, coredata.ErrFunctionVersionNotFound)
+
+	fv, err := rw.RollbackFunction(ctx, f.ID, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, fv.Version)
+	require.NotNil(t, fv.ValidFrom)
+	require.Nil(t, fv.ValidTo)
+
+	versions, err := rw.FunctionVersions(ctx, f.ID)
+	require.NoError(t, err)
+	require.NotNil(t, versions[0].ValidTo)
+	require.Nil(t, versions[1].ValidTo)
+
+	// The rolled back version is executed.
+	fns, err := rw.FunctionsByTrigger(ctx, "test/v1")
+	require.NoError(t, err)
+	require.Len(t, fns, 1)
+	fns, err = rw.FunctionsByTrigger(ctx, "test/v2")
+	require.NoError(t, err)
+	require.Len(t, fns, 0)
+
+	// Rolling back to the live version is a no-op.
+	again, err := rw.RollbackFunction(ctx, f.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, fv, again)
+}
+
+func TestSetFunctions_graph(t *testing.T) {
+	ctx := context.Background()
+
+	step := func(id, after string) function.Step {
+		return function.Step{
+			ID:      id,
+			Name:    id,
+			Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+			After:   []function.After{{Step: after}},
+		}
+	}
+	f := &function.Function{
+		Name: "test",
+		ID:   "test-fn",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/event"}},
+		},
+		Steps: map[string]function.Step{
+			"a": step("a", inngest.TriggerName),
+			"b": step("b", "c"),
+			"c": step("c", "b"),
+		},
+	}
+	require.Error(t, f.ValidateGraph(ctx))
+
+	// Graph analysis only runs when deploying, so that functions which
+	// were previously deployed continue to load.
+	l := &MemoryExecutionLoader{}
+	require.NoError(t, l.SetFunctions(ctx, []*function.Function{f}))
+	fns, err := l.Functions(ctx)
+	require.NoError(t, err)
+	require.Len(t, fns, 1)
+}
+
+func TestCreateFunctionVersion_invalid(t *testing.T) {
+	ctx := context.Background()
+	rw, err := New(ctx)
+	require.NoError(t, err)
+
+	f := function.Function{
+		Name: "test",
+		ID:   "test-fn",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/event"}},
+		},
+		Steps: map[string]function.Step{
+			"step-1": {
+				ID:   "step-1",
+				Name: "Step #1",
+				Runtime: inngest.RuntimeWrapper{
+					Runtime: inngest.RuntimeDocker{},
+				},
+			},
+		},
+	}
+	_, err = rw.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	// Functions which can't be loaded leave the live version unchanged.
+	invalid := f
+	invalid.Triggers = nil
+	_, err = rw.CreateFunctionVersion(ctx, invalid, true, "prod")
+	require.Error(t, err)
+
+	live := func() {
+		t.Helper()
+		versions, err := rw.FunctionVersions(ctx, f.ID)
+		require.NoError(t, err)
+		// Versions are returned newest first.
+		require.Len(t, versions, 2)
+		require.Nil(t, versions[0].ValidFrom)
+		require.NotNil(t, versions[1].ValidFrom)
+		require.Nil(t, versions[1].ValidTo)
+
+		fns, err := rw.FunctionsByTrigger(ctx, "test/event")
+		require.NoError(t, err)
+		require.Len(t, fns, 1)
+	}
+	_, err = rw.CreateFunctionVersion(ctx, invalid, false, "prod")
+	require.NoError(t, err)
+	live()
+
+	_, err = rw.RollbackFunction(ctx, f.ID, 2)
+	require.Error(t, err)
+	live()
+
+	// New functions which can't be loaded aren't stored.
+	invalid.ID = "new-fn"
+	_, err = rw.CreateFunctionVersion(ctx, invalid, true, "prod")
+	require.Error(t, err)
+	versions, err := rw.FunctionVersions(ctx, invalid.ID)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
@@ -0,0 +1,72 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/inngest/inngest/pkg/coredata"
+)
+
+// MemorySourceKeyStore is an in-memory coredata.SourceKeyStore, for development
+// and testing only.
+type MemorySourceKeyStore struct {
+	lock sync.RWMutex
+	keys map[string]coredata.SourceKey
+}
+
+func NewInMemorySourceKeyStore() *MemorySourceKeyStore {
+	return &MemorySourceKeyStore{
+		keys: map[string]coredata.SourceKey{},
+	}
+}
+
+func (m *MemorySourceKeyStore) SourceKey(ctx context.Context, key string) (*coredata.SourceKey, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	k, ok := m.keys[key]
+	if !ok {
+		return nil, coredata.ErrSourceKeyNotFound
+	}
+	return &k, nil
+}
+
+func (m *MemorySourceKeyStore) SourceKeyPrefixes(ctx context.Context) ([]string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	seen := map[string]struct{}{}
+	prefixes := []string{}
+	for _, k := range m.keys {
+		for _, p := range k.EventPrefixes {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			prefixes = append(prefixes, p)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes, nil
+}
+
+func (m *MemorySourceKeyStore) HasSourceKeys(ctx context.Context) (bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return len(m.keys) > 0, nil
+}
+
+func (m *MemorySourceKeyStore) CreateSourceKey(ctx context.Context, k coredata.SourceKey) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.keys[k.Key] = k
+	return nil
+}
+
+func (m *MemorySourceKeyStore) DeleteSourceKey(ctx context.Context, key string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.keys, key)
+	return nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/inngest/inngest/pkg/coredata"
+)
+
+var (
+	// deliveries
+	sqlInsertDelivery string = `
+		INSERT INTO deliveries (delivery_id, subscription_id, url, event_id, event_name, attempt, status_code, error, at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (delivery_id, attempt) DO NOTHING`
+	sqlSelectDeliveries string = `
+		SELECT delivery_id, subscription_id, url, event_id, event_name, attempt, status_code, error, at
+		FROM deliveries`
+)
+
+func (rw *ReadWriter) SaveDelivery(ctx context.Context, d coredata.Delivery) error {
+	_, err := rw.db.ExecContext(
+		ctx,
+		sqlInsertDelivery,
+		d.ID,
+		d.SubscriptionID,
+		d.URL,
+		d.EventID,
+		d.EventName,
+		d.Attempt,
+		d.StatusCode,
+		d.Error,
+		d.At,
+	)
+	return err
+}
+
+func (rw *ReadWriter) Deliveries(ctx context.Context, q coredata.DeliveryQuery) ([]coredata.Delivery, error) {
+	where := []string{}
+	args := []interface{}{}
+	filter := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+
+	if q.SubscriptionID != nil {
+		filter("subscription_id = $%d", *q.SubscriptionID)
+	}
+	if q.EventID != nil {
+		filter("event_id = $%d", *q.EventID)
+	}
+
+	query := sqlSelectDeliveries
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, q.Size())
+	query += fmt.Sprintf(" ORDER BY at DESC, attempt DESC LIMIT $%d", len(args))
+
+	rows, err := rw.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []coredata.Delivery{}
+	for rows.Next() {
+		d := coredata.Delivery{}
+		err := rows.Scan(
+			&d.ID,
+			&d.SubscriptionID,
+			&d.URL,
+			&d.EventID,
+			&d.EventName,
+			&d.Attempt,
+			&d.StatusCode,
+			&d.Error,
+			&d.At,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
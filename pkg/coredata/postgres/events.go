@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+)
+
+var (
+	// events
+	sqlInsertEvent string = `
+		INSERT INTO events (event_id, name, event, received_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id) DO NOTHING`
+	sqlSelectEvents string = `
+		SELECT event_id, event, received_at
+		FROM events`
+
+	// event_runs
+	sqlInsertEventRun string = `
+		INSERT INTO event_runs (event_id, function_id, workflow_id, run_id, key)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id, run_id) DO NOTHING`
+	sqlFindEventRuns string = `
+		SELECT event_id, function_id, workflow_id, run_id, key
+		FROM event_runs
+		WHERE event_id = ANY($1)
+		ORDER BY created_at ASC`
+	sqlSelectRuns string = `
+		SELECT function_id, workflow_id, run_id, key
+		FROM event_runs`
+)
+
+// SaveEvent stores the event and its runs within a single transaction.
+func (rw *ReadWriter) SaveEvent(ctx context.Context, e coredata.Event) error {
+	byt, err := json.Marshal(e.Event)
+	if err != nil {
+		return err
+	}
+
+	tx, err := rw.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, sqlInsertEvent, e.ID, e.Name, byt, e.ReceivedAt); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	for _, r := range e.Runs {
+		_, err := tx.ExecContext(
+			ctx,
+			sqlInsertEventRun,
+			e.ID,
+			r.FunctionID,
+			r.Identifier.WorkflowID,
+			r.Identifier.RunID.String(),
+			r.Identifier.Key,
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (rw *ReadWriter) Event(ctx context.Context, id string) (*coredata.Event, error) {
+	events, err := rw.Events(ctx, coredata.EventQuery{ID: &id, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, coredata.ErrEventNotFound
+	}
+	return &events[0], nil
+}
+
+func (rw *ReadWriter) Events(ctx context.Context, q coredata.EventQuery) ([]coredata.Event, error) {
+	where := []string{}
+	args := []interface{}{}
+	filter := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+
+	if q.ID != nil {
+		filter("event_id = $%d", *q.ID)
+	}
+	if q.Name != nil {
+		filter("name = $%d", *q.Name)
+	}
+	if q.From != nil {
+		filter("received_at >= $%d", *q.From)
+	}
+	if q.To != nil {
+		filter("received_at < $%d", *q.To)
+	}
+
+	query := sqlSelectEvents
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, q.Size())
+	query += fmt.Sprintf(" ORDER BY received_at DESC, event_id DESC LIMIT $%d", len(args))
+
+	rows, err := rw.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []coredata.Event{}
+	ids := []string{}
+	for rows.Next() {
+		var (
+			e   coredata.Event
+			raw []byte
+		)
+		if err := rows.Scan(&e.ID, &raw, &e.ReceivedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &e.Event); err != nil {
+			return nil, err
+		}
+		e.Name = e.Event.Name
+		e.Runs = []coredata.EventRun{}
+		events = append(events, e)
+		ids = append(ids, e.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	runs, err := rw.eventRuns(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for n, e := range events {
+		events[n].Runs = append(events[n].Runs, runs[e.ID]...)
+	}
+
+	return events, nil
+}
+
+// eventRuns returns all runs for the given event IDs, keyed by event ID.
+func (rw *ReadWriter) eventRuns(ctx context.Context, ids []string) (map[string][]coredata.EventRun, error) {
+	rows, err := rw.db.QueryContext(ctx, sqlFindEventRuns, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := map[string][]coredata.EventRun{}
+	for rows.Next() {
+		var (
+			eventID string
+			runID   string
+			run     coredata.EventRun
+		)
+		err := rows.Scan(&eventID, &run.FunctionID, &run.Identifier.WorkflowID, &runID, &run.Identifier.Key)
+		if err != nil {
+			return nil, err
+		}
+		if run.Identifier.RunID, err = ulid.Parse(runID); err != nil {
+			return nil, err
+		}
+		runs[eventID] = append(runs[eventID], run)
+	}
+	return runs, rows.Err()
+}
+
+// Runs returns all runs matching the given query.  Run IDs are ULIDs, so
+// ordering by ID orders runs by the time they were created.
+func (rw *ReadWriter) Runs(ctx context.Context, q coredata.RunQuery) ([]coredata.EventRun, error) {
+	where := []string{}
+	args := []interface{}{}
+	filter := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+
+	if q.FunctionID != nil {
+		filter("function_id = $%d", *q.FunctionID)
+	}
+	if q.RunID != nil {
+		filter("run_id = $%d", q.RunID.String())
+	}
+	if q.Before != nil {
+		filter("run_id < $%d", q.Before.String())
+	}
+
+	query := sqlSelectRuns
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, q.Size())
+	query += fmt.Sprintf(" ORDER BY run_id DESC LIMIT $%d", len(args))
+
+	rows, err := rw.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []coredata.EventRun{}
+	for rows.Next() {
+		var (
+			runID string
+			run   coredata.EventRun
+		)
+		err := rows.Scan(&run.FunctionID, &run.Identifier.WorkflowID, &runID, &run.Identifier.Key)
+		if err != nil {
+			return nil, err
+		}
+		if run.Identifier.RunID, err = ulid.Parse(runID); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
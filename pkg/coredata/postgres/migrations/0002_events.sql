@@ -0,0 +1,38 @@
+-- +goose Up
+
+-- events stores every event received by the system.
+CREATE TABLE public.events (
+  event_id character varying(64) NOT NULL,
+  name character varying(255) NOT NULL,
+  -- the full event payload
+  event jsonb NOT NULL,
+  received_at timestamp without time zone NOT NULL,
+  created_at timestamp without time zone DEFAULT now() NOT NULL,
+  PRIMARY KEY (event_id)
+);
+
+CREATE INDEX events_received_at ON public.events USING btree (received_at DESC);
+CREATE INDEX events_name_received_at ON public.events USING btree (name, received_at DESC);
+
+-- event_runs stores the function runs that each event initialized.
+CREATE TABLE public.event_runs (
+  event_id character varying(64) NOT NULL,
+  function_id character varying(255) NOT NULL,
+  workflow_id uuid NOT NULL,
+  run_id character(26) NOT NULL,
+  -- the idempotency key for the run
+  key character varying(255) NOT NULL,
+  created_at timestamp without time zone DEFAULT now() NOT NULL,
+  PRIMARY KEY (event_id, run_id)
+);
+
+ALTER TABLE ONLY public.event_runs
+  ADD CONSTRAINT event_runs_event_id FOREIGN KEY (event_id) REFERENCES public.events(event_id) ON DELETE CASCADE;
+
+CREATE INDEX event_runs_function_id ON public.event_runs USING btree (function_id, created_at DESC);
+CREATE INDEX event_runs_run_id ON public.event_runs USING btree (run_id);
+
+
+-- +goose Down
+DROP TABLE public.event_runs;
+DROP TABLE public.events;
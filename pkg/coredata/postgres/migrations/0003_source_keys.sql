@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- source_keys stores keys used to send events to the event API.  Keys are
+-- stored as a sha256 hash.
+CREATE TABLE public.source_keys (
+  key_hash character(64) NOT NULL,
+  name character varying(255) NOT NULL,
+  -- event_prefixes optionally restricts the events sent with this key
+  event_prefixes text[] NOT NULL DEFAULT '{}',
+  created_at timestamp without time zone DEFAULT now() NOT NULL,
+  PRIMARY KEY (key_hash)
+);
+
+
+-- +goose Down
+DROP TABLE public.source_keys;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- deliveries logs each attempt to deliver an event to an outbound webhook
+-- subscription.
+CREATE TABLE public.deliveries (
+  delivery_id character varying(255) NOT NULL,
+  subscription_id character varying(255) NOT NULL,
+  url text NOT NULL,
+  event_id character varying(255) NOT NULL,
+  event_name character varying(255) NOT NULL,
+  attempt integer NOT NULL,
+  status_code integer NOT NULL,
+  error text NOT NULL DEFAULT '',
+  at timestamp without time zone NOT NULL,
+  PRIMARY KEY (delivery_id, attempt)
+);
+
+CREATE INDEX deliveries_subscription_at ON public.deliveries (subscription_id, at);
+CREATE INDEX deliveries_event_id ON public.deliveries (event_id);
+
+
+-- +goose Down
+DROP TABLE public.deliveries;
@@ -117,6 +117,11 @@ var (
 		FROM function_triggers ft
 		JOIN function_versions fv on fv.function_id = ft.function_id and fv.version = ft.version
 		WHERE ft.event_name = $1 and fv.valid_from is not null and fv.valid_to is null;`
+	sqlFindFunctionVersions string = `
+		SELECT function_id, version, config, valid_from, valid_to, created_at, updated_at
+		FROM function_versions
+		WHERE function_id = $1
+		ORDER BY version DESC`
 	sqlFindLatestFunctionVersion string = `
 		SELECT f.function_id, COALESCE(version,0)
 		FROM functions f
@@ -128,9 +133,22 @@ var (
 		INSERT INTO function_versions (function_id, version, config, valid_from)
 		VALUES ($1, $2, $3, $4)
 		RETURNING created_at, updated_at`
-	sqlUpdateFunctionVersionValidTo string = `
+	sqlLockFunction string = `
+		SELECT function_id
+		FROM functions
+		WHERE function_id = $1
+		FOR UPDATE`
+	sqlFindFunctionVersion string = `
+		SELECT function_id, version, config, valid_from, valid_to, created_at, updated_at
+		FROM function_versions
+		WHERE function_id = $1 and version = $2`
+	sqlExpireLiveFunctionVersions string = `
+		UPDATE function_versions
+		SET valid_to = $2, updated_at = $2
+		WHERE function_id = $1 and valid_from is not null and valid_to is null`
+	sqlSetLiveFunctionVersion string = `
 		UPDATE function_versions
-		SET valid_to = $3
+		SET valid_from = $3, valid_to = null, updated_at = $3
 		WHERE function_id = $1 and version = $2`
 
 	// function_triggers
@@ -145,11 +163,30 @@ var (
 // CreateFunctionVersion creates the function, ensures function_triggers are up to date,
 // and creates a new function version, setting any prior version no longer valid.
 func (rw *ReadWriter) CreateFunctionVersion(ctx context.Context, f function.Function, live bool, env string) (function.FunctionVersion, error) {
-	var existingFunctionID string
-	var existingVersion int
 	now := time.Now()
 
-	err := rw.db.QueryRowContext(ctx, sqlFindLatestFunctionVersion, f.ID).
+	tx, err := rw.db.BeginTx(ctx, nil)
+	if err != nil {
+		return function.FunctionVersion{}, err
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the function so that concurrent deploys and rollbacks wait for
+	// this transaction, creating the function if it doesn't yet exist.
+	var id string
+	err = tx.QueryRowContext(ctx, sqlLockFunction, f.ID).Scan(&id)
+	if err == sql.ErrNoRows {
+		if _, err := tx.ExecContext(ctx, sqlInsertFunction, f.ID, f.Name); err != nil {
+			return function.FunctionVersion{}, err
+		}
+	} else if err != nil {
+		return function.FunctionVersion{}, err
+	}
+
+	var existingFunctionID string
+	var existingVersion int
+	err = tx.QueryRowContext(ctx, sqlFindLatestFunctionVersion, f.ID).
 		Scan(&existingFunctionID, &existingVersion)
 	if err != nil && err != sql.ErrNoRows {
 		return function.FunctionVersion{}, err
@@ -160,25 +197,13 @@ func (rw *ReadWriter) CreateFunctionVersion(ctx context.Context, f function.Func
 
 	// TODO - Diff the existing function vs. the new function and only add new version if it has changed
 
-	tx, err := rw.db.BeginTx(ctx, nil)
-	if err != nil {
-		return function.FunctionVersion{}, err
-	}
-
-	// We confirm there is no existing row in the functions table before creating one
-	if existingFunctionID == "" {
-		_, err := tx.ExecContext(ctx, sqlInsertFunction, f.ID, f.Name)
-		if err != nil {
-			return function.FunctionVersion{}, err
-		}
-	}
-
-	// For live functions, we must make the previous version as no longer valid
+	// For live functions, we must mark every previously live version as no
+	// longer valid.  After a rollback the live version isn't the latest, so
+	// we can't only expire the latest version.
 	// NOTE - We currently have no "draft" functions in the open source Inngest, this is for future draft functionality
 	// Every new function version deployed is assumed to be live
-	if live && existingVersion != 0 {
-		_, err := tx.ExecContext(ctx, sqlUpdateFunctionVersionValidTo, f.ID, existingVersion, now)
-		if err != nil {
+	if live {
+		if _, err := tx.ExecContext(ctx, sqlExpireLiveFunctionVersions, f.ID, now); err != nil {
 			return function.FunctionVersion{}, err
 		}
 	}
@@ -218,9 +243,6 @@ func (rw *ReadWriter) CreateFunctionVersion(ctx context.Context, f function.Func
 	}
 
 	if err = tx.Commit(); err != nil {
-		if err = tx.Rollback(); err != nil {
-			return function.FunctionVersion{}, err
-		}
 		return function.FunctionVersion{}, err
 	}
 
@@ -259,6 +281,91 @@ func (rw *ReadWriter) Functions(ctx context.Context) ([]function.Function, error
 	defer rows.Close()
 	return rowsToFunctions(ctx, rows)
 }
+
+// FunctionVersions returns every version of the given function, newest first.
+func (rw *ReadWriter) FunctionVersions(ctx context.Context, functionID string) ([]function.FunctionVersion, error) {
+	rows, err := rw.db.QueryContext(ctx, sqlFindFunctionVersions, functionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []function.FunctionVersion{}
+	for rows.Next() {
+		fv := function.FunctionVersion{}
+		err := rows.Scan(&fv.FunctionID, &fv.Version, &fv.Config, &fv.ValidFrom, &fv.ValidTo, &fv.CreatedAt, &fv.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		fn, err := function.Unmarshal(ctx, []byte(fv.Config), "")
+		if err != nil {
+			return nil, err
+		}
+		fv.Function = *fn
+		versions = append(versions, fv)
+	}
+	return versions, rows.Err()
+}
+
+// RollbackFunction makes the given prior version of a function live within a single
+// transaction, so that exactly one version of the function is live at any time.
+func (rw *ReadWriter) RollbackFunction(ctx context.Context, functionID string, version uint) (function.FunctionVersion, error) {
+	tx, err := rw.db.BeginTx(ctx, nil)
+	if err != nil {
+		return function.FunctionVersion{}, err
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the function so that concurrent deploys and rollbacks wait for
+	// this transaction.
+	var id string
+	err = tx.QueryRowContext(ctx, sqlLockFunction, functionID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return function.FunctionVersion{}, coredata.ErrFunctionVersionNotFound
+	}
+	if err != nil {
+		return function.FunctionVersion{}, err
+	}
+
+	fv := function.FunctionVersion{}
+	err = tx.QueryRowContext(ctx, sqlFindFunctionVersion, functionID, version).
+		Scan(&fv.FunctionID, &fv.Version, &fv.Config, &fv.ValidFrom, &fv.ValidTo, &fv.CreatedAt, &fv.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return function.FunctionVersion{}, coredata.ErrFunctionVersionNotFound
+	}
+	if err != nil {
+		return function.FunctionVersion{}, err
+	}
+
+	fn, err := function.Unmarshal(ctx, []byte(fv.Config), "")
+	if err != nil {
+		return function.FunctionVersion{}, err
+	}
+	fv.Function = *fn
+
+	if fv.ValidFrom != nil && fv.ValidTo == nil {
+		// This version is already live.
+		return fv, nil
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, sqlExpireLiveFunctionVersions, functionID, now); err != nil {
+		return function.FunctionVersion{}, err
+	}
+	if _, err := tx.ExecContext(ctx, sqlSetLiveFunctionVersion, functionID, version, now); err != nil {
+		return function.FunctionVersion{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return function.FunctionVersion{}, err
+	}
+
+	fv.ValidFrom = &now
+	fv.ValidTo = nil
+	fv.UpdatedAt = now
+	return fv, nil
+}
+
 func (rw *ReadWriter) FunctionsScheduled(ctx context.Context) ([]function.Function, error) {
 	rows, err := rw.db.QueryContext(ctx, sqlFindAllLiveScheduledFunctions)
 	if err != nil {
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"os"
@@ -10,8 +11,13 @@ import (
 	"time"
 
 	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/google/uuid"
 	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/state"
 	"github.com/inngest/inngest/pkg/function"
+	"github.com/oklog/ulid/v2"
 	"github.com/pressly/goose/v3"
 	"github.com/stretchr/testify/require"
 	pg "gocloud.dev/postgres"
@@ -661,3 +667,278 @@ func TestFunctionsByTrigger(t *testing.T) {
 	require.NotContains(t, functionIds, fn3Id)
 	require.NotContains(t, functionIds, fn4Id)
 }
+
+func TestFunctionVersions(t *testing.T) {
+	ctx := context.Background()
+	functionId := "prefix/function-read-versions-test1"
+	f := createFunctionWithTriggers(functionId, []function.Trigger{
+		{EventTrigger: &function.EventTrigger{Event: "test.event"}},
+	})
+
+	_, err := globalPGRW.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+	_, err = globalPGRW.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	versions, err := globalPGRW.FunctionVersions(ctx, functionId)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	// Versions are returned newest first, with only the latest version live.
+	require.Equal(t, uint(2), versions[0].Version)
+	require.NotNil(t, versions[0].ValidFrom)
+	require.Nil(t, versions[0].ValidTo)
+	require.Equal(t, uint(1), versions[1].Version)
+	require.NotNil(t, versions[1].ValidTo)
+	require.Equal(t, functionId, versions[1].Function.ID)
+
+	versions, err = globalPGRW.FunctionVersions(ctx, "prefix/function-read-versions-missing")
+	require.NoError(t, err)
+	require.Len(t, versions, 0)
+}
+
+func TestRollbackFunction(t *testing.T) {
+	ctx := context.Background()
+	functionId := "prefix/function-rollback-test1"
+	f := createFunctionWithTriggers(functionId, []function.Trigger{
+		{EventTrigger: &function.EventTrigger{Event: "test.rollback.v1"}},
+	})
+	_, err := globalPGRW.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	f.Triggers = []function.Trigger{
+		{EventTrigger: &function.EventTrigger{Event: "test.rollback.v2"}},
+	}
+	_, err = globalPGRW.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	_, err = globalPGRW.RollbackFunction(ctx, functionId, 3)
+	require.ErrorIs(t, err, coredata.ErrFunctionVersionNotFound)
+	_, err = globalPGRW.RollbackFunction(ctx, "prefix/function-rollback-missing", 1)
+	require.ErrorIs(t, err, coredata.ErrFunctionVersionNotFound)
+
+	fv, err := globalPGRW.RollbackFunction(ctx, functionId, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint(1), fv.Version)
+	require.NotNil(t, fv.ValidFrom)
+	require.Nil(t, fv.ValidTo)
+
+	versions, err := globalPGRW.FunctionVersions(ctx, functionId)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.NotNil(t, versions[0].ValidTo)
+	require.NotNil(t, versions[1].ValidFrom)
+	require.Nil(t, versions[1].ValidTo)
+
+	// The rolled back version's triggers are live.
+	fns, err := globalPGRW.FunctionsByTrigger(ctx, "test.rollback.v1")
+	require.NoError(t, err)
+	require.Len(t, fns, 1)
+	fns, err = globalPGRW.FunctionsByTrigger(ctx, "test.rollback.v2")
+	require.NoError(t, err)
+	require.Len(t, fns, 0)
+}
+
+func TestCreateFunctionVersion_after_rollback(t *testing.T) {
+	ctx := context.Background()
+	functionId := "prefix/function-rollback-deploy-test1"
+	f := createFunctionWithTriggers(functionId, []function.Trigger{
+		{EventTrigger: &function.EventTrigger{Event: "test.rollback.deploy.v1"}},
+	})
+	_, err := globalPGRW.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+	_, err = globalPGRW.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+
+	_, err = globalPGRW.RollbackFunction(ctx, functionId, 1)
+	require.NoError(t, err)
+
+	// Deploying after a rollback expires the rolled back version, not only
+	// the latest version.
+	f.Triggers = []function.Trigger{
+		{EventTrigger: &function.EventTrigger{Event: "test.rollback.deploy.v3"}},
+	}
+	fv, err := globalPGRW.CreateFunctionVersion(ctx, f, true, "prod")
+	require.NoError(t, err)
+	require.Equal(t, uint(3), fv.Version)
+
+	versions, err := globalPGRW.FunctionVersions(ctx, functionId)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	live := 0
+	for _, v := range versions {
+		if v.ValidFrom != nil && v.ValidTo == nil {
+			live++
+			require.Equal(t, uint(3), v.Version)
+		}
+	}
+	require.Equal(t, 1, live)
+
+	fns, err := globalPGRW.FunctionsByTrigger(ctx, "test.rollback.deploy.v1")
+	require.NoError(t, err)
+	require.Len(t, fns, 0)
+	fns, err = globalPGRW.FunctionsByTrigger(ctx, "test.rollback.deploy.v3")
+	require.NoError(t, err)
+	require.Len(t, fns, 1)
+}
+
+func TestSaveEvent(t *testing.T) {
+	ctx := context.Background()
+	name := "test/postgres.save.event"
+	id := ulid.MustNew(ulid.Now(), rand.Reader).String()
+
+	run := coredata.EventRun{
+		FunctionID: "test-save-event-fn",
+		Identifier: state.Identifier{
+			WorkflowID: uuid.New(),
+			RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+			Key:        id,
+		},
+	}
+	evt := coredata.Event{
+		ID:         id,
+		Name:       name,
+		Event:      event.Event{ID: id, Name: name, Data: map[string]interface{}{"ok": true}},
+		ReceivedAt: time.Now().UTC().Truncate(time.Millisecond),
+		Runs:       []coredata.EventRun{run},
+	}
+
+	require.NoError(t, globalPGRW.SaveEvent(ctx, evt))
+	// Saving the event twice should not duplicate runs.
+	require.NoError(t, globalPGRW.SaveEvent(ctx, evt))
+
+	found, err := globalPGRW.Event(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, name, found.Name)
+	require.Equal(t, true, found.Event.Data["ok"])
+	require.Equal(t, []coredata.EventRun{run}, found.Runs)
+
+	events, err := globalPGRW.Events(ctx, coredata.EventQuery{Name: &name})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	from := evt.ReceivedAt.Add(time.Second)
+	events, err = globalPGRW.Events(ctx, coredata.EventQuery{Name: &name, From: &from})
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+
+	_, err = globalPGRW.Event(ctx, "missing")
+	require.ErrorIs(t, err, coredata.ErrEventNotFound)
+}
+
+func TestRuns(t *testing.T) {
+	ctx := context.Background()
+	fnID := "test-runs-fn"
+
+	now := time.Now()
+	newRun := func(fnID string, at time.Time) coredata.EventRun {
+		return coredata.EventRun{
+			FunctionID: fnID,
+			Identifier: state.Identifier{
+				WorkflowID: uuid.New(),
+				RunID:      ulid.MustNew(ulid.Timestamp(at), rand.Reader),
+			},
+		}
+	}
+	a := newRun(fnID, now.Add(-time.Minute))
+	b := newRun(fnID, now)
+	other := newRun("test-runs-other-fn", now)
+
+	id := ulid.MustNew(ulid.Now(), rand.Reader).String()
+	err := globalPGRW.SaveEvent(ctx, coredata.Event{
+		ID:         id,
+		Name:       "test/postgres.runs",
+		Event:      event.Event{ID: id, Name: "test/postgres.runs"},
+		ReceivedAt: time.Now().UTC(),
+		Runs:       []coredata.EventRun{a, b, other},
+	})
+	require.NoError(t, err)
+
+	// Runs are returned newest first.
+	runs, err := globalPGRW.Runs(ctx, coredata.RunQuery{FunctionID: &fnID})
+	require.NoError(t, err)
+	require.Equal(t, []coredata.EventRun{b, a}, runs)
+
+	runs, err = globalPGRW.Runs(ctx, coredata.RunQuery{RunID: &other.Identifier.RunID})
+	require.NoError(t, err)
+	require.Equal(t, []coredata.EventRun{other}, runs)
+
+	runs, err = globalPGRW.Runs(ctx, coredata.RunQuery{FunctionID: &fnID, Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, []coredata.EventRun{b}, runs)
+
+	runs, err = globalPGRW.Runs(ctx, coredata.RunQuery{FunctionID: &fnID, Before: &b.Identifier.RunID})
+	require.NoError(t, err)
+	require.Equal(t, []coredata.EventRun{a}, runs)
+}
+
+func TestSourceKeys(t *testing.T) {
+	ctx := context.Background()
+	key := coredata.SourceKey{
+		Key:           "test-source-key",
+		Name:          "billing",
+		EventPrefixes: []string{"billing/"},
+	}
+
+	_, err := globalPGRW.SourceKey(ctx, key.Key)
+	require.ErrorIs(t, err, coredata.ErrSourceKeyNotFound)
+
+	require.NoError(t, globalPGRW.CreateSourceKey(ctx, key))
+	exists, err := globalPGRW.HasSourceKeys(ctx)
+	require.NoError(t, err)
+	require.True(t, exists)
+	found, err := globalPGRW.SourceKey(ctx, key.Key)
+	require.NoError(t, err)
+	require.Equal(t, key, *found)
+
+	prefixes, err := globalPGRW.SourceKeyPrefixes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"billing/"}, prefixes)
+
+	// Keys must never be stored in plaintext.
+	var n int
+	err = globalDB.QueryRowContext(ctx, "SELECT count(*) FROM source_keys WHERE key_hash = $1", key.Key).Scan(&n)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	require.NoError(t, globalPGRW.DeleteSourceKey(ctx, key.Key))
+	_, err = globalPGRW.SourceKey(ctx, key.Key)
+	require.ErrorIs(t, err, coredata.ErrSourceKeyNotFound)
+}
+
+func TestDeliveries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	first := coredata.Delivery{
+		ID:             "delivery-1",
+		SubscriptionID: "billing",
+		URL:            "https://example.com/hook",
+		EventID:        "evt-1",
+		EventName:      "billing/paid",
+		Attempt:        1,
+		StatusCode:     500,
+		Error:          "unexpected status code: 500",
+		At:             now,
+	}
+	second := first
+	second.Attempt = 2
+	second.StatusCode = 200
+	second.Error = ""
+	second.At = now.Add(time.Second)
+
+	require.NoError(t, globalPGRW.SaveDelivery(ctx, first))
+	require.NoError(t, globalPGRW.SaveDelivery(ctx, second))
+	// Saving the same attempt twice is a no-op.
+	require.NoError(t, globalPGRW.SaveDelivery(ctx, second))
+
+	sub := "billing"
+	found, err := globalPGRW.Deliveries(ctx, coredata.DeliveryQuery{SubscriptionID: &sub})
+	require.NoError(t, err)
+	require.Equal(t, []coredata.Delivery{second, first}, found)
+
+	other := "other"
+	found, err = globalPGRW.Deliveries(ctx, coredata.DeliveryQuery{EventID: &other})
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/lib/pq"
+)
+
+var (
+	// source_keys
+	sqlFindSourceKey string = `
+		SELECT name, event_prefixes
+		FROM source_keys
+		WHERE key_hash = $1`
+	sqlFindSourceKeyPrefixes string = `
+		SELECT DISTINCT unnest(event_prefixes) AS prefix
+		FROM source_keys
+		ORDER BY prefix`
+	sqlHasSourceKeys string = `
+		SELECT EXISTS (SELECT 1 FROM source_keys)`
+	sqlInsertSourceKey string = `
+		INSERT INTO source_keys (key_hash, name, event_prefixes)
+		VALUES ($1, $2, $3)`
+	sqlDeleteSourceKey string = `
+		DELETE FROM source_keys
+		WHERE key_hash = $1`
+)
+
+// hashKey hashes source keys, ensuring that keys are never stored in plaintext.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (rw *ReadWriter) SourceKey(ctx context.Context, key string) (*coredata.SourceKey, error) {
+	k := &coredata.SourceKey{Key: key}
+	prefixes := pq.StringArray{}
+	err := rw.db.QueryRowContext(ctx, sqlFindSourceKey, hashKey(key)).Scan(&k.Name, &prefixes)
+	if err == sql.ErrNoRows {
+		return nil, coredata.ErrSourceKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(prefixes) > 0 {
+		k.EventPrefixes = []string(prefixes)
+	}
+	return k, nil
+}
+
+func (rw *ReadWriter) SourceKeyPrefixes(ctx context.Context) ([]string, error) {
+	rows, err := rw.db.QueryContext(ctx, sqlFindSourceKeyPrefixes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefixes := []string{}
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, rows.Err()
+}
+
+func (rw *ReadWriter) HasSourceKeys(ctx context.Context) (bool, error) {
+	var exists bool
+	err := rw.db.QueryRowContext(ctx, sqlHasSourceKeys).Scan(&exists)
+	return exists, err
+}
+
+func (rw *ReadWriter) CreateSourceKey(ctx context.Context, k coredata.SourceKey) error {
+	prefixes := k.EventPrefixes
+	if prefixes == nil {
+		prefixes = []string{}
+	}
+	_, err := rw.db.ExecContext(ctx, sqlInsertSourceKey, hashKey(k.Key), k.Name, pq.StringArray(prefixes))
+	return err
+}
+
+func (rw *ReadWriter) DeleteSourceKey(ctx context.Context, key string) error {
+	_, err := rw.db.ExecContext(ctx, sqlDeleteSourceKey, hashKey(key))
+	return err
+}
@@ -0,0 +1,59 @@
+package coredata
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrSourceKeyNotFound = errors.New("source key not found")
+
+// SourceKeyStore stores keys used to send events to the event API.
+type SourceKeyStore interface {
+	SourceKeyReader
+	SourceKeyWriter
+}
+
+type SourceKeyReader interface {
+	// SourceKey returns the source key for the given key, or ErrSourceKeyNotFound.
+	SourceKey(ctx context.Context, key string) (*SourceKey, error)
+	// SourceKeyPrefixes returns the event prefixes of every source key which
+	// restricts the events it may send.
+	SourceKeyPrefixes(ctx context.Context) ([]string, error)
+	// HasSourceKeys returns whether any source keys are stored.
+	HasSourceKeys(ctx context.Context) (bool, error)
+}
+
+type SourceKeyWriter interface {
+	// CreateSourceKey stores a new source key.
+	CreateSourceKey(ctx context.Context, k SourceKey) error
+	// DeleteSourceKey deletes the given source key, revoking access.
+	DeleteSourceKey(ctx context.Context, key string) error
+}
+
+// SourceKey is a key used to send events to the event API.  Each source key
+// represents a single source of events, eg. a backend service or webhook.
+type SourceKey struct {
+	// Key is the secret key used within the event API URL.
+	Key string `json:"key"`
+	// Name is the name of the source.  This is attached to each event
+	// sent with the key.
+	Name string `json:"name"`
+	// EventPrefixes optionally restricts the events that may be sent
+	// with the key to events whose names start with one of the given
+	// prefixes.  If empty, any event may be sent.
+	EventPrefixes []string `json:"eventPrefixes,omitempty"`
+}
+
+// Allows returns whether the key can be used to send the given event.
+func (k SourceKey) Allows(eventName string) bool {
+	if len(k.EventPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range k.EventPrefixes {
+		if strings.HasPrefix(eventName, prefix) {
+			return true
+		}
+	}
+	return false
+}
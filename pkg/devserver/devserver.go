@@ -3,7 +3,6 @@ package devserver
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/inngest/inngest/pkg/api"
 	"github.com/inngest/inngest/pkg/cli"
@@ -15,13 +14,23 @@ import (
 	"github.com/inngest/inngest/pkg/execution/runner"
 	"github.com/inngest/inngest/pkg/function"
 	"github.com/inngest/inngest/pkg/logger"
+	"github.com/inngest/inngest/pkg/publisher"
 	"github.com/inngest/inngest/pkg/service"
 )
 
+// StartOpts configures the dev server.
+type StartOpts struct {
+	// Dir is the directory to load functions from.
+	Dir string
+	// UIAddr is the address to serve the web UI on, eg. "127.0.0.1:8288".
+	// The UI isn't served if this is empty.
+	UIAddr string
+}
+
 // Create and start a new dev server (API, Exectutor, State, Logger, etc.)
-func NewDevServer(ctx context.Context, c config.Config, dir string) error {
+func NewDevServer(ctx context.Context, c config.Config, opts StartOpts) error {
 	// Create a new filesystem loader.
-	el, err := inmemorydatastore.NewFSLoader(ctx, dir)
+	el, err := inmemorydatastore.NewFSLoader(ctx, opts.Dir)
 	if err != nil {
 		return err
 	}
@@ -30,6 +39,13 @@ func NewDevServer(ctx context.Context, c config.Config, dir string) error {
 	if err != nil {
 		return err
 	}
+	fns := make([]*function.Function, len(funcs))
+	for n := range funcs {
+		fns[n] = &funcs[n]
+	}
+	if err := validate(ctx, fns); err != nil {
+		return err
+	}
 
 	// For each function, build the image.
 	if err := buildImages(ctx, funcs); err != nil {
@@ -42,14 +58,46 @@ func NewDevServer(ctx context.Context, c config.Config, dir string) error {
 		c.Execution.LogOutput = true
 	}
 
-	return newDevServer(ctx, c, el)
+	// Reload functions and rebuild changed steps as files change, without
+	// restarting the dev server.
+	w, err := newWatcher(ctx, el)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := w.Watch(ctx); err != nil {
+			logger.From(ctx).Error().Err(err).Msg("error watching functions")
+		}
+	}()
+
+	return newDevServer(ctx, c, el, opts.UIAddr)
 }
 
-func newDevServer(ctx context.Context, c config.Config, el coredata.ExecutionLoader) error {
-	api := api.NewService(c)
+// validate statically analyses each function's steps, logging warnings for
+// issues found within the functions as a whole.
+func validate(ctx context.Context, fns []*function.Function) error {
+	for _, fn := range fns {
+		if err := fn.ValidateGraph(ctx); err != nil {
+			return fmt.Errorf("function '%s' is not valid: %w", fn.Name, err)
+		}
+	}
+	for _, w := range function.Warnings(ctx, fns, nil) {
+		logger.From(ctx).Warn().Msg(w)
+	}
+	return nil
+}
+
+func newDevServer(ctx context.Context, c config.Config, el coredata.ExecutionLoader, uiAddr string) error {
+	api := api.NewService(c, api.WithoutDatastoreKeys())
 	runner := runner.NewService(c, runner.WithExecutionLoader(el))
 	exec := executor.NewService(c, executor.WithExecutionLoader(el))
-	return service.StartAll(ctx, api, runner, exec)
+	pub := publisher.NewService(c)
+
+	services := []service.Service{api, runner, exec, pub}
+	if uiAddr != "" {
+		services = append(services, newUI(c, uiAddr))
+	}
+	return service.StartAll(ctx, services...)
 }
 
 // buildImages builds all images hosted within the engine.  This iterates through all
@@ -69,20 +117,23 @@ func buildImages(ctx context.Context, funcs []function.Function) error {
 		return nil
 	}
 
+	return build(ctx, opts)
+}
+
+// build builds the given docker images, rendering the build's progress.
+func build(ctx context.Context, opts []dockerdriver.BuildOpts) error {
 	ui, err := cli.NewBuilder(ctx, cli.BuilderUIOpts{
 		QuitOnComplete: true,
 		BuildOpts:      opts,
 	})
 	if err != nil {
-		fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
-		os.Exit(1)
+		return err
 	}
 	// calling Start on our UI instance invokes either a pretty TTY output
 	// via tea, or renders output as JSON directly depending on the global
 	// JSON flag.
 	if err := ui.Start(ctx); err != nil {
-		fmt.Println("\n" + cli.RenderError(err.Error()) + "\n")
-		os.Exit(1)
+		return err
 	}
 
 	return ui.Error()
@@ -12,6 +12,7 @@ import (
 	"github.com/inngest/inngest/inngest"
 	"github.com/inngest/inngest/pkg/config"
 	"github.com/inngest/inngest/pkg/config/registration"
+	"github.com/inngest/inngest/pkg/coredata"
 	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
 	"github.com/inngest/inngest/pkg/event"
 	"github.com/inngest/inngest/pkg/execution/driver/mockdriver"
@@ -97,7 +98,7 @@ func TestEngine_async(t *testing.T) {
 
 	go func() {
 		// Start the engine.
-		err = newDevServer(ctx, *conf, el)
+		err = newDevServer(ctx, *conf, el, "")
 		require.NoError(t, err)
 	}()
 
@@ -153,6 +154,16 @@ func TestEngine_async(t *testing.T) {
 	// Assert that the first step ran.
 	require.Equal(t, "Basic step", driver.Executed["first"].Name)
 
+	// The event should be recorded along with the run it created.
+	rw, err := conf.DataStore.Service.Concrete.ReadWriter(ctx)
+	require.NoError(t, err)
+	name := "test/new.event"
+	require.Eventually(t, func() bool {
+		events, err := rw.Events(ctx, coredata.EventQuery{Name: &name})
+		require.NoError(t, err)
+		return len(events) == 1 && len(events[0].Runs) == 1
+	}, time.Second, 10*time.Millisecond)
+
 	// And we should have a pause.
 	require.Eventually(t, func() bool {
 		n := 0
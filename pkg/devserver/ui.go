@@ -0,0 +1,513 @@
+package devserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/api"
+	"github.com/inngest/inngest/pkg/api/ratelimit"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/logger"
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/inngest/inngest/pkg/service"
+	"github.com/oklog/ulid/v2"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// pages lists the templates rendered by the UI, each of which is rendered
+// within templates/layout.html.
+var pages = []string{"events.html", "event.html", "run.html"}
+
+// uiEventLimit is the number of events listed within the UI.
+const uiEventLimit = 50
+
+// newUI returns a service which serves the dev server's web UI on the given
+// address.  The UI lists received events, the runs they triggered, and the
+// state of each run, reading from the dev server's event store and state.
+func newUI(c config.Config, addr string) service.Service {
+	return &ui{config: c, addr: addr}
+}
+
+type ui struct {
+	config config.Config
+	addr   string
+
+	// events reads received events and the runs they triggered.
+	events coredata.EventReader
+	// state loads the state of each run.
+	state state.Manager
+	// ingester checks resent events as if they were sent to the event API.
+	ingester *api.Ingester
+	// publisher publishes resent events to the event stream.
+	publisher pubsub.Publisher
+
+	// csrfToken must be submitted with each form, preventing other sites
+	// from resending events via the browser.  It is generated on start.
+	csrfToken string
+
+	templates map[string]*template.Template
+	server    *http.Server
+}
+
+func (u *ui) Name() string {
+	return "ui"
+}
+
+func (u *ui) Pre(ctx context.Context) (err error) {
+	rw, err := u.config.DataStore.Service.Concrete.ReadWriter(ctx)
+	if err != nil {
+		return err
+	}
+	u.events = rw
+
+	var limiter ratelimit.Limiter
+	if u.config.EventAPI.RateLimit != nil {
+		limiter, err = ratelimit.New(ctx, *u.config.EventAPI.RateLimit)
+		if err != nil {
+			return err
+		}
+	}
+	u.ingester = api.NewIngester(api.Options{
+		Config:    u.config,
+		Logger:    logger.From(ctx),
+		Limiter:   limiter,
+		Functions: rw,
+	})
+
+	u.state, err = u.config.State.Service.Concrete.Manager(ctx)
+	if err != nil {
+		return err
+	}
+
+	u.publisher, err = pubsub.NewPublisher(ctx, u.config.EventStream.Service)
+	if err != nil {
+		return err
+	}
+
+	u.csrfToken, err = newCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	u.templates, err = parseTemplates()
+	return err
+}
+
+func (u *ui) Run(ctx context.Context) error {
+	u.server = &http.Server{
+		Addr:    u.addr,
+		Handler: u.handler(),
+	}
+	logger.From(ctx).Info().Str("addr", u.addr).Msg("starting ui")
+	err := u.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (u *ui) Stop(ctx context.Context) error {
+	if u.server != nil {
+		if err := u.server.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	return pubsub.Close(u.publisher)
+}
+
+// handler returns the UI's routes.  The UI uses its own mux, as the event API
+// registers its routes on the default mux.
+func (u *ui) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", u.listEvents)
+	mux.HandleFunc("/events/", u.event)
+	mux.HandleFunc("/runs/", u.run)
+	return mux
+}
+
+// eventView is an event rendered within the UI.
+type eventView struct {
+	ID         string
+	Name       string
+	ReceivedAt time.Time
+	Payload    string
+	Runs       []runSummary
+	// CSRFToken is submitted when resending the event.
+	CSRFToken string
+}
+
+// runSummary is a run listed within the UI.
+type runSummary struct {
+	FunctionID string
+	RunID      string
+	Status     string
+	StartedAt  time.Time
+}
+
+// runView is the timeline of a single run.
+type runView struct {
+	runSummary
+	EventID string
+	Event   string
+	Pending int
+	Steps   []stepView
+	Pauses  []*state.Pause
+	Waits   []waitView
+}
+
+// stepView is a single step within a run's timeline.
+type stepView struct {
+	ID     string
+	Name   string
+	Status string
+	Input  string
+	Output string
+	Error  string
+}
+
+// waitView is an edge which is waiting for a duration before running the
+// incoming step.
+type waitView struct {
+	Outgoing string
+	Incoming string
+	Wait     string
+}
+
+func (u *ui) listEvents(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	events, err := u.events.Events(r.Context(), coredata.EventQuery{Limit: uiEventLimit})
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+
+	views := make([]eventView, len(events))
+	for n, evt := range events {
+		if views[n], err = u.eventView(r.Context(), evt); err != nil {
+			u.error(w, r, err)
+			return
+		}
+	}
+	u.render(w, r, "events.html", views)
+}
+
+// event renders a single event at /events/{id}, and resends the event on
+// POST /events/{id}/resend.
+func (u *ui) event(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/events/")
+	if resend := strings.TrimSuffix(id, "/resend"); resend != id {
+		u.resend(w, r, resend)
+		return
+	}
+
+	evt, err := u.events.Event(r.Context(), id)
+	if errors.Is(err, coredata.ErrEventNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+
+	view, err := u.eventView(r.Context(), *evt)
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+	view.CSRFToken = u.csrfToken
+	u.render(w, r, "event.html", view)
+}
+
+// resend publishes a copy of the given event to the event stream with a new ID
+// and timestamp, so that the event triggers new runs.
+func (u *ui) resend(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !u.validCSRFToken(r.PostFormValue("csrf")) {
+		http.Error(w, "invalid csrf token", http.StatusForbidden)
+		return
+	}
+
+	evt, err := u.events.Event(r.Context(), id)
+	if errors.Is(err, coredata.ErrEventNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+
+	// The resent event is a new event, so it's given a new ID and timestamp
+	// by the ingester, which also validates the event as the event API does.
+	resent := evt.Event
+	resent.ID = ""
+	resent.Timestamp = 0
+	if _, err := u.ingester.Ingest(r.Context(), "", nil, []*event.Event{&resent}); err != nil {
+		var ierr *api.IngestError
+		if errors.As(err, &ierr) {
+			http.Error(w, ierr.Message, ierr.StatusCode)
+			return
+		}
+		u.error(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	byt, err := json.Marshal(resent)
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+	err = u.publisher.Publish(r.Context(), u.config.EventStream.Service.TopicName(), pubsub.Message{
+		Name:      event.EventReceivedName,
+		Data:      string(byt),
+		Timestamp: now,
+	})
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+
+	// The event is stored once the runner receives it, so redirect to the
+	// list of events rather than the resent event.
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// run renders the timeline of the run at /runs/{id}.
+func (u *ui) run(w http.ResponseWriter, r *http.Request) {
+	runID, err := ulid.Parse(strings.TrimPrefix(r.URL.Path, "/runs/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	runs, err := u.events.Runs(r.Context(), coredata.RunQuery{RunID: &runID, Limit: 1})
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+	if len(runs) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	s, err := u.state.Load(r.Context(), runs[0].Identifier)
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+
+	view, err := u.runView(r.Context(), runs[0].FunctionID, s)
+	if err != nil {
+		u.error(w, r, err)
+		return
+	}
+	u.render(w, r, "run.html", view)
+}
+
+func (u *ui) eventView(ctx context.Context, evt coredata.Event) (eventView, error) {
+	payload, err := prettyJSON(evt.Event)
+	if err != nil {
+		return eventView{}, err
+	}
+
+	view := eventView{
+		ID:         evt.ID,
+		Name:       evt.Name,
+		ReceivedAt: evt.ReceivedAt,
+		Payload:    payload,
+		Runs:       make([]runSummary, len(evt.Runs)),
+	}
+	for n, run := range evt.Runs {
+		view.Runs[n] = runSummary{
+			FunctionID: run.FunctionID,
+			RunID:      run.Identifier.RunID.String(),
+			Status:     "unknown",
+		}
+		// The run's state may have been removed from the state store.
+		s, err := u.state.Load(ctx, run.Identifier)
+		if err != nil {
+			continue
+		}
+		view.Runs[n].Status = string(state.Status(s, time.Now()))
+		view.Runs[n].StartedAt = s.Metadata().StartedAt
+	}
+	return view, nil
+}
+
+func (u *ui) runView(ctx context.Context, functionID string, s state.State) (runView, error) {
+	evt, err := prettyJSON(s.Event())
+	if err != nil {
+		return runView{}, err
+	}
+
+	md := s.Metadata()
+	view := runView{
+		runSummary: runSummary{
+			FunctionID: functionID,
+			RunID:      s.RunID().String(),
+			Status:     string(state.Status(s, time.Now())),
+			StartedAt:  md.StartedAt,
+		},
+		EventID: s.Identifier().Key,
+		Event:   evt,
+		Pending: md.Pending,
+	}
+
+	w := s.Workflow()
+	actions := s.Actions()
+	errs := s.Errors()
+
+	for _, step := range w.Steps {
+		sv := stepView{ID: step.ID, Name: step.Name, Status: "pending"}
+
+		// Steps are given the triggering event and the output of each
+		// parent step.
+		input := map[string]interface{}{"event": s.Event()}
+		steps := map[string]interface{}{}
+		for _, edge := range w.Edges {
+			if edge.Incoming != step.ID {
+				continue
+			}
+			if output, ok := actions[edge.Outgoing]; ok {
+				steps[edge.Outgoing] = output
+			}
+		}
+		input["steps"] = steps
+		if sv.Input, err = prettyJSON(input); err != nil {
+			return runView{}, err
+		}
+
+		if output, ok := actions[step.ID]; ok {
+			sv.Status = "completed"
+			if sv.Output, err = prettyJSON(output); err != nil {
+				return runView{}, err
+			}
+		}
+		if stepErr, ok := errs[step.ID]; ok {
+			sv.Status = "failed"
+			sv.Error = stepErr.Error()
+		}
+		view.Steps = append(view.Steps, sv)
+	}
+
+	// Pauses are stored for each outgoing step of an edge, including the
+	// trigger.
+	ids := []string{inngest.TriggerName}
+	for _, step := range w.Steps {
+		ids = append(ids, step.ID)
+	}
+	for _, id := range ids {
+		pause, err := u.state.PauseByStep(ctx, s.Identifier(), id)
+		if errors.Is(err, state.ErrPauseNotFound) {
+			continue
+		}
+		if err != nil {
+			return runView{}, err
+		}
+		view.Pauses = append(view.Pauses, pause)
+	}
+
+	// Edges with a wait are pending once their outgoing step has completed,
+	// until the incoming step runs.
+	if md.Pending > 0 && !md.Cancelled {
+		for _, edge := range w.Edges {
+			if edge.Metadata == nil || edge.Metadata.Wait == nil {
+				continue
+			}
+			if edge.Outgoing != inngest.TriggerName && !s.ActionComplete(edge.Outgoing) {
+				continue
+			}
+			if _, ok := actions[edge.Incoming]; ok {
+				continue
+			}
+			if _, ok := errs[edge.Incoming]; ok {
+				continue
+			}
+			view.Waits = append(view.Waits, waitView{
+				Outgoing: edge.Outgoing,
+				Incoming: edge.Incoming,
+				Wait:     *edge.Metadata.Wait,
+			})
+		}
+	}
+
+	return view, nil
+}
+
+// render renders the given page within the layout.
+func (u *ui) render(w http.ResponseWriter, r *http.Request, page string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := u.templates[page].ExecuteTemplate(w, "layout", data); err != nil {
+		logger.From(r.Context()).Error().Err(err).Str("page", page).Msg("error rendering ui")
+	}
+}
+
+func (u *ui) error(w http.ResponseWriter, r *http.Request, err error) {
+	logger.From(r.Context()).Error().Err(err).Str("path", r.URL.Path).Msg("ui error")
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// validCSRFToken returns whether the given token matches the UI's token.
+func (u *ui) validCSRFToken(token string) bool {
+	return u.csrfToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(u.csrfToken)) == 1
+}
+
+func newCSRFToken() (string, error) {
+	byt := make([]byte, 32)
+	if _, err := rand.Read(byt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(byt), nil
+}
+
+// parseTemplates parses each page along with the layout.  Pages are parsed
+// separately as each defines its own "content" template.
+func parseTemplates() (map[string]*template.Template, error) {
+	funcs := template.FuncMap{
+		"time": func(t time.Time) string {
+			if t.IsZero() {
+				return "-"
+			}
+			return t.Local().Format("2006-01-02 15:04:05")
+		},
+	}
+
+	templates := map[string]*template.Template{}
+	for _, page := range pages {
+		t, err := template.New(page).Funcs(funcs).ParseFS(templateFS, "templates/layout.html", "templates/"+page)
+		if err != nil {
+			return nil, err
+		}
+		templates[page] = t
+	}
+	return templates, nil
+}
+
+func prettyJSON(v interface{}) (string, error) {
+	byt, err := json.MarshalIndent(v, "", "  ")
+	return string(byt), err
+}
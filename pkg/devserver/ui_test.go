@@ -0,0 +1,271 @@
+package devserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/api"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/execution/state/inmemory"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	topics   []string
+	messages []pubsub.Message
+}
+
+func (r *recordingPublisher) Publish(ctx context.Context, topic string, m pubsub.Message) error {
+	r.topics = append(r.topics, topic)
+	r.messages = append(r.messages, m)
+	return nil
+}
+
+func TestUI(t *testing.T) {
+	ctx := context.Background()
+
+	conf, err := config.Default(ctx)
+	require.NoError(t, err)
+	data, err := inmemorydatastore.New(ctx)
+	require.NoError(t, err)
+	sm := inmemory.NewStateManager()
+	pub := &recordingPublisher{}
+	templates, err := parseTemplates()
+	require.NoError(t, err)
+
+	u := &ui{
+		config:    *conf,
+		events:    data,
+		state:     sm,
+		ingester:  api.NewIngester(api.Options{Config: *conf, Functions: data}),
+		publisher: pub,
+		templates: templates,
+		csrfToken: "test-csrf-token",
+	}
+	srv := httptest.NewServer(u.handler())
+	defer srv.Close()
+
+	wait := "1h"
+	w := inngest.Workflow{
+		UUID: uuid.New(),
+		Steps: []inngest.Step{
+			{ID: "first", Name: "First step"},
+			{ID: "second", Name: "Second step"},
+			{ID: "third", Name: "Third step"},
+		},
+		Edges: []inngest.Edge{
+			{Outgoing: inngest.TriggerName, Incoming: "first"},
+			{Outgoing: "first", Incoming: "second", Metadata: &inngest.EdgeMetadata{Wait: &wait}},
+			{Outgoing: "first", Incoming: "third"},
+		},
+	}
+	evtID := ulid.MustNew(ulid.Now(), rand.Reader).String()
+	id := state.Identifier{
+		WorkflowID: w.UUID,
+		RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+		Key:        evtID,
+	}
+	evt := event.Event{ID: evtID, Name: "test/event", Data: map[string]any{"user": "tester"}, Source: "test-key"}
+	_, err = sm.New(ctx, w, id, evt.Map())
+	require.NoError(t, err)
+	err = data.SaveEvent(ctx, coredata.Event{
+		ID:         evtID,
+		Name:       evt.Name,
+		Event:      evt,
+		ReceivedAt: time.Now(),
+		Runs:       []coredata.EventRun{{FunctionID: "test-fn", Identifier: id}},
+	})
+	require.NoError(t, err)
+
+	_, err = sm.SaveResponse(ctx, id, state.DriverResponse{
+		Step:   w.Steps[0],
+		Output: map[string]any{"first-output": true},
+	}, 0)
+	require.NoError(t, err)
+	_, err = sm.SaveResponse(ctx, id, state.DriverResponse{
+		Step: w.Steps[2],
+		Err:  fmt.Errorf("third step broke"),
+	}, 0)
+	require.NoError(t, err)
+
+	pauseEvt := "test/continue"
+	require.NoError(t, sm.SavePause(ctx, state.Pause{
+		ID:         uuid.New(),
+		Identifier: id,
+		Outgoing:   inngest.TriggerName,
+		Incoming:   "third",
+		Expires:    time.Now().Add(time.Hour),
+		Event:      &pauseEvt,
+	}))
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		byt, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(byt)
+	}
+
+	t.Run("lists events and their runs", func(t *testing.T) {
+		status, body := get("/")
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, body, "test/event")
+		require.Contains(t, body, "/events/"+evtID)
+		require.Contains(t, body, "/runs/"+id.RunID.String())
+		require.Contains(t, body, "running")
+	})
+
+	t.Run("renders an event", func(t *testing.T) {
+		status, body := get("/events/" + evtID)
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, body, "tester")
+		require.Contains(t, body, "/events/"+evtID+"/resend")
+		require.Contains(t, body, "test-csrf-token")
+
+		status, _ = get("/events/missing")
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("renders a run timeline", func(t *testing.T) {
+		status, body := get("/runs/" + id.RunID.String())
+		require.Equal(t, http.StatusOK, status)
+		for _, s := range []string{"First step", "Second step", "Third step", "first-output", "third step broke", "test/continue", "1h"} {
+			require.Contains(t, body, s)
+		}
+
+		status, _ = get("/runs/" + ulid.MustNew(ulid.Now(), rand.Reader).String())
+		require.Equal(t, http.StatusNotFound, status)
+		status, _ = get("/runs/invalid")
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("resends an event", func(t *testing.T) {
+		status, _ := get("/events/" + evtID + "/resend")
+		require.Equal(t, http.StatusMethodNotAllowed, status)
+		require.Len(t, pub.messages, 0)
+
+		for _, token := range []string{"", "invalid"} {
+			resp, err := http.PostForm(srv.URL+"/events/"+evtID+"/resend", url.Values{"csrf": {token}})
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		}
+		require.Len(t, pub.messages, 0)
+
+		resp, err := http.PostForm(srv.URL+"/events/"+evtID+"/resend", url.Values{"csrf": {"test-csrf-token"}})
+		require.NoError(t, err)
+		resp.Body.Close()
+		// The client follows the redirect to the list of events.
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "/", resp.Request.URL.Path)
+
+		require.Len(t, pub.messages, 1)
+		require.Equal(t, conf.EventStream.Service.TopicName(), pub.topics[0])
+		require.Equal(t, event.EventReceivedName, pub.messages[0].Name)
+
+		resent := event.Event{}
+		require.NoError(t, json.Unmarshal([]byte(pub.messages[0].Data), &resent))
+		require.Equal(t, evt.Name, resent.Name)
+		require.Equal(t, evt.Data, resent.Data)
+		require.NotEqual(t, evtID, resent.ID)
+		require.NotZero(t, resent.Timestamp)
+		// Resent events weren't sent with the original event's key.
+		require.Empty(t, resent.Source)
+	})
+
+	t.Run("rejects resent events which don't match their definition", func(t *testing.T) {
+		require.NoError(t, data.SetFunctions(ctx, []*function.Function{
+			{
+				ID:   "test-fn",
+				Name: "test-fn",
+				Triggers: []function.Trigger{
+					{EventTrigger: &function.EventTrigger{
+						Event: "test/event",
+						Definition: &function.EventDefinition{
+							Format: function.FormatCue,
+							Def:    `{ name: "test/event", data: { email: string } }`,
+						},
+					}},
+				},
+				Steps: map[string]function.Step{
+					"first": {
+						ID:      "first",
+						Name:    "first",
+						Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+						After:   []function.After{{Step: inngest.TriggerName}},
+					},
+				},
+			},
+		}))
+
+		c := *conf
+		c.EventAPI.Validation = config.ValidationReject
+		rejecting := *u
+		rejecting.ingester = api.NewIngester(api.Options{Config: c, Functions: data})
+		srv := httptest.NewServer(rejecting.handler())
+		defer srv.Close()
+
+		resp, err := http.PostForm(srv.URL+"/events/"+evtID+"/resend", url.Values{"csrf": {"test-csrf-token"}})
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Len(t, pub.messages, 1)
+	})
+}
+
+func TestRunView(t *testing.T) {
+	ctx := context.Background()
+	sm := inmemory.NewStateManager()
+	u := &ui{state: sm}
+
+	w := inngest.Workflow{
+		UUID: uuid.New(),
+		Steps: []inngest.Step{
+			{ID: "first", Name: "First"},
+			{ID: "second", Name: "Second"},
+		},
+		Edges: []inngest.Edge{
+			{Outgoing: inngest.TriggerName, Incoming: "first"},
+			{Outgoing: "first", Incoming: "second"},
+		},
+	}
+	id := state.Identifier{WorkflowID: w.UUID, RunID: ulid.MustNew(ulid.Now(), rand.Reader)}
+	_, err := sm.New(ctx, w, id, map[string]any{"name": "test/event"})
+	require.NoError(t, err)
+
+	s, err := sm.SaveResponse(ctx, id, state.DriverResponse{Step: w.Steps[0], Output: map[string]any{"ok": true}}, 0)
+	require.NoError(t, err)
+
+	view, err := u.runView(ctx, "fn", s)
+	require.NoError(t, err)
+	require.Equal(t, "running", view.Status)
+	require.Len(t, view.Steps, 2)
+	require.Equal(t, "completed", view.Steps[0].Status)
+	require.JSONEq(t, `{"ok":true}`, view.Steps[0].Output)
+	require.Equal(t, "pending", view.Steps[1].Status)
+
+	// The second step is given the output of the first.
+	input := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(view.Steps[1].Input), &input))
+	require.Equal(t, map[string]any{"first": map[string]any{"ok": true}}, input["steps"])
+	require.Empty(t, view.Pauses)
+	require.Empty(t, view.Waits)
+}
@@ -0,0 +1,206 @@
+package devserver
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/execution/driver/dockerdriver"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/logger"
+)
+
+const (
+	// defaultDebounce is how long the watcher waits after a file changes before
+	// reloading functions, so that saving many files reloads once.
+	defaultDebounce = 250 * time.Millisecond
+)
+
+// watcher watches the dev server's function directory, reloading functions and
+// rebuilding the docker images of changed steps whenever files change.
+type watcher struct {
+	loader *inmemorydatastore.FSLoader
+	// build builds the given docker images.
+	build func(ctx context.Context, opts []dockerdriver.BuildOpts) error
+	// debounce is how long to wait for further changes before reloading.
+	debounce time.Duration
+	// images stores the build options of each docker step from the last
+	// successful reload, keyed by image tag.
+	images map[string]dockerdriver.BuildOpts
+}
+
+func newWatcher(ctx context.Context, loader *inmemorydatastore.FSLoader) (*watcher, error) {
+	w := &watcher{
+		loader:   loader,
+		build:    build,
+		debounce: defaultDebounce,
+		images:   map[string]dockerdriver.BuildOpts{},
+	}
+
+	// Record the images built when the dev server started.
+	fns, err := loader.Functions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range fns {
+		opts, err := dockerdriver.FnBuildOpts(ctx, fn)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range opts {
+			w.images[o.Tag] = o
+		}
+	}
+	return w, nil
+}
+
+// Watch watches the function directory until the context is cancelled.
+func (w *watcher) Watch(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsw.Close()
+
+	if err := addDirs(fsw, w.loader.Root()); err != nil {
+		return err
+	}
+
+	l := logger.From(ctx)
+	l.Info().Str("dir", w.loader.Root()).Msg("watching functions for changes")
+
+	changed := map[string]struct{}{}
+	// timer fires once no further changes have been received within the
+	// debounce period.
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			l.Error().Err(err).Msg("error watching functions")
+
+		case evt, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if evt.Op == fsnotify.Chmod || ignored(filepath.Base(evt.Name)) {
+				continue
+			}
+			if evt.Op&fsnotify.Create != 0 {
+				// Watch new directories, as fsnotify doesn't watch
+				// directories recursively.
+				if info, err := os.Stat(evt.Name); err == nil && info.IsDir() {
+					if err := addDirs(fsw, evt.Name); err != nil {
+						l.Error().Err(err).Str("dir", evt.Name).Msg("error watching directory")
+					}
+				}
+			}
+			changed[evt.Name] = struct{}{}
+			timer.Reset(w.debounce)
+
+		case <-timer.C:
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			changed = map[string]struct{}{}
+
+			if err := w.reload(ctx, paths); err != nil {
+				// Keep running the previous functions until the error
+				// is fixed.
+				l.Error().Err(err).Msg("error reloading functions")
+			}
+		}
+	}
+}
+
+// reload loads and validates all functions, rebuilds the images of docker steps
+// affected by the changed paths, then swaps the functions within the loader.
+// The loader is left unchanged if any step fails.
+func (w *watcher) reload(ctx context.Context, changed []string) error {
+	fns, err := function.LoadRecursive(ctx, w.loader.Root())
+	if err != nil {
+		return err
+	}
+	if err := validate(ctx, fns); err != nil {
+		return err
+	}
+
+	images := map[string]dockerdriver.BuildOpts{}
+	rebuild := []dockerdriver.BuildOpts{}
+	for _, fn := range fns {
+		opts, err := dockerdriver.FnBuildOpts(ctx, *fn)
+		if err != nil {
+			return err
+		}
+		for _, o := range opts {
+			images[o.Tag] = o
+			if prev, ok := w.images[o.Tag]; !ok || prev.Path != o.Path || contains(o.Path, changed) {
+				rebuild = append(rebuild, o)
+			}
+		}
+	}
+
+	if len(rebuild) > 0 {
+		if err := w.build(ctx, rebuild); err != nil {
+			return err
+		}
+	}
+
+	if err := w.loader.SetFunctions(ctx, fns); err != nil {
+		return err
+	}
+	w.images = images
+
+	logger.From(ctx).Info().
+		Int("functions", len(fns)).
+		Int("rebuilt", len(rebuild)).
+		Msg("reloaded functions")
+	return nil
+}
+
+// contains returns whether any of the given paths are within dir.
+func contains(dir string, paths []string) bool {
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addDirs watches the given directory and all of its subdirectories.
+func addDirs(fsw *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && ignored(d.Name()) {
+			return filepath.SkipDir
+		}
+		return fsw.Add(path)
+	})
+}
+
+// ignored returns whether changes to the given file or directory name are
+// ignored, eg. hidden files, editor swap files, and installed dependencies.
+func ignored(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || name == "node_modules"
+}
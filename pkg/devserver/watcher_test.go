@@ -0,0 +1,90 @@
+package devserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/execution/driver/dockerdriver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	write := func(path, data string) {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+	}
+	write("a/inngest.json", `{"id": "fn-a", "name": "a", "triggers": [{"event": "test/a"}]}`)
+	write("a/main.js", "console.log('a')")
+	write("b/inngest.json", `{"id": "fn-b", "name": "b", "triggers": [{"event": "test/b"}]}`)
+
+	loader, err := inmemorydatastore.NewFSLoader(ctx, dir)
+	require.NoError(t, err)
+
+	var (
+		lock   sync.Mutex
+		builds [][]dockerdriver.BuildOpts
+	)
+	w, err := newWatcher(ctx, loader)
+	require.NoError(t, err)
+	require.Len(t, w.images, 2)
+	w.debounce = 10 * time.Millisecond
+	w.build = func(ctx context.Context, opts []dockerdriver.BuildOpts) error {
+		lock.Lock()
+		defer lock.Unlock()
+		builds = append(builds, opts)
+		return nil
+	}
+	built := func() [][]dockerdriver.BuildOpts {
+		lock.Lock()
+		defer lock.Unlock()
+		return builds
+	}
+
+	go func() {
+		require.NoError(t, w.Watch(ctx))
+	}()
+	<-time.After(50 * time.Millisecond)
+
+	// Changing a step's source rebuilds only that step.
+	write("a/main.js", "console.log('changed')")
+	require.Eventually(t, func() bool { return len(built()) == 1 }, time.Second, 10*time.Millisecond)
+	require.Len(t, built()[0], 1)
+	require.Equal(t, filepath.Join(dir, "a"), built()[0][0].Path)
+
+	// New functions are built and loaded.
+	write("c/inngest.json", `{"id": "fn-c", "name": "c", "triggers": [{"event": "test/c"}]}`)
+	require.Eventually(t, func() bool {
+		fns, err := loader.FunctionsByTrigger(ctx, "test/c")
+		require.NoError(t, err)
+		return len(fns) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, built(), 2)
+	require.Len(t, built()[1], 1)
+	require.Equal(t, filepath.Join(dir, "c"), built()[1][0].Path)
+
+	// Invalid functions leave the previous functions running.
+	write("b/inngest.json", `{"id": "fn-b", "name": "b", "triggers": [`)
+	<-time.After(100 * time.Millisecond)
+	fns, err := loader.FunctionsByTrigger(ctx, "test/b")
+	require.NoError(t, err)
+	require.Len(t, fns, 1)
+	require.Len(t, built(), 2)
+
+	// Fixing the function reloads it.
+	write("b/inngest.json", `{"id": "fn-b", "name": "b", "triggers": [{"event": "test/b.changed"}]}`)
+	require.Eventually(t, func() bool {
+		fns, err := loader.FunctionsByTrigger(ctx, "test/b.changed")
+		require.NoError(t, err)
+		return len(fns) == 1
+	}, time.Second, 10*time.Millisecond)
+}
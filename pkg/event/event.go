@@ -1,5 +1,30 @@
 package event
 
+const (
+	// EventReceivedName is the name of the pubsub message published to the
+	// event stream when an event is received.
+	EventReceivedName = "event/event.received"
+
+	// RunFinishedName is the name of the pubsub message published to the
+	// publisher's topic when a function run finishes.  The message's data is
+	// an Event named FunctionFinishedName.
+	RunFinishedName = "run/run.finished"
+
+	// FunctionFinishedName is the name of the event describing a finished
+	// function run, including the run's status and step outputs.
+	FunctionFinishedName = "inngest/function.finished"
+
+	// MetadataValidationErrors is the metadata key used to tag events which
+	// don't match the definitions of the functions they trigger.  The value
+	// is a map of function IDs to validation errors.
+	MetadataValidationErrors = "validationErrors"
+
+	// MetadataCloudEvent is the metadata key used to store the attributes of
+	// events received as CloudEvents, including extensions, which aren't
+	// mapped to the event itself.
+	MetadataCloudEvent = "cloudevent"
+)
+
 // Event represents an event sent to Inngest.
 type Event struct {
 	Name string                 `json:"name"`
@@ -16,6 +41,21 @@ type Event struct {
 	// If this is not provided, we will insert the current time upon receipt of the event
 	Timestamp int64  `json:"ts,omitempty"`
 	Version   string `json:"v,omitempty"`
+
+	// Source is the name of the source key used to send the event.  This is
+	// always set by the event API for auditing, and is never user-supplied.
+	Source string `json:"source,omitempty"`
+
+	// Metadata stores additional information about the event which isn't
+	// part of the event's payload, eg. tags added by the event API.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ValidationErrors returns the validation errors tagged by the event API,
+// keyed by function ID.
+func (evt Event) ValidationErrors() map[string]interface{} {
+	errs, _ := evt.Metadata[MetadataValidationErrors].(map[string]interface{})
+	return errs
 }
 
 func (evt Event) Map() map[string]interface{} {
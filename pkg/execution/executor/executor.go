@@ -171,6 +171,11 @@ func (e *executor) Execute(ctx context.Context, id state.Identifier, from string
 		return nil, err
 	}
 
+	if s.Metadata().Cancelled {
+		// Cancelled runs never execute further steps.
+		return nil, state.ErrFunctionCancelled
+	}
+
 	w := s.Workflow()
 
 	// This could have been retried due to a state load error after
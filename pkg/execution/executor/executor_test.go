@@ -326,6 +326,35 @@ func TestExecute_edge_expressions(t *testing.T) {
 	require.ElementsMatch(t, []string{"run-step-child"}, availableIDs(edges))
 }
 
+func TestExecute_cancelled(t *testing.T) {
+	ctx := context.Background()
+	sm := inmemory.NewStateManager()
+	al := inmemorydatastore.NewInMemoryActionLoader()
+
+	w := inngest.Workflow{
+		UUID:  uuid.New(),
+		Steps: []inngest.Step{{DSN: "test", ID: "1"}},
+		Edges: []inngest.Edge{{Outgoing: inngest.TriggerName, Incoming: "1"}},
+	}
+	id := state.Identifier{RunID: ulid.MustNew(ulid.Now(), rand.Reader)}
+	_, err := sm.New(ctx, w, id, map[string]interface{}{})
+	require.NoError(t, err)
+	require.NoError(t, sm.(state.RunCanceller).Cancel(ctx, id))
+
+	driver := &mockdriver.Mock{}
+	exec, err := NewExecutor(
+		WithStateManager(sm),
+		WithActionLoader(al),
+		WithRuntimeDrivers(driver),
+	)
+	require.NoError(t, err)
+
+	// Steps of cancelled runs are never executed.
+	_, err = exec.Execute(ctx, id, "1", 0)
+	require.ErrorIs(t, err, state.ErrFunctionCancelled)
+	require.Equal(t, 0, len(driver.Executed))
+}
+
 func availableIDs(edges []inngest.Edge) []string {
 	strs := make([]string, len(edges))
 	for n, e := range edges {
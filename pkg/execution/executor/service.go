@@ -2,19 +2,25 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
-	"time"
 
 	"github.com/google/uuid"
+	"github.com/inngest/inngest/inngest"
 	"github.com/inngest/inngest/pkg/backoff"
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/config"
 	"github.com/inngest/inngest/pkg/coredata"
 	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/event"
 	"github.com/inngest/inngest/pkg/execution/driver"
+	"github.com/inngest/inngest/pkg/execution/lifecycle"
 	"github.com/inngest/inngest/pkg/execution/queue"
 	"github.com/inngest/inngest/pkg/execution/state"
 	"github.com/inngest/inngest/pkg/logger"
+	"github.com/inngest/inngest/pkg/pubsub"
 	"github.com/inngest/inngest/pkg/service"
 	"github.com/xhit/go-str2duration/v2"
 )
@@ -27,8 +33,16 @@ func WithExecutionLoader(l coredata.ExecutionLoader) func(s *svc) {
 	}
 }
 
+// WithClock sets the clock used to schedule steps, waits and pause timeouts.
+// If this isn't provided, the system clock is used.
+func WithClock(c clock.Clock) func(s *svc) {
+	return func(s *svc) {
+		s.clock = c
+	}
+}
+
 func NewService(c config.Config, opts ...Opt) service.Service {
-	svc := &svc{config: c}
+	svc := &svc{config: c, clock: clock.New()}
 	for _, o := range opts {
 		o(svc)
 	}
@@ -45,6 +59,11 @@ type svc struct {
 	queue queue.Queue
 	// exec runs the specific actions.
 	exec Executor
+	// pubsub publishes run finished messages to the publisher's topic, and
+	// step and run lifecycle updates to the lifecycle topic.
+	pubsub pubsub.Publisher
+	// clock returns the current time when scheduling steps.
+	clock clock.Clock
 
 	wg sync.WaitGroup
 }
@@ -74,6 +93,11 @@ func (s *svc) Pre(ctx context.Context) error {
 		return err
 	}
 
+	s.pubsub, err = pubsub.NewPublisher(ctx, s.config.EventStream.Service)
+	if err != nil {
+		return err
+	}
+
 	// Create drivers based off of the available config
 	var drivers = []driver.Driver{}
 	for _, driverConfig := range s.config.Execution.Drivers {
@@ -128,7 +152,7 @@ func (s *svc) Run(ctx context.Context) error {
 func (s *svc) Stop(ctx context.Context) error {
 	// Wait for all in-flight queue runs to finish
 	s.wg.Wait()
-	return nil
+	return pubsub.Close(s.pubsub)
 }
 
 func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
@@ -141,8 +165,18 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 
 	l.Info().Interface("edge", edge).Msg("processing step")
 
-	_, err = s.exec.Execute(ctx, item.Identifier, edge.Incoming, item.ErrorCount)
+	s.publishStep(ctx, item, edge.Incoming, lifecycle.KindStepStarted, nil, nil)
+
+	resp, err := s.exec.Execute(ctx, item.Identifier, edge.Incoming, item.ErrorCount)
+	if errors.Is(err, state.ErrFunctionCancelled) {
+		// The step was enqueued prior to the run being cancelled.  Finalize
+		// the step without running it or scheduling its children.
+		l.Info().Interface("edge", edge).Msg("skipping step of cancelled run")
+		return s.finalize(ctx, item.Identifier, edge.Incoming)
+	}
 	if err != nil {
+		s.publishStep(ctx, item, edge.Incoming, lifecycle.KindStepFailed, resp, err)
+
 		// The executor usually returns a state.DriverResponse if the step's
 		// response was an error.  In this case, the executor itself handles
 		// whether the step has been retried the max amount of times, as the
@@ -157,7 +191,7 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 		if (isRetryable && retry.Retryable()) || !isRetryable {
 			next := item
 			next.ErrorCount += 1
-			at := backoff.LinearJitterBackoff(next.ErrorCount)
+			at := s.clock.Now().Add(backoff.LinearJitterDelay(next.ErrorCount))
 			l.Info().Interface("edge", next).Time("at", at).Msg("enqueueing retry")
 			if err := s.queue.Enqueue(ctx, next, at); err != nil {
 				return err
@@ -167,16 +201,32 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 
 		// This is a non-retryable error.  Finalize this step.
 		l.Warn().Interface("edge", edge).Msg("step permanently failed")
-		if err := s.state.Finalized(ctx, item.Identifier, edge.Incoming); err != nil {
+
+		// Final driver responses are finalized by the state store when the
+		// response is saved, so finalizing the step again would remove
+		// another pending step from the run.
+		var dr *state.DriverResponse
+		if errors.As(err, &dr) && dr.Final() {
+			return s.finished(ctx, item.Identifier)
+		}
+		if err := s.finalize(ctx, item.Identifier, edge.Incoming); err != nil {
 			return err
 		}
 		return nil
 	}
 
+	if resp == nil || !resp.Scheduled {
+		s.publishStep(ctx, item, edge.Incoming, lifecycle.KindStepFinished, resp, nil)
+	}
+
 	run, err := s.state.Load(ctx, item.Identifier)
 	if err != nil {
 		return err
 	}
+	if item.FunctionID == "" {
+		// Ensure that the function is known when handling child steps.
+		item.FunctionID = run.Workflow().ID
+	}
 
 	children, err := state.DefaultEdgeEvaluator.AvailableChildren(ctx, run, edge.Incoming)
 	if err != nil {
@@ -211,7 +261,7 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 
 			l.Debug().Interface("edge", next).Msg("saving pause")
 			pauseID := uuid.New()
-			expires := time.Now().Add(dur)
+			expires := s.clock.Now().Add(dur)
 			err = s.state.SavePause(ctx, state.Pause{
 				ID:         pauseID,
 				Identifier: run.Identifier(),
@@ -233,6 +283,7 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 			if err := s.queue.Enqueue(ctx, queue.Item{
 				Kind:       queue.KindPause,
 				Identifier: item.Identifier,
+				FunctionID: item.FunctionID,
 				Payload: queue.PayloadPauseTimeout{
 					PauseID:   pauseID,
 					OnTimeout: am.OnTimeout,
@@ -243,7 +294,7 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 			continue
 		}
 
-		at := time.Now()
+		at := s.clock.Now()
 		if next.Metadata != nil && next.Metadata.Wait != nil {
 			dur, err := str2duration.ParseDuration(*next.Metadata.Wait)
 			if err != nil {
@@ -258,6 +309,7 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 		if err := s.queue.Enqueue(ctx, queue.Item{
 			Kind:       queue.KindEdge,
 			Identifier: item.Identifier,
+			FunctionID: item.FunctionID,
 			Payload:    queue.PayloadEdge{Edge: next},
 		}, at); err != nil {
 			return err
@@ -282,7 +334,7 @@ func (s *svc) handleQueueItem(ctx context.Context, item queue.Item) error {
 	//
 	// This must happen after everything is enqueued, else the scheduled <> finalized count
 	// is out of order.
-	if err := s.state.Finalized(ctx, item.Identifier, edge.Incoming); err != nil {
+	if err := s.finalize(ctx, item.Identifier, edge.Incoming); err != nil {
 		return err
 	}
 
@@ -322,17 +374,154 @@ func (s *svc) handlePauseTimeout(ctx context.Context, item queue.Item) error {
 		if err := s.queue.Enqueue(ctx, queue.Item{
 			Kind:       queue.KindEdge,
 			Identifier: item.Identifier,
+			FunctionID: item.FunctionID,
 			Payload:    queue.PayloadEdge{Edge: pause.Edge()},
-		}, time.Now()); err != nil {
+		}, s.clock.Now()); err != nil {
 			return fmt.Errorf("error enqueueing timeout step: %w", err)
 		}
 	} else {
 		l.Info().Interface("pause", pauseTimeout).Interface("edge", pause.Edge()).Msg("ignoring pause timeout")
 		// Finalize this action without it running.
-		if err := s.state.Finalized(ctx, item.Identifier, pause.Edge().Incoming); err != nil {
+		if err := s.finalize(ctx, item.Identifier, pause.Edge().Incoming); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// finalize marks the given step as finalized, publishing a run finished message
+// if the run has no pending steps.
+func (s *svc) finalize(ctx context.Context, id state.Identifier, stepID string) error {
+	if err := s.state.Finalized(ctx, id, stepID); err != nil {
+		return err
+	}
+	return s.finished(ctx, id)
+}
+
+// finished publishes a run finished message to the publisher's topic if the run
+// has no pending steps.
+//
+// Parallel steps may finalize concurrently, so the message may be published more
+// than once for a single run.  Messages use the run ID as the event ID, allowing
+// consumers to deduplicate them.
+func (s *svc) finished(ctx context.Context, id state.Identifier) error {
+	if s.pubsub == nil || (!s.config.Publisher.Enabled() && s.config.EventStream.LifecycleTopic == "") {
+		// Nothing consumes finished runs, so there's no need to load state.
+		return nil
+	}
+
+	run, err := s.state.Load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if run.Metadata().Pending > 0 {
+		return nil
+	}
+
+	// The step has finalized, so publishing errors must not retry the step.
+	if err := s.publishFinished(ctx, run); err != nil {
+		logger.From(ctx).Error().Err(err).Str("run_id", id.RunID.String()).Msg("error publishing run finished")
+	}
+	s.publishUpdate(ctx, lifecycle.Update{
+		Kind:       lifecycle.KindRunCompleted,
+		FunctionID: run.Workflow().ID,
+		RunID:      id.RunID,
+		Status:     state.Status(run, s.clock.Now()),
+		At:         s.clock.Now(),
+	})
+	return nil
+}
+
+// publishStep publishes a lifecycle update for the given step.  Updates aren't
+// published for the trigger, which runs no code.
+func (s *svc) publishStep(ctx context.Context, item queue.Item, stepID string, kind lifecycle.Kind, resp *state.DriverResponse, stepErr error) {
+	if s.pubsub == nil || stepID == inngest.TriggerName || s.config.EventStream.LifecycleTopic == "" {
+		return
+	}
+
+	fnID := item.FunctionID
+	if fnID == "" {
+		// Only load the run's state for items enqueued without the function.
+		run, err := s.state.Load(ctx, item.Identifier)
+		if err != nil {
+			logger.From(ctx).Error().Err(err).Str("run_id", item.Identifier.RunID.String()).Msg("error loading run for lifecycle update")
+			return
+		}
+		fnID = run.Workflow().ID
+	}
+
+	u := lifecycle.Update{
+		Kind:       kind,
+		FunctionID: fnID,
+		RunID:      item.Identifier.RunID,
+		StepID:     stepID,
+		Attempt:    item.ErrorCount,
+		At:         s.clock.Now(),
+	}
+	if stepErr != nil {
+		u.Error = stepErr.Error()
+	} else if resp != nil {
+		u.Output = resp.Output
+	}
+	s.publishUpdate(ctx, u)
+}
+
+// publishUpdate publishes a lifecycle update to the lifecycle topic.  Updates
+// are informational, so publishing errors are logged and never retry steps.
+func (s *svc) publishUpdate(ctx context.Context, u lifecycle.Update) {
+	if s.pubsub == nil || s.config.EventStream.LifecycleTopic == "" {
+		return
+	}
+
+	m, err := u.Message()
+	if err == nil {
+		err = s.pubsub.Publish(ctx, s.config.EventStream.LifecycleTopic, m)
+	}
+	if err != nil {
+		logger.From(ctx).Error().Err(err).Str("run_id", u.RunID.String()).Str("kind", string(u.Kind)).Msg("error publishing lifecycle update")
+	}
+}
+
+// publishFinished publishes a run finished message to the publisher's topic,
+// if the publisher has any subscriptions.
+func (s *svc) publishFinished(ctx context.Context, run state.State) error {
+	if !s.config.Publisher.Enabled() {
+		return nil
+	}
+
+	errs := map[string]interface{}{}
+	for step, err := range run.Errors() {
+		errs[step] = err.Error()
+	}
+
+	steps := map[string]interface{}{}
+	for step, output := range run.Actions() {
+		steps[step] = output
+	}
+
+	evt := event.Event{
+		ID:        run.Identifier().RunID.String(),
+		Name:      event.FunctionFinishedName,
+		Timestamp: s.clock.Now().UnixMilli(),
+		Data: map[string]interface{}{
+			"function_id": run.Workflow().ID,
+			"run_id":      run.Identifier().RunID.String(),
+			"status":      string(state.Status(run, s.clock.Now())),
+			"event":       run.Event(),
+			"steps":       steps,
+			"errors":      errs,
+		},
+	}
+
+	byt, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	return s.pubsub.Publish(ctx, s.config.Publisher.Topic, pubsub.Message{
+		Name:      event.RunFinishedName,
+		Data:      string(byt),
+		Timestamp: s.clock.Now(),
+	})
+}
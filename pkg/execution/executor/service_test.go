@@ -8,15 +8,18 @@ import (
 	"time"
 
 	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/config"
 	_ "github.com/inngest/inngest/pkg/config/defaults"
 	"github.com/inngest/inngest/pkg/coredata"
 	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
 	"github.com/inngest/inngest/pkg/event"
 	"github.com/inngest/inngest/pkg/execution/driver/mockdriver"
+	"github.com/inngest/inngest/pkg/execution/lifecycle"
 	"github.com/inngest/inngest/pkg/execution/queue"
 	"github.com/inngest/inngest/pkg/execution/state"
 	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/pubsub"
 	"github.com/inngest/inngest/pkg/service"
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
@@ -249,6 +252,47 @@ func TestHandleQueueItemTriggerService(t *testing.T) {
 	require.Equal(t, 0, run.Metadata().Pending)
 }
 
+func TestHandleQueueItemPermanentFailureService(t *testing.T) {
+	ctx := context.Background()
+	data := prepare(ctx, t, syncF)
+	data.c.Execution.Drivers["mock"] = &mockdriver.Config{
+		Responses: map[string]state.DriverResponse{
+			"1": {
+				Output: map[string]interface{}{"status": 400},
+				Err:    fmt.Errorf("bad request"),
+			},
+		},
+	}
+	svc := NewService(*data.c, WithExecutionLoader(data.al))
+
+	go func() {
+		err := service.Start(ctx, svc)
+		require.NoError(t, err)
+	}()
+
+	id := state.Identifier{
+		WorkflowID: data.w.UUID,
+		RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+	}
+	_, err := data.sm.New(ctx, data.w, id, (event.Event{Name: "test"}).Map())
+	require.NoError(t, err)
+
+	err = data.q.Enqueue(ctx, queue.Item{
+		Kind:       queue.KindEdge,
+		Identifier: id,
+		Payload:    queue.PayloadEdge{Edge: inngest.SourceEdge},
+	}, time.Now())
+	require.NoError(t, err)
+
+	<-time.After(buffer)
+
+	// The failed step must only be finalized once.
+	run, err := data.sm.Load(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, run.Errors(), 1)
+	require.Equal(t, 0, run.Metadata().Pending)
+}
+
 // TestHandleAsync ensures correctness when hitting an async edge.  Technically,
 // once we hit an async edge we need to:
 //
@@ -364,3 +408,91 @@ func TestHandleAsyncService(t *testing.T) {
 	require.Equal(t, 0, run.Metadata().Pending)
 
 }
+
+func TestLifecycleUpdatesService(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data := prepare(ctx, t, syncF)
+	data.c.Execution.Drivers["mock"] = &mockdriver.Config{
+		Responses: map[string]state.DriverResponse{
+			"1": {Output: map[string]interface{}{"id": 1}},
+		},
+	}
+
+	data.c.EventStream.LifecycleTopic = "run-updates"
+
+	// Subscribe to lifecycle updates prior to starting the run.
+	hub := lifecycle.NewHub()
+	updates := hub.Subscribe(ctx, lifecycle.Filter{})
+	sub, err := pubsub.NewSubscriber(ctx, data.c.EventStream.Service)
+	require.NoError(t, err)
+	go func() {
+		_ = sub.Subscribe(ctx, data.c.EventStream.LifecycleTopic, hub.Handle)
+	}()
+	<-time.After(buffer)
+
+	svc := NewService(*data.c, WithExecutionLoader(data.al))
+	go func() {
+		err := service.Start(ctx, svc)
+		require.NoError(t, err)
+	}()
+
+	id := state.Identifier{
+		WorkflowID: data.w.UUID,
+		RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+	}
+	_, err = data.sm.New(ctx, data.w, id, (event.Event{Name: "test"}).Map())
+	require.NoError(t, err)
+
+	err = data.q.Enqueue(ctx, queue.Item{
+		Kind:       queue.KindEdge,
+		Identifier: id,
+		Payload:    queue.PayloadEdge{Edge: inngest.SourceEdge},
+	}, time.Now())
+	require.NoError(t, err)
+
+	// Updates are handled concurrently by subscribers, so they may be
+	// received out of order.  Collect every update until the run completes
+	// and no further updates are received.
+	received := map[lifecycle.Kind][]lifecycle.Update{}
+	deadline := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case u := <-updates:
+			require.Equal(t, id.RunID, u.RunID)
+			require.Equal(t, syncF.ID, u.FunctionID)
+			received[u.Kind] = append(received[u.Kind], u)
+		case <-time.After(timeout):
+			done = len(received[lifecycle.KindRunCompleted]) > 0
+		case <-deadline:
+			require.Fail(t, "run completed update not received")
+		}
+	}
+
+	require.Len(t, received[lifecycle.KindStepStarted], 3)
+	require.Len(t, received[lifecycle.KindStepFinished], 3)
+	require.Len(t, received[lifecycle.KindStepFailed], 0)
+	require.Len(t, received[lifecycle.KindRunCompleted], 1)
+	require.Equal(t, state.RunStatusCompleted, received[lifecycle.KindRunCompleted][0].Status)
+
+	for _, u := range received[lifecycle.KindStepFinished] {
+		if u.StepID == "1" {
+			require.EqualValues(t, map[string]interface{}{"id": float64(1)}, u.Output)
+		}
+	}
+}
+
+func TestFinished_noConsumers(t *testing.T) {
+	ctx := context.Background()
+	c, err := config.Default(ctx)
+	require.NoError(t, err)
+	p, err := pubsub.NewPublisher(ctx, c.EventStream.Service)
+	require.NoError(t, err)
+
+	// Without publisher subscriptions or a lifecycle topic, finished runs
+	// aren't published so the run's state must not be loaded.  The service
+	// has no state manager, so loading state would panic.
+	s := &svc{config: *c, pubsub: p, clock: clock.New()}
+	require.NoError(t, s.finished(ctx, state.Identifier{}))
+}
@@ -0,0 +1,164 @@
+// Package lifecycle defines the step and run lifecycle updates which executors
+// publish as functions run, allowing other services to follow runs live.
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// MessageName is the name of the pubsub message containing an Update.
+	MessageName = "run/lifecycle"
+
+	// hubBuffer is the number of updates buffered for each listener.
+	hubBuffer = 64
+)
+
+// Kind represents the type of lifecycle update.
+type Kind string
+
+const (
+	KindStepStarted  Kind = "step.started"
+	KindStepFinished Kind = "step.finished"
+	KindStepFailed   Kind = "step.failed"
+	KindRunCompleted Kind = "run.completed"
+)
+
+// Update is a single lifecycle update for a function run.
+type Update struct {
+	Kind       Kind      `json:"kind"`
+	FunctionID string    `json:"function_id"`
+	RunID      ulid.ULID `json:"run_id"`
+	// StepID is the step that this update refers to, for step updates.
+	StepID string `json:"step_id,omitempty"`
+	// Attempt is the zero-indexed attempt of the step, for step updates.
+	Attempt int `json:"attempt,omitempty"`
+	// Status is the final status of the run for run.completed updates.
+	Status state.RunStatus `json:"status,omitempty"`
+	// Output is the output of a finished step.
+	Output map[string]interface{} `json:"output,omitempty"`
+	// Error is the error of a failed step.
+	Error string    `json:"error,omitempty"`
+	At    time.Time `json:"at"`
+}
+
+// Message returns the update as a pubsub message.
+func (u Update) Message() (pubsub.Message, error) {
+	byt, err := json.Marshal(u)
+	if err != nil {
+		return pubsub.Message{}, err
+	}
+	return pubsub.Message{
+		Name:      MessageName,
+		Data:      string(byt),
+		Timestamp: u.At,
+	}, nil
+}
+
+// Parse returns the update stored within the given pubsub message.
+func Parse(m pubsub.Message) (Update, error) {
+	u := Update{}
+	if m.Name != MessageName {
+		return u, fmt.Errorf("unknown lifecycle message: %s", m.Name)
+	}
+	if err := json.Unmarshal([]byte(m.Data), &u); err != nil {
+		return u, fmt.Errorf("error decoding lifecycle update: %w", err)
+	}
+	return u, nil
+}
+
+// Filter selects the updates for a specific run or function.  Nil fields
+// match every update.
+type Filter struct {
+	RunID      *ulid.ULID
+	FunctionID *string
+}
+
+// Matches returns whether the update matches the filter.
+func (f Filter) Matches(u Update) bool {
+	if f.RunID != nil && *f.RunID != u.RunID {
+		return false
+	}
+	if f.FunctionID != nil && *f.FunctionID != u.FunctionID {
+		return false
+	}
+	return true
+}
+
+// Hub fans out updates received from the lifecycle topic to every listener
+// within this process.  Its Handle method is used as the subscription's
+// pubsub.PerformFunc.
+type Hub struct {
+	lock      sync.RWMutex
+	listeners map[*listener]struct{}
+}
+
+type listener struct {
+	filter Filter
+	ch     chan Update
+}
+
+func NewHub() *Hub {
+	return &Hub{listeners: map[*listener]struct{}{}}
+}
+
+// Subscribe returns a channel which receives every update matching the
+// filter until the given context is cancelled, after which the channel is
+// closed.
+//
+// Updates are dropped for listeners which fall too far behind, so that one
+// slow listener never blocks the hub.
+func (h *Hub) Subscribe(ctx context.Context, f Filter) <-chan Update {
+	l := &listener{filter: f, ch: make(chan Update, hubBuffer)}
+
+	h.lock.Lock()
+	h.listeners[l] = struct{}{}
+	h.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.lock.Lock()
+		delete(h.listeners, l)
+		close(l.ch)
+		h.lock.Unlock()
+	}()
+
+	return l.ch
+}
+
+// Handle broadcasts the update within the given message to all matching
+// listeners.  Messages which aren't lifecycle updates are ignored.
+func (h *Hub) Handle(ctx context.Context, m pubsub.Message) error {
+	if m.Name != MessageName {
+		return nil
+	}
+	u, err := Parse(m)
+	if err != nil {
+		return err
+	}
+	h.Broadcast(u)
+	return nil
+}
+
+// Broadcast sends the update to all matching listeners.
+func (h *Hub) Broadcast(u Update) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for l := range h.listeners {
+		if !l.filter.Matches(u) {
+			continue
+		}
+		select {
+		case l.ch <- u:
+		default:
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package lifecycle
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage(t *testing.T) {
+	u := Update{
+		Kind:       KindStepFinished,
+		FunctionID: "fn",
+		RunID:      ulid.MustNew(ulid.Now(), rand.Reader),
+		StepID:     "step",
+		Output:     map[string]interface{}{"ok": true},
+		At:         time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	m, err := u.Message()
+	require.NoError(t, err)
+	require.Equal(t, MessageName, m.Name)
+
+	parsed, err := Parse(m)
+	require.NoError(t, err)
+	require.Equal(t, u, parsed)
+
+	_, err = Parse(pubsub.Message{Name: "other"})
+	require.Error(t, err)
+}
+
+func TestHub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := NewHub()
+
+	runID := ulid.MustNew(ulid.Now(), rand.Reader)
+	fnID := "fn"
+	byRun := h.Subscribe(ctx, Filter{RunID: &runID})
+	byFn := h.Subscribe(ctx, Filter{FunctionID: &fnID})
+
+	other := Update{Kind: KindStepStarted, FunctionID: "other", RunID: ulid.MustNew(ulid.Now(), rand.Reader)}
+	started := Update{Kind: KindStepStarted, FunctionID: fnID, RunID: runID, StepID: "step"}
+
+	for _, u := range []Update{other, started} {
+		m, err := u.Message()
+		require.NoError(t, err)
+		require.NoError(t, h.Handle(ctx, m))
+	}
+
+	// Other messages on the topic are ignored.
+	require.NoError(t, h.Handle(ctx, pubsub.Message{Name: "run/run.finished"}))
+
+	require.Equal(t, started, <-byRun)
+	require.Equal(t, started, <-byFn)
+	require.Len(t, byRun, 0)
+	require.Len(t, byFn, 0)
+
+	// Cancelling the context closes the channel.
+	cancel()
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-byRun:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}
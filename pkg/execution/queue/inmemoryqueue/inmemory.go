@@ -5,8 +5,17 @@ import (
 	"sync"
 	"time"
 
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/config/registration"
 	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/logger"
+)
+
+const (
+	// retryDelay is the delay before redelivering items which errored.
+	retryDelay = time.Second
+
+	defaultMaxAttempts = 5
 )
 
 func init() {
@@ -24,6 +33,15 @@ type Config struct {
 	// we may have unexpected data within our in-memory queue during parallel
 	// tests.
 	mem *mem
+
+	// MaxAttempts is the number of times an item is attempted, including
+	// prior errors recorded within the item's ErrorCount, before it's
+	// dropped.
+	MaxAttempts int
+
+	// Clock is used to schedule enqueued items, defaulting to the system
+	// clock.  This must be set prior to creating the queue.
+	Clock clock.Clock `json:"-"`
 }
 
 func (c *Config) QueueName() string { return "inmemory" }
@@ -33,8 +51,19 @@ func (c *Config) Queue() (queue.Queue, error) {
 	defer c.l.Unlock()
 
 	if c.mem == nil {
+		clk := c.Clock
+		if clk == nil {
+			clk = clock.New()
+		}
+		maxAttempts := c.MaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = defaultMaxAttempts
+		}
 		c.mem = &mem{
-			q: make(chan queue.Item),
+			q:           make(chan queue.Item),
+			clock:       clk,
+			maxAttempts: maxAttempts,
+			pending:     map[uint64]time.Time{},
 		}
 	}
 
@@ -57,20 +86,67 @@ type MemoryQueue interface {
 	// Channel returns a channel which receives available jobs on the queue.
 	// This is helpful during testing.
 	Channel() chan queue.Item
+	// Idle returns whether no items are available or being processed, ie.
+	// every enqueued item is scheduled for the future.  This only accounts
+	// for items processed via Run, and is used to decide when a virtual clock
+	// can be advanced.
+	Idle() bool
 }
 
 type mem struct {
-	q chan queue.Item
+	q           chan queue.Item
+	clock       clock.Clock
+	maxAttempts int
+
+	lock sync.Mutex
+	// pending stores the time that each item which hasn't yet been delivered
+	// is scheduled for, keyed by a sequential ID.
+	pending map[uint64]time.Time
+	seq     uint64
+	// delivering is the number of items which are available but haven't yet
+	// been received by Run.
+	delivering int
+	// processing is the number of items being processed by Run.
+	processing int
 }
 
 func (m *mem) Enqueue(ctx context.Context, item queue.Item, at time.Time) error {
+	m.lock.Lock()
+	m.seq++
+	id := m.seq
+	m.pending[id] = at
+	m.lock.Unlock()
+
+	// Create the timer prior to returning so that virtual clocks advanced
+	// after enqueueing always fire the item.
+	after := m.clock.After(at.Sub(m.clock.Now()))
 	go func() {
-		<-time.After(time.Until(at))
+		<-after
+		m.lock.Lock()
+		delete(m.pending, id)
+		m.delivering++
+		m.lock.Unlock()
 		m.q <- item
 	}()
 	return nil
 }
 
+func (m *mem) Idle() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.delivering > 0 || m.processing > 0 {
+		return false
+	}
+	now := m.clock.Now()
+	for _, at := range m.pending {
+		if !at.After(now) {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *mem) Channel() chan queue.Item {
 	return m.q
 }
@@ -82,10 +158,35 @@ func (m *mem) Run(ctx context.Context, f func(context.Context, queue.Item) error
 			// We are shutting down.
 			return nil
 		case item := <-m.q:
+			m.lock.Lock()
+			m.delivering--
+			m.processing++
+			m.lock.Unlock()
+
 			if err := f(ctx, item); err != nil {
-				return err
+				m.retry(ctx, item, err)
 			}
+
+			m.lock.Lock()
+			m.processing--
+			m.lock.Unlock()
 		}
 
 	}
 }
+
+// retry redelivers an item which errored after a delay, so that items are
+// delivered at least once, dropping the item once it has been attempted the
+// maximum number of times.
+func (m *mem) retry(ctx context.Context, item queue.Item, err error) {
+	item.ErrorCount++
+	if item.ErrorCount >= m.maxAttempts {
+		logger.From(ctx).Error().
+			Err(err).
+			Str("run_id", item.Identifier.RunID.String()).
+			Int("attempts", item.ErrorCount).
+			Msg("dropping queue item after max attempts")
+		return
+	}
+	_ = m.Enqueue(ctx, item, m.clock.Now().Add(retryDelay))
+}
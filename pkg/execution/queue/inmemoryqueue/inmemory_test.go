@@ -0,0 +1,99 @@
+package inmemoryqueue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/clock"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/queue/testharness"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueHarness(t *testing.T) {
+	testharness.CheckQueue(t, func() (queue.Queue, func()) {
+		q, err := (&Config{}).Queue()
+		require.NoError(t, err)
+		return q, func() {}
+	})
+}
+
+func TestIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clk := clock.NewVirtual(time.Now())
+	q, err := (&Config{Clock: clk}).Queue()
+	require.NoError(t, err)
+	mq := q.(MemoryQueue)
+	require.True(t, mq.Idle())
+
+	release := make(chan struct{})
+	processed := make(chan queue.Item, 2)
+	go func() {
+		_ = q.Run(ctx, func(ctx context.Context, item queue.Item) error {
+			<-release
+			processed <- item
+			return nil
+		})
+	}()
+
+	// Items scheduled for the future leave the queue idle, until the clock
+	// reaches them.
+	require.NoError(t, q.Enqueue(ctx, queue.Item{}, clk.Now().Add(time.Hour)))
+	require.True(t, mq.Idle())
+
+	// Available items and items being processed aren't idle.
+	require.NoError(t, q.Enqueue(ctx, queue.Item{}, clk.Now()))
+	require.False(t, mq.Idle())
+	release <- struct{}{}
+	<-processed
+	require.Eventually(t, mq.Idle, time.Second, time.Millisecond)
+
+	clk.Advance(time.Hour)
+	require.False(t, mq.Idle())
+	release <- struct{}{}
+	<-processed
+	require.Eventually(t, mq.Idle, time.Second, time.Millisecond)
+}
+
+func TestMaxAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clk := clock.NewVirtual(time.Now())
+	q, err := (&Config{Clock: clk, MaxAttempts: 3}).Queue()
+	require.NoError(t, err)
+	mq := q.(MemoryQueue)
+
+	attempts := make(chan int, 10)
+	go func() {
+		_ = q.Run(ctx, func(ctx context.Context, item queue.Item) error {
+			attempts <- item.ErrorCount
+			return fmt.Errorf("handler error")
+		})
+	}()
+
+	// Items which already errored are attempted fewer times.
+	require.NoError(t, q.Enqueue(ctx, queue.Item{ErrorCount: 1}, clk.Now()))
+	for _, expected := range []int{1, 2} {
+		select {
+		case n := <-attempts:
+			require.Equal(t, expected, n)
+		case <-time.After(time.Second):
+			require.FailNow(t, "timed out waiting for queue item")
+		}
+		require.Eventually(t, mq.Idle, time.Second, time.Millisecond)
+		clk.Advance(retryDelay)
+	}
+
+	// The item is dropped after reaching the max attempts.
+	require.Eventually(t, mq.Idle, time.Second, time.Millisecond)
+	select {
+	case n := <-attempts:
+		require.FailNow(t, "item was redelivered after the max attempts", "error count: %d", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
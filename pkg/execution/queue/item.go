@@ -23,6 +23,10 @@ type Item struct {
 	Kind string `json:"kind"`
 	// Identifier represents the unique workflow ID and run ID for the current job.
 	Identifier state.Identifier `json:"identifier"`
+	// FunctionID is the ID of the function being run, allowing the item to be
+	// described without loading the run's state.  This may be empty for items
+	// enqueued without the function, eg. when resuming pauses.
+	FunctionID string `json:"functionID,omitempty"`
 	// ErrorCount stores the total number of errors that this job has currently procesed.
 	ErrorCount int `json:"errorCount"`
 	// Payload stores item-specific data for use when processing the item.  For example,
@@ -34,6 +38,7 @@ func (i *Item) UnmarshalJSON(b []byte) error {
 	type kind struct {
 		Kind       string           `json:"kind"`
 		Identifier state.Identifier `json:"identifier"`
+		FunctionID string           `json:"functionID"`
 		ErrorCount int              `json:"errorCount"`
 		Payload    json.RawMessage  `json:"payload"`
 	}
@@ -45,6 +50,7 @@ func (i *Item) UnmarshalJSON(b []byte) error {
 
 	i.Kind = temp.Kind
 	i.Identifier = temp.Identifier
+	i.FunctionID = temp.FunctionID
 	i.ErrorCount = temp.ErrorCount
 
 	switch temp.Kind {
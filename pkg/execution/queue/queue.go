@@ -21,5 +21,9 @@ type Consumer interface {
 	//
 	// This must only return an error if we fail to subscribe to the queue's
 	// implementation and can no longer process jobs.
+	//
+	// Items are delivered at least once:  if the given function returns an
+	// error the item must be redelivered.  When the context is cancelled, Run
+	// must wait for in-flight items to be processed then return nil.
 	Run(context.Context, func(context.Context, Item) error) error
 }
@@ -0,0 +1,201 @@
+package sqsqueue
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeSQS is a minimal, single queue SQS server implementing the query API
+// actions used by the queue, for testing without AWS.
+type fakeSQS struct {
+	*httptest.Server
+
+	l        sync.Mutex
+	messages []*fakeMessage
+}
+
+type fakeMessage struct {
+	id      string
+	body    string
+	visible time.Time
+	// receipt is the receipt handle for the latest receive.
+	receipt string
+}
+
+// visibilityTimeout is the duration that received messages are hidden for
+// until they're deleted or their visibility is changed.
+const visibilityTimeout = 30 * time.Second
+
+func newFakeSQS() *fakeSQS {
+	f := &fakeSQS{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeSQS) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var resp any
+	switch action := r.Form.Get("Action"); action {
+	case "SendMessage":
+		resp = f.send(r)
+	case "ReceiveMessage":
+		resp = f.receive(r)
+	case "DeleteMessageBatch":
+		resp = f.deleteBatch(r)
+	case "ChangeMessageVisibilityBatch":
+		resp = f.changeVisibilityBatch(r)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "unsupported action: %s", action)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	_ = xml.NewEncoder(w).Encode(resp)
+}
+
+type sendMessageResponse struct {
+	XMLName          xml.Name `xml:"SendMessageResponse"`
+	MD5OfMessageBody string   `xml:"SendMessageResult>MD5OfMessageBody"`
+	MessageId        string   `xml:"SendMessageResult>MessageId"`
+}
+
+func (f *fakeSQS) send(r *http.Request) any {
+	delay, _ := strconv.Atoi(r.Form.Get("DelaySeconds"))
+	m := &fakeMessage{
+		id:      uuid.NewString(),
+		body:    r.Form.Get("MessageBody"),
+		visible: time.Now().Add(time.Duration(delay) * time.Second),
+	}
+
+	f.l.Lock()
+	f.messages = append(f.messages, m)
+	f.l.Unlock()
+
+	return sendMessageResponse{MD5OfMessageBody: md5sum(m.body), MessageId: m.id}
+}
+
+type receiveMessageResponse struct {
+	XMLName  xml.Name         `xml:"ReceiveMessageResponse"`
+	Messages []receiveMessage `xml:"ReceiveMessageResult>Message"`
+}
+
+type receiveMessage struct {
+	MessageId     string
+	ReceiptHandle string
+	MD5OfBody     string
+	Body          string
+}
+
+func (f *fakeSQS) receive(r *http.Request) any {
+	max, _ := strconv.Atoi(r.Form.Get("MaxNumberOfMessages"))
+	if max == 0 {
+		max = 1
+	}
+
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	now := time.Now()
+	resp := receiveMessageResponse{}
+	for _, m := range f.messages {
+		if len(resp.Messages) == max {
+			break
+		}
+		if m.visible.After(now) {
+			continue
+		}
+		m.visible = now.Add(visibilityTimeout)
+		m.receipt = uuid.NewString()
+		resp.Messages = append(resp.Messages, receiveMessage{
+			MessageId:     m.id,
+			ReceiptHandle: m.receipt,
+			MD5OfBody:     md5sum(m.body),
+			Body:          m.body,
+		})
+	}
+	return resp
+}
+
+type deleteMessageBatchResponse struct {
+	XMLName xml.Name           `xml:"DeleteMessageBatchResponse"`
+	Entries []batchResultEntry `xml:"DeleteMessageBatchResult>DeleteMessageBatchResultEntry"`
+}
+
+type changeMessageVisibilityBatchResponse struct {
+	XMLName xml.Name           `xml:"ChangeMessageVisibilityBatchResponse"`
+	Entries []batchResultEntry `xml:"ChangeMessageVisibilityBatchResult>ChangeMessageVisibilityBatchResultEntry"`
+}
+
+type batchResultEntry struct {
+	Id string
+}
+
+// batchEntries returns the fields of each entry within a batch request.
+func batchEntries(r *http.Request, prefix string, fields ...string) []map[string]string {
+	entries := []map[string]string{}
+	for n := 1; ; n++ {
+		id := r.Form.Get(fmt.Sprintf("%s.%d.Id", prefix, n))
+		if id == "" {
+			return entries
+		}
+		e := map[string]string{"Id": id}
+		for _, field := range fields {
+			e[field] = r.Form.Get(fmt.Sprintf("%s.%d.%s", prefix, n, field))
+		}
+		entries = append(entries, e)
+	}
+}
+
+func (f *fakeSQS) deleteBatch(r *http.Request) any {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	resp := deleteMessageBatchResponse{}
+	for _, e := range batchEntries(r, "DeleteMessageBatchRequestEntry", "ReceiptHandle") {
+		for n, m := range f.messages {
+			if m.receipt == e["ReceiptHandle"] {
+				f.messages = append(f.messages[:n], f.messages[n+1:]...)
+				break
+			}
+		}
+		resp.Entries = append(resp.Entries, batchResultEntry{Id: e["Id"]})
+	}
+	return resp
+}
+
+func (f *fakeSQS) changeVisibilityBatch(r *http.Request) any {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	resp := changeMessageVisibilityBatchResponse{}
+	for _, e := range batchEntries(r, "ChangeMessageVisibilityBatchRequestEntry", "ReceiptHandle", "VisibilityTimeout") {
+		timeout, _ := strconv.Atoi(e["VisibilityTimeout"])
+		for _, m := range f.messages {
+			if m.receipt == e["ReceiptHandle"] {
+				m.visible = time.Now().Add(time.Duration(timeout) * time.Second)
+				break
+			}
+		}
+		resp.Entries = append(resp.Entries, batchResultEntry{Id: e["Id"]})
+	}
+	return resp
+}
+
+func md5sum(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
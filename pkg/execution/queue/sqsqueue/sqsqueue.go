@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"time"
 
@@ -172,9 +173,7 @@ func (i impl) Run(ctx context.Context, f func(context.Context, queue.Item) error
 			Interface("payload", w).
 			Msg("received step via sqs")
 
-		// SQS has second granularity, so truncate to the
-		// nearest second.
-		if w.At.Truncate(time.Second).After(time.Now()) {
+		if w.At.After(time.Now()) {
 			// Re-enqueue this at a future time.
 			return i.Enqueue(ctx, w.Item, w.At)
 		}
@@ -203,17 +202,15 @@ type Wrapper struct {
 }
 
 func (m Wrapper) DelaySeconds() *int64 {
-	diff := time.Until(m.At.Truncate(time.Second))
+	diff := time.Until(m.At)
 	if diff <= 0 {
 		return nil
 	}
 	if diff > 15*time.Minute {
 		return aws.Int64(15 * 60)
 	}
-	secs := int64(diff.Seconds())
-	if secs < 0 {
-		// Ensure that we always round up.
-		secs = 1
-	}
+	// SQS has second granularity, so always round up to ensure that
+	// messages are never received early.
+	secs := int64(math.Ceil(diff.Seconds()))
 	return &secs
 }
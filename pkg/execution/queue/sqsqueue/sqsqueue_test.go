@@ -0,0 +1,31 @@
+package sqsqueue
+
+import (
+	"testing"
+
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/queue/testharness"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueHarness(t *testing.T) {
+	// The fake SQS server uses plain HTTP and ignores credentials.  Custom
+	// CA bundles are unset, as the AWS SDK modifies the default HTTP client
+	// to load them.
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	testharness.CheckQueue(t, func() (queue.Queue, func()) {
+		sqs := newFakeSQS()
+		c := &Config{
+			Region:      "us-east-1",
+			QueueURL:    sqs.URL + "/000000000000/queue?endpoint=" + sqs.URL,
+			Topic:       "queue",
+			Concurrency: 10,
+		}
+		q, err := c.Queue()
+		require.NoError(t, err)
+		return q, sqs.Close
+	})
+}
@@ -0,0 +1,420 @@
+package testharness
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// tolerance is the maximum time after an item's scheduled time that the
+	// queue may deliver the item.  This accounts for queues which poll for
+	// items, or which schedule items with second granularity, such as SQS.
+	tolerance = 2 * time.Second
+
+	// timeout is the maximum time to wait for any item to be delivered.
+	timeout = 10 * time.Second
+)
+
+type Generator func() (q queue.Queue, cleanup func())
+
+// CheckQueue runs the conformance suite against queues created by the given
+// generator.  Each check uses a new queue.
+func CheckQueue(t *testing.T, gen Generator) {
+	t.Helper()
+
+	funcs := map[string]func(t *testing.T, q queue.Queue){
+		"Payloads":            checkPayloads,
+		"Enqueue/Past":        checkEnqueue_past,
+		"Enqueue/Future":      checkEnqueue_future,
+		"Enqueue/Order":       checkEnqueue_order,
+		"HandlerErrors":       checkHandlerErrors,
+		"Concurrent/Enqueue":  checkConcurrent_enqueue,
+		"Concurrent/Consumer": checkConcurrent_consumers,
+		"Shutdown":            checkShutdown,
+	}
+	for name, f := range funcs {
+		ok := t.Run(name, func(t *testing.T) {
+			q, cleanup := gen()
+			f(t, q)
+			cleanup()
+		})
+		require.True(t, ok, name)
+	}
+}
+
+// received is an item received by a consumer, plus the time it was received.
+type received struct {
+	item queue.Item
+	at   time.Time
+}
+
+// consumer runs the queue, recording each item received.  handler is invoked
+// for each item, and may be nil.
+type consumer struct {
+	items chan received
+	done  chan error
+
+	cancel func()
+}
+
+func consume(ctx context.Context, q queue.Queue, handler func(context.Context, queue.Item) error) *consumer {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &consumer{
+		items:  make(chan received, 1000),
+		done:   make(chan error, 1),
+		cancel: cancel,
+	}
+	go func() {
+		c.done <- q.Run(ctx, func(ctx context.Context, item queue.Item) error {
+			c.items <- received{item: item, at: time.Now()}
+			if handler != nil {
+				return handler(ctx, item)
+			}
+			return nil
+		})
+	}()
+	return c
+}
+
+// next returns the next item received, failing if no item is received within
+// the timeout.
+func (c *consumer) next(t *testing.T) received {
+	t.Helper()
+	select {
+	case r := <-c.items:
+		return r
+	case err := <-c.done:
+		require.FailNow(t, "queue stopped running", "error: %v", err)
+	case <-time.After(timeout):
+		require.FailNow(t, "timed out waiting for queue item")
+	}
+	return received{}
+}
+
+// none asserts that no items are received within the given duration.
+func (c *consumer) none(t *testing.T, d time.Duration) {
+	t.Helper()
+	select {
+	case r := <-c.items:
+		require.FailNow(t, "unexpected queue item", "%s", key(r.item))
+	case <-time.After(d):
+	}
+}
+
+// stop stops the consumer, asserting that Run returns no error.
+func (c *consumer) stop(t *testing.T) {
+	t.Helper()
+	c.cancel()
+	select {
+	case err := <-c.done:
+		require.NoError(t, err, "Run must not error when the context is cancelled")
+	case <-time.After(timeout):
+		require.FailNow(t, "Run didn't return after the context was cancelled")
+	}
+}
+
+func newIdentifier() state.Identifier {
+	runID := ulid.MustNew(ulid.Now(), rand.Reader)
+	return state.Identifier{
+		WorkflowID: uuid.New(),
+		RunID:      runID,
+		Key:        runID.String(),
+	}
+}
+
+// edgeItem returns a new item traversing an edge within a new run.
+func edgeItem() queue.Item {
+	return queue.Item{
+		Kind:       queue.KindEdge,
+		Identifier: newIdentifier(),
+		Payload: queue.PayloadEdge{
+			Edge: inngest.Edge{
+				Outgoing: inngest.TriggerName,
+				Incoming: "step-a",
+			},
+		},
+	}
+}
+
+// key returns the unique run key for the given item.
+func key(i queue.Item) string {
+	return i.Identifier.Key
+}
+
+// checkPayloads asserts that each kind of item is received as it was enqueued.
+func checkPayloads(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	wait := "1h"
+	match := "async.data.id == event.data.id"
+	items := []queue.Item{
+		{
+			Kind:       queue.KindEdge,
+			Identifier: newIdentifier(),
+			FunctionID: "some-fn",
+			ErrorCount: 2,
+			Payload: queue.PayloadEdge{
+				Edge: inngest.Edge{
+					Outgoing: "step-a",
+					Incoming: "step-b",
+					Metadata: &inngest.EdgeMetadata{
+						Name: "b",
+						If:   "steps['step-a'].ok == true",
+						Wait: &wait,
+						AsyncEdgeMetadata: &inngest.AsyncEdgeMetadata{
+							Event:     "test/some.event",
+							TTL:       "24h",
+							Match:     &match,
+							OnTimeout: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Kind:       queue.KindPause,
+			Identifier: newIdentifier(),
+			Payload: queue.PayloadPauseTimeout{
+				PauseID:   uuid.New(),
+				OnTimeout: true,
+			},
+		},
+	}
+
+	c := consume(ctx, q, nil)
+	defer c.stop(t)
+
+	expected := map[string]queue.Item{}
+	for _, i := range items {
+		expected[key(i)] = i
+		require.NoError(t, q.Enqueue(ctx, i, time.Now()))
+	}
+
+	for range items {
+		r := c.next(t)
+		require.Equal(t, expected[key(r.item)], r.item, "Received item does not match enqueued item")
+	}
+}
+
+// checkEnqueue_past asserts that items scheduled in the past are available
+// immediately.
+func checkEnqueue_past(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	c := consume(ctx, q, nil)
+	defer c.stop(t)
+
+	item := edgeItem()
+	enqueuedAt := time.Now()
+	require.NoError(t, q.Enqueue(ctx, item, enqueuedAt.Add(-time.Hour)))
+
+	r := c.next(t)
+	require.Equal(t, key(item), key(r.item))
+	require.WithinDuration(t, enqueuedAt, r.at, tolerance)
+}
+
+// checkEnqueue_future asserts that items scheduled in the future are never
+// delivered before their scheduled time.
+func checkEnqueue_future(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	c := consume(ctx, q, nil)
+	defer c.stop(t)
+
+	item := edgeItem()
+	at := time.Now().Add(1500 * time.Millisecond)
+	require.NoError(t, q.Enqueue(ctx, item, at))
+
+	r := c.next(t)
+	require.Equal(t, key(item), key(r.item))
+	require.False(t, r.at.Before(at), "Item was received %s before its scheduled time", at.Sub(r.at))
+	require.WithinDuration(t, at, r.at, tolerance)
+}
+
+// checkEnqueue_order asserts that items are delivered in the order they're
+// scheduled, regardless of the order they're enqueued.
+func checkEnqueue_order(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	c := consume(ctx, q, nil)
+	defer c.stop(t)
+
+	now := time.Now()
+	items := []queue.Item{edgeItem(), edgeItem(), edgeItem()}
+	for n := len(items) - 1; n >= 0; n-- {
+		require.NoError(t, q.Enqueue(ctx, items[n], now.Add(time.Duration(n)*time.Second)))
+	}
+
+	for n := range items {
+		r := c.next(t)
+		require.Equal(t, key(items[n]), key(r.item), "Item %d was received out of order", n)
+	}
+}
+
+// checkHandlerErrors asserts that items are redelivered after the handler
+// errors, and that errors don't stop the queue from processing other items.
+func checkHandlerErrors(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	failing, ok := edgeItem(), edgeItem()
+
+	l := sync.Mutex{}
+	attempts := map[string]int{}
+	c := consume(ctx, q, func(ctx context.Context, item queue.Item) error {
+		l.Lock()
+		defer l.Unlock()
+		attempts[key(item)]++
+		if key(item) == key(failing) && attempts[key(item)] == 1 {
+			return fmt.Errorf("handler error")
+		}
+		return nil
+	})
+	defer c.stop(t)
+
+	require.NoError(t, q.Enqueue(ctx, failing, time.Now()))
+	require.Equal(t, key(failing), key(c.next(t).item))
+
+	require.NoError(t, q.Enqueue(ctx, ok, time.Now()))
+
+	// Items are received in any order, as redelivery may be delayed.
+	seen := map[string]int{}
+	for i := 0; i < 2; i++ {
+		seen[key(c.next(t).item)]++
+	}
+	require.Equal(t, map[string]int{key(failing): 1, key(ok): 1}, seen)
+
+	// Items processed successfully are not redelivered.
+	c.none(t, time.Second)
+}
+
+// checkConcurrent_enqueue asserts that items enqueued concurrently are each
+// delivered.
+func checkConcurrent_enqueue(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	c := consume(ctx, q, nil)
+	defer c.stop(t)
+
+	items := make([]queue.Item, 50)
+	// require must only be called from the test's goroutine, so errors are
+	// collected and checked once every item is enqueued.
+	errs := make(chan error, len(items))
+	wg := sync.WaitGroup{}
+	for n := range items {
+		items[n] = edgeItem()
+		wg.Add(1)
+		go func(i queue.Item) {
+			defer wg.Done()
+			errs <- q.Enqueue(ctx, i, time.Now())
+		}(items[n])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	seen := map[string]struct{}{}
+	for range items {
+		k := key(c.next(t).item)
+		require.NotContains(t, seen, k, "Item was delivered more than once")
+		seen[k] = struct{}{}
+	}
+	for _, i := range items {
+		require.Contains(t, seen, key(i))
+	}
+}
+
+// checkConcurrent_consumers asserts that multiple consumers of the same queue
+// share items, with each item delivered to a single consumer.
+func checkConcurrent_consumers(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	items := make(chan received, 100)
+	handler := func(ctx context.Context, item queue.Item) error {
+		items <- received{item: item, at: time.Now()}
+		return nil
+	}
+	a := consume(ctx, q, handler)
+	defer a.stop(t)
+	b := consume(ctx, q, handler)
+	defer b.stop(t)
+
+	enqueued := map[string]struct{}{}
+	for n := 0; n < 20; n++ {
+		i := edgeItem()
+		enqueued[key(i)] = struct{}{}
+		require.NoError(t, q.Enqueue(ctx, i, time.Now()))
+	}
+
+	seen := map[string]struct{}{}
+	for range enqueued {
+		select {
+		case r := <-items:
+			k := key(r.item)
+			require.Contains(t, enqueued, k)
+			require.NotContains(t, seen, k, "Item was delivered to more than one consumer")
+			seen[k] = struct{}{}
+		case <-time.After(timeout):
+			require.FailNow(t, "timed out waiting for queue items")
+		}
+	}
+}
+
+// checkShutdown asserts that Run waits for in-flight items to be processed
+// after its context is cancelled, and that items enqueued after shutdown are
+// delivered to the next consumer.
+func checkShutdown(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	c := consume(ctx, q, func(ctx context.Context, item queue.Item) error {
+		<-release
+		close(finished)
+		return nil
+	})
+
+	first := edgeItem()
+	require.NoError(t, q.Enqueue(ctx, first, time.Now()))
+	require.Equal(t, key(first), key(c.next(t).item))
+
+	c.cancel()
+	select {
+	case err := <-c.done:
+		require.FailNow(t, "Run returned prior to in-flight items finishing", "error: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-c.done:
+		require.NoError(t, err, "Run must not error when the context is cancelled")
+	case <-time.After(timeout):
+		require.FailNow(t, "Run didn't return after in-flight items finished")
+	}
+	select {
+	case <-finished:
+	default:
+		require.FailNow(t, "Run returned prior to in-flight items finishing")
+	}
+
+	second := edgeItem()
+	require.NoError(t, q.Enqueue(ctx, second, time.Now()))
+
+	next := consume(ctx, q, nil)
+	defer next.stop(t)
+	require.Equal(t, key(second), key(next.next(t).item), "Items processed before shutdown must not be redelivered")
+	next.none(t, time.Second)
+}
@@ -0,0 +1,225 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/logger"
+)
+
+const (
+	// backfillPageSize is the number of events loaded from the event store
+	// at once when backfilling.
+	backfillPageSize = 100
+)
+
+// BackfillOpts configures a backfill.
+type BackfillOpts struct {
+	// Function is the function to backfill.
+	Function function.Function
+	// From and To specify the time range of events to backfill, based off of
+	// when each event was received.  From is inclusive and To is exclusive.
+	From time.Time
+	To   time.Time
+	// Rate limits the number of runs initialized per second.  A rate of 0
+	// disables rate limiting.
+	Rate float64
+	// DryRun counts the matching events without initializing any runs.
+	DryRun bool
+
+	Events coredata.EventStore
+	State  state.Manager
+	Queue  queue.Producer
+}
+
+// BackfillResult reports the outcome of a backfill.
+type BackfillResult struct {
+	// Matched is the number of events which matched the function's triggers.
+	Matched int `json:"matched"`
+	// Initialized is the number of new runs created.
+	Initialized int `json:"initialized"`
+	// Skipped is the number of matching events which already have a run for
+	// the function, eg. from a previous backfill.
+	Skipped int `json:"skipped"`
+	// Failed is the number of matching events for which a run couldn't be
+	// started.  The backfill continues after failures, which are logged.
+	Failed int `json:"failed"`
+}
+
+// Backfill streams stored events received within the given time range through
+// the function's triggers, initializing a new run for each matching event.
+//
+// Backfills are idempotent.  Each run uses the event's ID as its idempotency
+// key, and events which already have a recorded run for the function are
+// skipped;  re-running a backfill never duplicates runs.
+func Backfill(ctx context.Context, opts BackfillOpts) (BackfillResult, error) {
+	result := BackfillResult{}
+
+	if opts.Events == nil {
+		return result, fmt.Errorf("no event store provided")
+	}
+	if !opts.DryRun && (opts.State == nil || opts.Queue == nil) {
+		return result, fmt.Errorf("a state manager and queue are required")
+	}
+	if !opts.To.After(opts.From) {
+		return result, fmt.Errorf("the backfill end time must be after the start time")
+	}
+
+	var limit <-chan time.Time
+	if opts.Rate > 0 && !opts.DryRun {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		limit = ticker.C
+	}
+
+	// Each event trigger has its own name;  query events for each name
+	// separately, ensuring we only visit each event once.
+	seen := map[string]struct{}{}
+	for _, t := range opts.Function.Triggers {
+		if t.EventTrigger == nil {
+			continue
+		}
+
+		err := streamEvents(ctx, opts.Events, t.Event, opts.From, opts.To, func(evt coredata.Event) error {
+			if _, ok := seen[evt.ID]; ok {
+				return nil
+			}
+			seen[evt.ID] = struct{}{}
+
+			ok, err := triggered(ctx, opts.Function, evt.Event, evt.Event.Map())
+			if err != nil {
+				logger.From(ctx).Warn().Err(err).Str("event_id", evt.ID).Msg("error evaluating trigger")
+			}
+			if !ok {
+				return nil
+			}
+
+			result.Matched++
+
+			for _, r := range evt.Runs {
+				if r.FunctionID == opts.Function.ID {
+					result.Skipped++
+					return nil
+				}
+			}
+
+			if opts.DryRun {
+				return nil
+			}
+
+			if limit != nil {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-limit:
+				}
+			}
+
+			id, err := Initialize(ctx, opts.Function, evt.Event, opts.State, opts.Queue)
+			if errors.Is(err, state.ErrIdentifierExists) {
+				result.Skipped++
+				return nil
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// The run's state may have been created without the run being
+				// enqueued;  log the run ID so that it can be inspected.
+				l := logger.From(ctx).Error().Err(err).Str("event_id", evt.ID)
+				if id != nil {
+					l = l.Str("run_id", id.RunID.String())
+				}
+				l.Msg("error initializing backfilled run")
+				result.Failed++
+				return nil
+			}
+
+			result.Initialized++
+			// Record the run against the event so that the event log reflects
+			// the backfill, and so that future backfills skip this event.
+			serr := opts.Events.SaveEvent(ctx, coredata.Event{
+				ID:         evt.ID,
+				Name:       evt.Name,
+				Event:      evt.Event,
+				ReceivedAt: evt.ReceivedAt,
+				Runs: []coredata.EventRun{
+					{FunctionID: opts.Function.ID, Identifier: *id},
+				},
+			})
+			if serr != nil {
+				logger.From(ctx).Error().Err(serr).Str("event_id", evt.ID).Msg("error saving backfilled run")
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// streamEvents pages through all events with the given name received within
+// the time range, calling f for each event.
+func streamEvents(ctx context.Context, es coredata.EventReader, name string, from, to time.Time, f func(coredata.Event) error) error {
+	// Events are returned newest first.  Page backwards by moving the end
+	// of the range to the oldest event seen.  The range's end is exclusive,
+	// so events which share the oldest timestamp are re-queried by
+	// including that timestamp and ignoring events we've already visited.
+	visited := map[string]struct{}{}
+	end := to
+	inclusive := false
+
+	for {
+		queryEnd := end
+		if inclusive {
+			queryEnd = end.Add(time.Nanosecond)
+		}
+
+		events, err := es.Events(ctx, coredata.EventQuery{
+			Name:  &name,
+			From:  &from,
+			To:    &queryEnd,
+			Limit: backfillPageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		found := 0
+		for _, evt := range events {
+			if _, ok := visited[evt.ID]; ok {
+				continue
+			}
+			visited[evt.ID] = struct{}{}
+			found++
+			if err := f(evt); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < backfillPageSize {
+			return nil
+		}
+
+		oldest := events[len(events)-1].ReceivedAt
+		switch {
+		case found == 0 && inclusive && oldest.Equal(end):
+			// Every event in this page shares the same timestamp and has
+			// been visited.  Move past the timestamp entirely.
+			inclusive = false
+		case found == 0 && !inclusive:
+			return nil
+		default:
+			end = oldest
+			inclusive = true
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package runner
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/coredata"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/state/inmemory"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// producer records every item enqueued.
+type producer struct {
+	lock  sync.Mutex
+	items []queue.Item
+}
+
+func (p *producer) Enqueue(ctx context.Context, item queue.Item, at time.Time) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.items = append(p.items, item)
+	return nil
+}
+
+func (p *producer) len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.items)
+}
+
+// failingProducer errors on every enqueue.
+type failingProducer struct{}
+
+func (failingProducer) Enqueue(ctx context.Context, item queue.Item, at time.Time) error {
+	return fmt.Errorf("queue unavailable")
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+
+	expr := "event.data.ok == true"
+	fn := function.Function{
+		ID:   "backfill-fn",
+		Name: "backfill",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/backfill", Expression: &expr}},
+		},
+		Steps: map[string]function.Step{
+			"first": {
+				ID:      "first",
+				Name:    "first",
+				Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+				After:   []function.After{{Step: inngest.TriggerName}},
+			},
+		},
+	}
+
+	es := inmemorydatastore.NewInMemoryEventStore()
+	now := time.Now()
+
+	// Store more events than a single page, with half matching the
+	// expression.  Many events share timestamps, to ensure that paging
+	// visits each event exactly once.
+	total := backfillPageSize*2 + 10
+	for i := 0; i < total; i++ {
+		at := now.Add(-time.Duration(i/7) * time.Second)
+		id := ulid.MustNew(ulid.Timestamp(at), rand.Reader).String()
+		evt := event.Event{
+			ID:   id,
+			Name: "test/backfill",
+			Data: map[string]interface{}{"ok": i%2 == 0},
+		}
+		require.NoError(t, es.SaveEvent(ctx, coredata.Event{
+			ID:         id,
+			Name:       evt.Name,
+			Event:      evt,
+			ReceivedAt: at,
+		}))
+	}
+	// An event outside of the range is ignored.
+	old := event.Event{ID: "old", Name: "test/backfill", Data: map[string]interface{}{"ok": true}}
+	require.NoError(t, es.SaveEvent(ctx, coredata.Event{
+		ID:         old.ID,
+		Name:       old.Name,
+		Event:      old,
+		ReceivedAt: now.Add(-48 * time.Hour),
+	}))
+
+	matching := (total + 1) / 2
+	opts := BackfillOpts{
+		Function: fn,
+		From:     now.Add(-time.Hour),
+		To:       now.Add(time.Second),
+		Events:   es,
+		State:    inmemory.NewStateManager(),
+		Queue:    &producer{},
+	}
+
+	t.Run("runs which fail to start are reported separately", func(t *testing.T) {
+		failing := opts
+		failing.State = inmemory.NewStateManager()
+		failing.Queue = failingProducer{}
+		res, err := Backfill(ctx, failing)
+		require.NoError(t, err)
+		require.Equal(t, BackfillResult{Matched: matching, Failed: matching}, res)
+	})
+
+	t.Run("dry runs count matching events", func(t *testing.T) {
+		dry := opts
+		dry.DryRun = true
+		res, err := Backfill(ctx, dry)
+		require.NoError(t, err)
+		require.Equal(t, BackfillResult{Matched: matching}, res)
+		require.Equal(t, 0, opts.Queue.(*producer).len())
+	})
+
+	t.Run("it initializes runs for matching events", func(t *testing.T) {
+		res, err := Backfill(ctx, opts)
+		require.NoError(t, err)
+		require.Equal(t, BackfillResult{Matched: matching, Initialized: matching}, res)
+		require.Equal(t, matching, opts.Queue.(*producer).len())
+	})
+
+	t.Run("re-running a backfill does not duplicate runs", func(t *testing.T) {
+		res, err := Backfill(ctx, opts)
+		require.NoError(t, err)
+		require.Equal(t, BackfillResult{Matched: matching, Skipped: matching}, res)
+		require.Equal(t, matching, opts.Queue.(*producer).len())
+	})
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"github.com/inngest/inngest/pkg/clock"
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser parses standard five-field cron expressions.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// scheduler invokes functions on cron schedules using the given clock, so
+// that virtual clocks can fast-forward through schedules.  robfig/cron is only
+// used to parse schedules:  its runner reads the system time directly, so
+// can't be driven by a virtual clock.
+//
+// Jobs are scheduled from the clock's time after each invocation.  If the
+// clock moves past multiple invocations at once, the job runs once.
+type scheduler struct {
+	clock clock.Clock
+	jobs  []cronJob
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+type cronJob struct {
+	schedule cron.Schedule
+	f        func()
+}
+
+func newScheduler(c clock.Clock) *scheduler {
+	return &scheduler{clock: c, stop: make(chan struct{})}
+}
+
+// AddFunc adds a function to invoke on the given cron schedule.  Functions
+// must be added prior to starting the scheduler.
+func (s *scheduler) AddFunc(spec string, f func()) error {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return err
+	}
+	s.jobs = append(s.jobs, cronJob{schedule: schedule, f: f})
+	return nil
+}
+
+// Entries returns the scheduled jobs.
+func (s *scheduler) Entries() []cronJob {
+	return s.jobs
+}
+
+// Start invokes each job on its schedule until the scheduler is stopped.
+func (s *scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.run(j)
+	}
+}
+
+func (s *scheduler) run(j cronJob) {
+	defer s.wg.Done()
+	for {
+		now := s.clock.Now()
+		timer := s.clock.NewTimer(j.schedule.Next(now).Sub(now))
+		select {
+		case <-s.stop:
+			// Release the timer, which may otherwise wait hours to fire.
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+		j.f()
+	}
+}
+
+// Stop stops the scheduler, returning a context which is done once any running
+// jobs have finished.
+func (s *scheduler) Stop() context.Context {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		s.wg.Wait()
+		cancel()
+	}()
+	return ctx
+}
@@ -0,0 +1,170 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/clock"
+	"github.com/inngest/inngest/pkg/config"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadCrons(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduled := func(id, cron string) *function.Function {
+		return &function.Function{
+			ID:       id,
+			Name:     id,
+			Triggers: []function.Trigger{{CronTrigger: &function.CronTrigger{Cron: cron}}},
+			Steps: map[string]function.Step{
+				"first": {
+					ID:      "first",
+					Name:    "first",
+					Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+					After:   []function.After{{Step: inngest.TriggerName}},
+				},
+			},
+		}
+	}
+
+	loader := &inmemorydatastore.MemoryExecutionLoader{}
+	require.NoError(t, loader.SetFunctions(ctx, []*function.Function{
+		scheduled("a", "0 * * * *"),
+	}))
+
+	s := &svc{data: loader, reloadInterval: 10 * time.Millisecond, clock: clock.New()}
+	require.NoError(t, s.initializeCrons(ctx))
+	require.Len(t, s.cronmanager.Entries(), 1)
+
+	// Unchanged functions keep the existing schedule.
+	prev := s.cronmanager
+	require.NoError(t, s.initializeCrons(ctx))
+	require.Same(t, prev, s.cronmanager)
+
+	go s.reloadCrons(ctx)
+
+	entries := func() int {
+		s.cronlock.Lock()
+		defer s.cronlock.Unlock()
+		return len(s.cronmanager.Entries())
+	}
+
+	// Deploying a new cron function adds it to the schedule.
+	require.NoError(t, loader.SetFunctions(ctx, []*function.Function{
+		scheduled("a", "0 * * * *"),
+		scheduled("b", "30 * * * *"),
+	}))
+	require.Eventually(t, func() bool { return entries() == 2 }, time.Second, 10*time.Millisecond)
+
+	// Removing cron triggers removes them from the schedule.
+	require.NoError(t, loader.SetFunctions(ctx, []*function.Function{}))
+	require.Eventually(t, func() bool { return entries() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestScheduler_virtualClock(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewVirtual(start)
+
+	s := newScheduler(clk)
+	require.Error(t, s.AddFunc("not a cron", func() {}))
+
+	fired := make(chan time.Time, 1)
+	require.NoError(t, s.AddFunc("0 * * * *", func() { fired <- clk.Now() }))
+	s.Start()
+
+	scheduledFor := func(at time.Time) func() bool {
+		return func() bool {
+			next, ok := clk.Next()
+			return ok && next.Equal(at)
+		}
+	}
+
+	// Advancing the clock to the next hour fires the job, which then waits
+	// for the following hour.
+	require.Eventually(t, scheduledFor(start.Add(time.Hour)), time.Second, time.Millisecond)
+	clk.Advance(time.Hour)
+	require.Equal(t, start.Add(time.Hour), <-fired)
+
+	require.Eventually(t, scheduledFor(start.Add(2*time.Hour)), time.Second, time.Millisecond)
+	clk.Advance(30 * time.Minute)
+	select {
+	case <-fired:
+		t.Fatal("job fired before its schedule")
+	default:
+	}
+
+	select {
+	case <-s.Stop().Done():
+	case <-time.After(time.Second):
+		t.Fatal("scheduler didn't stop")
+	}
+
+	// Stopping the scheduler releases its pending timers.
+	_, ok := clk.Next()
+	require.False(t, ok)
+}
+
+func TestReloadCrons_deploy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data, err := inmemorydatastore.New(ctx)
+	require.NoError(t, err)
+
+	fn := function.Function{
+		ID:       "fn",
+		Name:     "fn",
+		Triggers: []function.Trigger{{CronTrigger: &function.CronTrigger{Cron: "0 * * * *"}}},
+		Steps: map[string]function.Step{
+			"first": {
+				ID:      "first",
+				Name:    "first",
+				Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+				After:   []function.After{{Step: inngest.TriggerName}},
+			},
+		},
+	}
+	_, err = data.CreateFunctionVersion(ctx, fn, true, "prod")
+	require.NoError(t, err)
+
+	s := NewService(config.Config{}, WithExecutionLoader(data), WithReloadInterval(10*time.Millisecond)).(*svc)
+	require.NoError(t, s.initializeCrons(ctx))
+	go s.reloadCrons(ctx)
+
+	// next returns the next time that each scheduled function runs.
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := func() []time.Time {
+		s.cronlock.Lock()
+		defer s.cronlock.Unlock()
+		times := []time.Time{}
+		for _, j := range s.cronmanager.Entries() {
+			times = append(times, j.schedule.Next(base))
+		}
+		return times
+	}
+	hourly := []time.Time{base.Add(time.Hour)}
+	halfHourly := []time.Time{base.Add(30 * time.Minute)}
+	require.Equal(t, hourly, next())
+
+	// Deploying a new version replaces the schedule.
+	fn.Triggers = []function.Trigger{{CronTrigger: &function.CronTrigger{Cron: "30 * * * *"}}}
+	_, err = data.CreateFunctionVersion(ctx, fn, true, "prod")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(halfHourly, next())
+	}, time.Second, 10*time.Millisecond)
+
+	// Rolling back restores the previous schedule.
+	_, err = data.RollbackFunction(ctx, fn.ID, 1)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(hourly, next())
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,328 @@
+package runner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/clock"
+	"github.com/inngest/inngest/pkg/config"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/execution/queue"
+	"github.com/inngest/inngest/pkg/execution/queue/inmemoryqueue"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/execution/state/inmemory"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctions_validation(t *testing.T) {
+	ctx := context.Background()
+
+	fns := []*function.Function{}
+	for _, id := range []string{"valid-fn", "invalid-fn"} {
+		fns = append(fns, &function.Function{
+			ID:   id,
+			Name: id,
+			Triggers: []function.Trigger{
+				{EventTrigger: &function.EventTrigger{Event: "test/validation"}},
+			},
+			Steps: map[string]function.Step{
+				"first": {
+					ID:      "first",
+					Name:    "first",
+					Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+					After:   []function.After{{Step: inngest.TriggerName}},
+				},
+			},
+		})
+	}
+	loader := &inmemorydatastore.MemoryExecutionLoader{}
+	require.NoError(t, loader.SetFunctions(ctx, fns))
+
+	q, err := (&inmemoryqueue.Config{}).Queue()
+	require.NoError(t, err)
+
+	evt := event.Event{
+		ID:   "evt",
+		Name: "test/validation",
+		Metadata: map[string]interface{}{
+			event.MetadataValidationErrors: map[string]interface{}{
+				"invalid-fn": "event does not match definition",
+			},
+		},
+	}
+
+	for _, mode := range []config.ValidationMode{config.ValidationWarn, config.ValidationDrop} {
+		t.Run(string(mode), func(t *testing.T) {
+			c := config.Config{}
+			c.EventAPI.Validation = mode
+			s := &svc{
+				config: c,
+				data:   loader,
+				state:  inmemory.NewStateManager(),
+				queue:  q,
+				clock:  clock.New(),
+			}
+
+			runs, err := s.functions(ctx, evt)
+			require.NoError(t, err)
+
+			ids := []string{}
+			for _, r := range runs {
+				ids = append(ids, r.FunctionID)
+			}
+			if mode == config.ValidationDrop {
+				require.Equal(t, []string{"valid-fn"}, ids)
+				return
+			}
+			require.ElementsMatch(t, []string{"valid-fn", "invalid-fn"}, ids)
+		})
+	}
+}
+
+// recordingQueue records the time that each item is enqueued for.
+type recordingQueue struct {
+	queue.Queue
+
+	lock sync.Mutex
+	at   []time.Time
+}
+
+func (r *recordingQueue) Enqueue(ctx context.Context, item queue.Item, at time.Time) error {
+	r.lock.Lock()
+	r.at = append(r.at, at)
+	r.lock.Unlock()
+	return r.Queue.Enqueue(ctx, item, at)
+}
+
+func TestFunctions_delayed(t *testing.T) {
+	ctx := context.Background()
+
+	fn := &function.Function{
+		ID:   "delayed-fn",
+		Name: "delayed-fn",
+		Triggers: []function.Trigger{
+			{EventTrigger: &function.EventTrigger{Event: "test/delayed"}},
+		},
+		Steps: map[string]function.Step{
+			"first": {
+				ID:      "first",
+				Name:    "first",
+				Runtime: inngest.RuntimeWrapper{Runtime: inngest.RuntimeDocker{}},
+				After:   []function.After{{Step: inngest.TriggerName}},
+			},
+		},
+	}
+	loader := &inmemorydatastore.MemoryExecutionLoader{}
+	require.NoError(t, loader.SetFunctions(ctx, []*function.Function{fn}))
+
+	q, err := (&inmemoryqueue.Config{}).Queue()
+	require.NoError(t, err)
+	rq := &recordingQueue{Queue: q}
+
+	c := config.Config{}
+	c.Execution.MaxDelay = time.Hour
+	sm := inmemory.NewStateManager()
+	clk := clock.NewVirtual(time.Now().Truncate(time.Millisecond))
+	s := &svc{
+		config: c,
+		data:   loader,
+		state:  sm,
+		queue:  rq,
+		clock:  clk,
+	}
+
+	ts := clk.Now().Add(10 * time.Minute)
+	runs, err := s.functions(ctx, event.Event{
+		ID:        "evt",
+		Name:      "test/delayed",
+		Timestamp: ts.UnixMilli(),
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	require.Len(t, rq.at, 1)
+	require.True(t, ts.Equal(rq.at[0]), "source edge should be enqueued at the event's timestamp")
+
+	run, err := sm.Load(ctx, runs[0].Identifier)
+	require.NoError(t, err)
+	require.True(t, ts.Equal(run.Metadata().ScheduledAt), "run metadata should contain the scheduled start")
+}
+
+// recordingPubSub records the topic of each message published.
+type recordingPubSub struct {
+	pubsub.Subscriber
+
+	lock   sync.Mutex
+	topics []string
+	// err is returned from each publish.
+	err error
+}
+
+func (r *recordingPubSub) Publish(ctx context.Context, topic string, m pubsub.Message) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.topics = append(r.topics, topic)
+	return r.err
+}
+
+func TestHandleMessage_publisher(t *testing.T) {
+	ctx := context.Background()
+
+	q, err := (&inmemoryqueue.Config{}).Queue()
+	require.NoError(t, err)
+
+	byt, err := json.Marshal(event.Event{ID: "evt", Name: "test/publish"})
+	require.NoError(t, err)
+	m := pubsub.Message{Name: event.EventReceivedName, Data: string(byt)}
+
+	for _, subs := range [][]config.Subscription{nil, {{ID: "sub", URL: "http://localhost", Event: "*"}}} {
+		c := config.Config{}
+		c.Publisher.Topic = "publisher"
+		c.Publisher.Subscriptions = subs
+		ps := &recordingPubSub{}
+		s := &svc{
+			config: c,
+			pubsub: ps,
+			data:   &inmemorydatastore.MemoryExecutionLoader{},
+			state:  inmemory.NewStateManager(),
+			queue:  q,
+			clock:  clock.New(),
+		}
+
+		require.NoError(t, s.handleMessage(ctx, m))
+		if len(subs) == 0 {
+			require.Empty(t, ps.topics, "Events must only be forwarded if the publisher has subscriptions")
+			continue
+		}
+		require.Equal(t, []string{"publisher"}, ps.topics)
+
+		// Forwarding errors must not redeliver the message, which would
+		// initialize functions again.
+		ps.err = fmt.Errorf("publish error")
+		require.NoError(t, s.handleMessage(ctx, m))
+	}
+}
+
+func TestScheduledAt(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+
+	tests := []struct {
+		name     string
+		ts       time.Time
+		maxDelay time.Duration
+		expected time.Time
+	}{
+		{
+			name:     "no timestamp",
+			maxDelay: time.Hour,
+			expected: now,
+		},
+		{
+			name:     "past timestamp",
+			ts:       now.Add(-time.Minute),
+			maxDelay: time.Hour,
+			expected: now,
+		},
+		{
+			name:     "future timestamp",
+			ts:       now.Add(time.Minute),
+			maxDelay: time.Hour,
+			expected: now.Add(time.Minute),
+		},
+		{
+			name:     "past the max delay",
+			ts:       now.Add(48 * time.Hour),
+			maxDelay: time.Hour,
+			expected: now.Add(time.Hour),
+		},
+		{
+			name:     "delays disabled",
+			ts:       now.Add(time.Minute),
+			expected: now,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			evt := event.Event{}
+			if !test.ts.IsZero() {
+				evt.Timestamp = test.ts.UnixMilli()
+			}
+			actual := scheduledAt(evt, now, test.maxDelay)
+			require.True(t, test.expected.Equal(actual), "expected %s, got %s", test.expected, actual)
+		})
+	}
+}
+
+func TestResumePause(t *testing.T) {
+	ctx := context.Background()
+	sm := inmemory.NewStateManager()
+	q := &producer{}
+
+	w := inngest.Workflow{
+		UUID: uuid.New(),
+		Steps: []inngest.Step{
+			{ID: "first", Name: "First"},
+			{ID: "second", Name: "Second"},
+		},
+	}
+	id := state.Identifier{WorkflowID: w.UUID, RunID: ulid.MustNew(ulid.Now(), rand.Reader)}
+	_, err := sm.New(ctx, w, id, map[string]any{"name": "test/event"})
+	require.NoError(t, err)
+
+	async := map[string]interface{}{"name": "test/async", "data": map[string]interface{}{"ok": true}}
+
+	t.Run("it stores the async event and enqueues the incoming step", func(t *testing.T) {
+		pause := state.Pause{ID: uuid.New(), Identifier: id, Outgoing: "first", Incoming: "second", Expires: time.Now().Add(time.Hour)}
+		require.NoError(t, sm.SavePause(ctx, pause))
+
+		resumed, err := ResumePause(ctx, pause, async, sm, q)
+		require.NoError(t, err)
+		require.True(t, resumed)
+		require.Equal(t, 1, q.len())
+
+		s, err := sm.Load(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, async, s.Actions()[state.AsyncID("second")])
+		require.False(t, s.ActionComplete("second"), "The incoming step must still run")
+
+		_, err = sm.PauseByID(ctx, pause.ID)
+		require.ErrorIs(t, err, state.ErrPauseNotFound)
+	})
+
+	t.Run("it consumes timeout pauses without enqueueing", func(t *testing.T) {
+		pause := state.Pause{ID: uuid.New(), Identifier: id, Outgoing: "first", Incoming: "second", Expires: time.Now().Add(time.Hour), OnTimeout: true}
+		require.NoError(t, sm.SavePause(ctx, pause))
+
+		before := q.len()
+		resumed, err := ResumePause(ctx, pause, async, sm, q)
+		require.NoError(t, err)
+		require.False(t, resumed)
+		require.Equal(t, before, q.len())
+
+		_, err = sm.PauseByID(ctx, pause.ID)
+		require.ErrorIs(t, err, state.ErrPauseNotFound)
+	})
+
+	t.Run("it doesn't modify leased pauses", func(t *testing.T) {
+		pause := state.Pause{ID: uuid.New(), Identifier: id, Outgoing: "first", Incoming: "second", Expires: time.Now().Add(time.Hour), OnTimeout: true}
+		require.NoError(t, sm.SavePause(ctx, pause))
+		require.NoError(t, sm.LeasePause(ctx, pause.ID))
+
+		_, err := ResumePause(ctx, pause, async, sm, q)
+		require.ErrorIs(t, err, state.ErrPauseLeased)
+		_, err = sm.PauseByID(ctx, pause.ID)
+		require.NoError(t, err)
+	})
+}
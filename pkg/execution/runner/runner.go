@@ -4,14 +4,18 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
 	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/config"
 	"github.com/inngest/inngest/pkg/coredata"
 	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
@@ -24,7 +28,6 @@ import (
 	"github.com/inngest/inngest/pkg/pubsub"
 	"github.com/inngest/inngest/pkg/service"
 	"github.com/oklog/ulid/v2"
-	"github.com/robfig/cron/v3"
 )
 
 type Opt func(s *svc)
@@ -35,8 +38,39 @@ func WithExecutionLoader(l coredata.ExecutionLoader) func(s *svc) {
 	}
 }
 
+// WithEventStore sets the store used to record received events and the runs
+// that they initialize.  If this isn't provided, the configured data store is
+// used.
+func WithEventStore(e coredata.EventWriter) func(s *svc) {
+	return func(s *svc) {
+		s.events = e
+	}
+}
+
+// WithReloadInterval sets how often the runner checks for changes to scheduled
+// functions, rebuilding its cron schedule when functions are deployed or removed.
+// If zero, functions are never reloaded.
+func WithReloadInterval(d time.Duration) func(s *svc) {
+	return func(s *svc) {
+		s.reloadInterval = d
+	}
+}
+
+// WithClock sets the clock used to schedule function runs, fire cron triggers
+// and check whether pauses have expired.  If this isn't provided, the system
+// clock is used.
+func WithClock(c clock.Clock) func(s *svc) {
+	return func(s *svc) {
+		s.clock = c
+	}
+}
+
+// DefaultReloadInterval is the default interval at which runners check for
+// changes to scheduled functions.
+const DefaultReloadInterval = 10 * time.Second
+
 func NewService(c config.Config, opts ...Opt) service.Service {
-	svc := &svc{config: c}
+	svc := &svc{config: c, reloadInterval: DefaultReloadInterval, clock: clock.New()}
 	for _, o := range opts {
 		o(svc)
 	}
@@ -51,15 +85,26 @@ type svc struct {
 	// data provides the required loading capabilities to trigger functions
 	// from events.
 	data coredata.ExecutionLoader
+	// events records each event received along with the runs it created.
+	events coredata.EventWriter
 	// state allows the creation of new function runs.
 	state state.Manager
 	// queue allows the scheduling of new functions.
 	queue queue.Queue
 	// cronmanager allows the creation of new scheduled functions.
-	cronmanager *cron.Cron
+	cronmanager *scheduler
+	// crons is a signature of the scheduled functions within cronmanager,
+	// used to check whether the scheduled functions have changed.
+	crons string
+	// cronlock locks cronmanager and crons when reloading functions.
+	cronlock sync.Mutex
+	// reloadInterval is how often scheduled functions are checked for changes.
+	reloadInterval time.Duration
+	// clock returns the current time and fires cron triggers.
+	clock clock.Clock
 }
 
-func (s svc) Name() string {
+func (s *svc) Name() string {
 	return "runner"
 }
 
@@ -73,6 +118,13 @@ func (s *svc) Pre(ctx context.Context) error {
 		}
 	}
 
+	if s.events == nil {
+		s.events, err = s.config.DataStore.Service.Concrete.ReadWriter(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	logger.From(ctx).Info().Str("backend", s.config.Queue.Service.Backend).Msg("starting event stream")
 	s.pubsub, err = pubsub.NewPublishSubscriber(ctx, s.config.EventStream.Service)
 	if err != nil {
@@ -110,10 +162,20 @@ func (s *svc) Pre(ctx context.Context) error {
 
 func (s *svc) Run(ctx context.Context) error {
 	l := logger.From(ctx)
+	if s.reloadInterval > 0 {
+		go s.reloadCrons(ctx)
+	}
+
 	l.Info().
 		Str("topic", s.config.EventStream.Service.TopicName()).
 		Msg("subscribing to events")
-	err := s.pubsub.Subscribe(ctx, s.config.EventStream.Service.TopicName(), s.handleMessage)
+	// Subscribe using the runner's own consumer group, so that each runner
+	// receives a share of events while other services receive every event.
+	err := s.pubsub.Subscribe(
+		pubsub.WithConsumerGroup(ctx, s.Name()),
+		s.config.EventStream.Service.TopicName(),
+		s.handleMessage,
+	)
 	if err != nil {
 		return err
 	}
@@ -121,27 +183,40 @@ func (s *svc) Run(ctx context.Context) error {
 }
 
 func (s *svc) Stop(ctx context.Context) error {
+	s.cronlock.Lock()
 	cronCtx := s.cronmanager.Stop()
+	s.cronlock.Unlock()
 	select {
 	case <-cronCtx.Done():
 	case <-ctx.Done():
 		return fmt.Errorf("error waiting for scheduled executions to finish")
 	}
-	return nil
+	return pubsub.Close(s.pubsub)
 }
 
-func (s *svc) initializeCrons(ctx context.Context) error {
-	// If a previous cron manager exists, cancel it.
-	if s.cronmanager != nil {
-		s.cronmanager.Stop()
+// reloadCrons checks for changes to scheduled functions every reloadInterval
+// until the context is cancelled, rebuilding the cron schedule when deployed
+// functions change.  This ensures that new cron triggers are picked up and that
+// removed cron triggers stop firing without restarting the runner.
+func (s *svc) reloadCrons(ctx context.Context) {
+	t := time.NewTicker(s.reloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if err := s.initializeCrons(ctx); err != nil {
+			logger.From(ctx).Error().Err(err).Msg("error reloading scheduled functions")
+		}
 	}
+}
 
-	s.cronmanager = cron.New(
-		cron.WithParser(
-			cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow),
-		),
-	)
-
+// initializeCrons loads all scheduled functions and builds the cron schedule,
+// replacing any previous schedule.  If the scheduled functions haven't changed
+// since the schedule was last built, this is a no-op.
+func (s *svc) initializeCrons(ctx context.Context) error {
 	// Set the functions within the engine, then iterate through each function's
 	// triggers so that we can easily invoke them.  We also need to immediately
 	// set up cron timers to invoke functions on a schedule.
@@ -150,6 +225,26 @@ func (s *svc) initializeCrons(ctx context.Context) error {
 		return err
 	}
 
+	sig, err := cronSignature(fns)
+	if err != nil {
+		return err
+	}
+
+	s.cronlock.Lock()
+	defer s.cronlock.Unlock()
+
+	if s.cronmanager != nil && sig == s.crons {
+		return nil
+	}
+
+	// If a previous cron manager exists, cancel it.
+	if s.cronmanager != nil {
+		s.cronmanager.Stop()
+	}
+
+	s.cronmanager = newScheduler(s.clock)
+	s.crons = sig
+
 	logger.From(ctx).
 		Debug().
 		Int("len", len(fns)).
@@ -162,13 +257,21 @@ func (s *svc) initializeCrons(ctx context.Context) error {
 			if t.CronTrigger == nil {
 				continue
 			}
-			_, err := s.cronmanager.AddFunc(t.Cron, func() {
-				err := s.initialize(ctx, fn, event.Event{
-					Name: "inngest/scheduled.timer",
-				})
+			err := s.cronmanager.AddFunc(t.Cron, func() {
+				evt := event.Event{
+					Name:      "inngest/scheduled.timer",
+					ID:        ulid.MustNew(ulid.Now(), rand.Reader).String(),
+					Timestamp: s.clock.Now().UnixMilli(),
+				}
+				id, err := s.initialize(ctx, fn, evt)
 				if err != nil {
 					logger.From(ctx).Error().Err(err).Msg("error initializing scheduled function")
 				}
+				runs := []coredata.EventRun{}
+				if id != nil {
+					runs = append(runs, coredata.EventRun{FunctionID: fn.ID, Identifier: *id})
+				}
+				s.saveEvent(ctx, evt, s.clock.Now(), runs)
 			})
 			if err != nil {
 				return err
@@ -180,8 +283,30 @@ func (s *svc) initializeCrons(ctx context.Context) error {
 	return nil
 }
 
+// cronSignature returns a signature of the given scheduled functions, which
+// changes whenever any function's configuration changes.  Cron jobs capture
+// the function that they run, so any change requires rebuilding the schedule.
+func cronSignature(fns []function.Function) (string, error) {
+	sorted := make([]function.Function, len(fns))
+	copy(sorted, fns)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	byt, err := json.Marshal(sorted)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(byt)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func (s *svc) handleMessage(ctx context.Context, m pubsub.Message) error {
-	if m.Name != "event/event.received" {
+	if m.Name == event.RunFinishedName {
+		// Finished runs never trigger functions.
+		return nil
+	}
+	if m.Name != event.EventReceivedName {
 		return fmt.Errorf("unknown event type: %s", m.Name)
 	}
 
@@ -198,16 +323,24 @@ func (s *svc) handleMessage(ctx context.Context, m pubsub.Message) error {
 
 	l.Debug().Msg("received message")
 
-	var errs error
+	var (
+		errs error
+		runs []coredata.EventRun
+		lock sync.Mutex
+	)
 	wg := &sync.WaitGroup{}
 
 	// Trigger both new functions and pauses.
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := s.functions(ctx, *evt); err != nil {
+		var err error
+		runs, err = s.functions(ctx, *evt)
+		if err != nil {
 			l.Error().Err(err).Msg("error scheduling functions")
+			lock.Lock()
 			errs = multierror.Append(errs, err)
+			lock.Unlock()
 		}
 	}()
 
@@ -216,22 +349,68 @@ func (s *svc) handleMessage(ctx context.Context, m pubsub.Message) error {
 		defer wg.Done()
 		if err := s.pauses(ctx, *evt); err != nil {
 			l.Error().Err(err).Msg("error consuming pauses")
+			lock.Lock()
 			errs = multierror.Append(errs, err)
+			lock.Unlock()
 		}
 	}()
 
+	wg.Wait()
+
+	receivedAt := m.Timestamp
+	if receivedAt.IsZero() {
+		receivedAt = s.clock.Now()
+	}
+	// Record the event with every run that was created, even if some functions
+	// failed to initialize.  This allows us to see which functions ran for any
+	// given event.
+	s.saveEvent(ctx, *evt, receivedAt, runs)
+
+	// Forward the event to the publisher.  The publisher reads from its own
+	// topic, as not every backend can deliver each message to more than one
+	// service.  Errors are logged without redelivering the message, as
+	// redelivery would initialize every function again.
+	if s.config.Publisher.Enabled() {
+		if err := s.pubsub.Publish(ctx, s.config.Publisher.Topic, m); err != nil {
+			l.Error().Err(err).Msg("error forwarding event to publisher")
+		}
+	}
+
 	return errs
 }
 
-// functions triggers all functions from the given event.
-func (s *svc) functions(ctx context.Context, evt event.Event) error {
+// saveEvent records the given event and its runs within the event store.  Errors
+// are logged and do not fail event processing, as the event has already been
+// handled.
+func (s *svc) saveEvent(ctx context.Context, evt event.Event, receivedAt time.Time, runs []coredata.EventRun) {
+	if s.events == nil || evt.ID == "" {
+		return
+	}
+	if runs == nil {
+		runs = []coredata.EventRun{}
+	}
+	err := s.events.SaveEvent(ctx, coredata.Event{
+		ID:         evt.ID,
+		Name:       evt.Name,
+		Event:      evt,
+		ReceivedAt: receivedAt,
+		Runs:       runs,
+	})
+	if err != nil {
+		logger.From(ctx).Error().Err(err).Msg("error saving event")
+	}
+}
+
+// functions triggers all functions from the given event, returning the runs
+// that were created.
+func (s *svc) functions(ctx context.Context, evt event.Event) ([]coredata.EventRun, error) {
 	fns, err := s.data.FunctionsByTrigger(ctx, evt.Name)
 	if err != nil {
-		return fmt.Errorf("error loading functions by trigger: %w", err)
+		return nil, fmt.Errorf("error loading functions by trigger: %w", err)
 	}
 
 	if len(fns) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	logger.From(ctx).Debug().Int("len", len(fns)).Msg("scheduling functions")
@@ -239,55 +418,98 @@ func (s *svc) functions(ctx context.Context, evt event.Event) error {
 	// Do this once instead of many times when evaluating expressions.
 	evtMap := evt.Map()
 
-	var errs error
+	var (
+		errs error
+		runs []coredata.EventRun
+		lock sync.Mutex
+	)
 	wg := &sync.WaitGroup{}
 	for _, fn := range fns {
 		// We want to initialize each function concurrently;  some of these
 		// may have expressions that take ~tens of milliseconds to run, and
 		// each function should have as little latency as possible.
 		copied := fn
+		if _, invalid := evt.ValidationErrors()[fn.ID]; invalid && s.config.EventAPI.Validation == config.ValidationDrop {
+			// The event API tagged this event as not matching the function's
+			// event definition, so the trigger is dropped.
+			logger.From(ctx).Warn().
+				Str("function", fn.ID).
+				Str("event", evt.Name).
+				Msg("skipping function for invalid event")
+			continue
+		}
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for _, t := range copied.Triggers {
-				if t.EventTrigger == nil || t.Event != evt.Name {
-					// This isn't triggered by an event, so we skip this trigger entirely.
-					continue
-				}
+			ok, evalerr := triggered(ctx, copied, evt, evtMap)
+			if evalerr != nil {
+				lock.Lock()
+				errs = multierror.Append(errs, evalerr)
+				lock.Unlock()
+			}
+			if !ok {
+				return
+			}
 
-				if t.Expression != nil {
-					// Execute expressions here, ensuring that each function is only triggered
-					// under the correct conditions.
-					ok, _, evalerr := expressions.Evaluate(ctx, *t.Expression, map[string]interface{}{
-						"event": evtMap,
-					})
-					if evalerr != nil {
-						errs = multierror.Append(errs, evalerr)
-						continue
-					}
-					if !ok {
-						// Skip this trigger.
-						continue
-					}
-				}
+			// Initialize this function for this event only once;  we don't
+			// want multiple matching triggers to run the function more than once.
+			id, err := s.initialize(ctx, copied, evt)
 
-				// Initialize this function for this event only once;  we don't
-				// want multiple matching triggers to run the function more than once.
-				err := s.initialize(ctx, copied, evt)
-				if err != nil {
-					logger.From(ctx).Error().
-						Err(err).
-						Str("function", copied.ID).
-						Msg("error initializing fn")
-					errs = multierror.Append(errs, err)
-				}
-				return
+			lock.Lock()
+			defer lock.Unlock()
+			if id != nil {
+				runs = append(runs, coredata.EventRun{
+					FunctionID: copied.ID,
+					Identifier: *id,
+				})
+			}
+			if err != nil {
+				logger.From(ctx).Error().
+					Err(err).
+					Str("function", copied.ID).
+					Msg("error initializing fn")
+				errs = multierror.Append(errs, err)
 			}
 		}()
 	}
 
 	wg.Wait()
-	return errs
+	return runs, errs
+}
+
+// triggered returns whether the given function is triggered by the event,
+// evaluating each matching trigger's expression.  evtMap must be the result of
+// evt.Map(), which is passed in to prevent recomputing the map for every function.
+//
+// Any errors evaluating expressions are returned, though the function may still
+// be triggered if another of its triggers matches.
+func triggered(ctx context.Context, fn function.Function, evt event.Event, evtMap map[string]interface{}) (bool, error) {
+	var errs error
+	for _, t := range fn.Triggers {
+		if t.EventTrigger == nil || t.Event != evt.Name {
+			// This isn't triggered by an event, so we skip this trigger entirely.
+			continue
+		}
+
+		if t.Expression == nil {
+			return true, errs
+		}
+
+		// Execute expressions here, ensuring that each function is only triggered
+		// under the correct conditions.
+		ok, _, err := expressions.Evaluate(ctx, *t.Expression, map[string]interface{}{
+			"event": evtMap,
+		})
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		if ok {
+			return true, errs
+		}
+	}
+	return false, errs
 }
 
 // pauses searches for and triggers all pauses from this event.
@@ -305,7 +527,8 @@ func (s *svc) pauses(ctx context.Context, evt event.Event) error {
 		// NOTE: Some pauses may be nil or expired, as the iterator may take
 		// time to process.  We handle that here and assume that the event
 		// did not occur in time.
-		if pause == nil || pause.Expires.Before(time.Now()) {
+		now := s.clock.Now()
+		if pause == nil || pause.Expires.Before(now) {
 			continue
 		}
 
@@ -313,94 +536,156 @@ func (s *svc) pauses(ctx context.Context, evt event.Event) error {
 			Str("pause_id", pause.ID.String()).
 			Msg("handling pause")
 
-		if pause.Expression != nil {
-			s, err := s.state.Load(ctx, pause.Identifier)
-			if err != nil {
-				return err
-			}
+		// Ignore leased pauses;  these are being handled by another runner.
+		if _, err := ResumePauseAt(ctx, *pause, evtMap, now, s.state, s.queue); err != nil && err != state.ErrPauseLeased {
+			return err
+		}
+	}
 
-			// Get expression data from the executor for the given run ID.
-			data := state.EdgeExpressionData(ctx, s, pause.Outgoing)
-			// Add the async event data to the expression
-			data["async"] = evtMap
-			// Compile and run the expression.
-			ok, _, err := expressions.Evaluate(ctx, *pause.Expression, data)
-			if err != nil {
-				return err
-			}
-			if !ok {
-				logger.From(ctx).Trace().
-					Str("pause_id", pause.ID.String()).
-					Str("expression", *pause.Expression).
-					Msg("expression false")
-				continue
-			}
+	return nil
+}
+
+// ResumePause resumes the given pause using the given async event data,
+// enqueueing the pause's incoming step.  If the pause has an expression, the
+// pause is only resumed if the expression matches using the data as the
+// "async" event.  The data is stored within the run's state as the output of
+// state.AsyncID for the incoming step.  This returns whether the pause was
+// resumed.
+//
+// Pauses which only resume on timeout are consumed so that they never resume,
+// returning false.
+//
+// If the pause is already leased, this returns state.ErrPauseLeased without
+// modifying the pause or run.
+//
+// This is a separate, exported function so that it can be used from this service
+// and also from eg. the core API.
+func ResumePause(ctx context.Context, pause state.Pause, async map[string]interface{}, sm state.Manager, q queue.Producer) (bool, error) {
+	return ResumePauseAt(ctx, pause, async, time.Now(), sm, q)
+}
+
+// ResumePauseAt resumes the pause in the same manner as ResumePause, enqueueing
+// the pause's incoming step to run at the given time.
+func ResumePauseAt(ctx context.Context, pause state.Pause, async map[string]interface{}, at time.Time, sm state.Manager, q queue.Producer) (bool, error) {
+	if pause.Expression != nil {
+		s, err := sm.Load(ctx, pause.Identifier)
+		if err != nil {
+			return false, err
 		}
 
-		if pause.OnTimeout {
-			// Delete this pause, as an event has occured which matches
-			// the timeout.
-			if err := s.state.ConsumePause(ctx, pause.ID); err != nil {
-				return err
-			}
+		// Get expression data from the executor for the given run ID.
+		data := state.EdgeExpressionData(ctx, s, pause.Outgoing)
+		// Add the async event data to the expression
+		data["async"] = async
+		// Compile and run the expression.
+		ok, _, err := expressions.Evaluate(ctx, *pause.Expression, data)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			logger.From(ctx).Trace().
+				Str("pause_id", pause.ID.String()).
+				Str("expression", *pause.Expression).
+				Msg("expression false")
+			return false, nil
 		}
+	}
 
-		logger.From(ctx).Debug().
-			Str("pause_id", pause.ID.String()).
-			Str("run_id", pause.Identifier.RunID.String()).
-			Msg("leasing pause")
+	logger.From(ctx).Debug().
+		Str("pause_id", pause.ID.String()).
+		Str("run_id", pause.Identifier.RunID.String()).
+		Msg("leasing pause")
 
-		// Lease this pause so that only this thread can schedule the execution.
-		//
-		// If we don't do this, there's a chance that two concurrent runners
-		// attempt to enqueue the next step of the workflow.
-		err := s.state.LeasePause(ctx, pause.ID)
-		if err == state.ErrPauseLeased {
-			// Ignore;  this is being handled by another runner.
-			continue
-		}
+	// Lease this pause prior to any changes so that only this thread can
+	// schedule the execution or consume the pause.
+	//
+	// If we don't do this, there's a chance that two concurrent runners
+	// attempt to enqueue the next step of the workflow.
+	if err := sm.LeasePause(ctx, pause.ID); err != nil {
+		return false, err
+	}
+
+	if pause.OnTimeout {
+		// Delete this pause, as an event has occured which matches
+		// the timeout.  The incoming step only runs if the event is
+		// not received.
+		return false, sm.ConsumePause(ctx, pause.ID)
+	}
+
+	if len(async) > 0 {
+		// Store the event which resumed the pause, so that it's available
+		// to the incoming step.
+		_, err := sm.SaveResponse(ctx, pause.Identifier, state.DriverResponse{
+			Step:   inngest.Step{ID: state.AsyncID(pause.Incoming)},
+			Output: async,
+		}, 0)
 		if err != nil {
-			return err
+			return false, err
 		}
+	}
 
-		logger.From(ctx).Info().
-			Str("pause_id", pause.ID.String()).
-			Str("run_id", pause.Identifier.RunID.String()).
-			Msg("resuming function")
-
-		// Schedule an execution from the pause's entrypoint.
-		if err := s.queue.Enqueue(
-			ctx,
-			queue.Item{
-				Kind:       queue.KindEdge,
-				Identifier: pause.Identifier,
-				Payload: queue.PayloadEdge{
-					Edge: inngest.Edge{
-						Incoming: pause.Incoming,
-					},
+	logger.From(ctx).Info().
+		Str("pause_id", pause.ID.String()).
+		Str("run_id", pause.Identifier.RunID.String()).
+		Msg("resuming function")
+
+	// Schedule an execution from the pause's entrypoint.
+	if err := q.Enqueue(
+		ctx,
+		queue.Item{
+			Kind:       queue.KindEdge,
+			Identifier: pause.Identifier,
+			Payload: queue.PayloadEdge{
+				Edge: inngest.Edge{
+					Incoming: pause.Incoming,
 				},
 			},
-			time.Now(),
-		); err != nil {
-			return err
-		}
+		},
+		at,
+	); err != nil {
+		return false, err
+	}
 
-		logger.From(ctx).Debug().
-			Str("pause_id", pause.ID.String()).
-			Str("run_id", pause.Identifier.RunID.String()).
-			Msg("consuming pause")
-		if err := s.state.ConsumePause(ctx, pause.ID); err != nil {
-			return err
-		}
+	logger.From(ctx).Debug().
+		Str("pause_id", pause.ID.String()).
+		Str("run_id", pause.Identifier.RunID.String()).
+		Msg("consuming pause")
+	if err := sm.ConsumePause(ctx, pause.ID); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	return nil
+func (s *svc) initialize(ctx context.Context, fn function.Function, evt event.Event) (*state.Identifier, error) {
+	at := scheduledAt(evt, s.clock.Now(), s.config.Execution.MaxDelay)
+	if ts := time.UnixMilli(evt.Timestamp); s.config.Execution.MaxDelay > 0 && ts.After(at) {
+		// The event API rejects these events, but events may be sent from
+		// other sources.
+		logger.From(ctx).Warn().
+			Str("function", fn.ID).
+			Time("ts", ts).
+			Time("at", at).
+			Msg("event timestamp is past the max delay; scheduling function at the max delay")
+	}
+	logger.From(ctx).Debug().Str("function", fn.ID).Time("at", at).Msg("initializing fn")
+	return InitializeAt(ctx, fn, evt, at, s.state, s.queue)
 }
 
-func (s *svc) initialize(ctx context.Context, fn function.Function, evt event.Event) error {
-	logger.From(ctx).Debug().Str("function", fn.ID).Msg("initializing fn")
-	_, err := Initialize(ctx, fn, evt, s.state, s.queue)
-	return err
+// scheduledAt returns the time that functions triggered by the given event
+// should start.  Events with a timestamp in the future are scheduled for that
+// time, up to maxDelay from now;  all other events start immediately.
+func scheduledAt(evt event.Event, now time.Time, maxDelay time.Duration) time.Time {
+	if maxDelay <= 0 || evt.Timestamp <= 0 {
+		return now
+	}
+	ts := time.UnixMilli(evt.Timestamp)
+	if !ts.After(now) {
+		return now
+	}
+	if horizon := now.Add(maxDelay); ts.After(horizon) {
+		return horizon
+	}
+	return ts
 }
 
 // Initialize creates a new funciton run identifier for the given workflow and
@@ -410,6 +695,12 @@ func (s *svc) initialize(ctx context.Context, fn function.Function, evt event.Ev
 // This is a separate, exported function so that it can be used from this service
 // and also from eg. the run command.
 func Initialize(ctx context.Context, fn function.Function, evt event.Event, s state.Manager, q queue.Producer) (*state.Identifier, error) {
+	return InitializeAt(ctx, fn, evt, time.Now(), s, q)
+}
+
+// InitializeAt creates a new function run in the same manner as Initialize,
+// scheduling the run to start at the given time.
+func InitializeAt(ctx context.Context, fn function.Function, evt event.Event, at time.Time, s state.Manager, q queue.Producer) (*state.Identifier, error) {
 	// XXX: This could/should be memoized.
 	flow, err := fn.Workflow(ctx)
 	if err != nil {
@@ -431,7 +722,12 @@ func Initialize(ctx context.Context, fn function.Function, evt event.Event, s st
 		Key:        evt.ID,
 	}
 
-	if _, err := s.New(ctx, *flow, id, evt.Map()); err != nil {
+	if sm, ok := s.(state.ScheduledMutater); ok {
+		_, err = sm.NewAt(ctx, *flow, id, evt.Map(), at)
+	} else {
+		_, err = s.New(ctx, *flow, id, evt.Map())
+	}
+	if err != nil {
 		return nil, fmt.Errorf("error creating run state: %w", err)
 	}
 
@@ -439,8 +735,9 @@ func Initialize(ctx context.Context, fn function.Function, evt event.Event, s st
 	err = q.Enqueue(ctx, queue.Item{
 		Kind:       queue.KindEdge,
 		Identifier: id,
+		FunctionID: fn.ID,
 		Payload:    queue.PayloadEdge{Edge: inngest.SourceEdge},
-	}, time.Now())
+	}, at)
 	if err != nil {
 		return &id, fmt.Errorf("error enqueuing function: %w", err)
 	}
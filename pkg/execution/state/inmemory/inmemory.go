@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/config/registration"
 	"github.com/inngest/inngest/pkg/execution/state"
 )
@@ -21,6 +22,11 @@ func init() {
 type Config struct {
 	l   sync.Mutex
 	mem *mem
+
+	// Clock is used to record when runs start and to check pause expiry and
+	// leases, defaulting to the system clock.  This must be set prior to
+	// creating the state manager.
+	Clock clock.Clock `json:"-"`
 }
 
 func (c *Config) StateName() string { return "inmemory" }
@@ -31,6 +37,9 @@ func (c *Config) Manager(ctx context.Context) (state.Manager, error) {
 
 	if c.mem == nil {
 		c.mem = NewStateManager().(*mem)
+		if c.Clock != nil {
+			c.mem.clock = c.Clock
+		}
 	}
 	return c.mem, nil
 }
@@ -42,6 +51,7 @@ func NewStateManager() state.Manager {
 		state:  map[string]state.State{},
 		pauses: map[uuid.UUID]state.Pause{},
 		lock:   &sync.RWMutex{},
+		clock:  clock.New(),
 	}
 }
 
@@ -49,6 +59,7 @@ type mem struct {
 	state  map[string]state.State
 	pauses map[uuid.UUID]state.Pause
 	lock   *sync.RWMutex
+	clock  clock.Clock
 }
 
 func (m *mem) IsComplete(ctx context.Context, id state.Identifier) (bool, error) {
@@ -64,19 +75,32 @@ func (m *mem) IsComplete(ctx context.Context, id state.Identifier) (bool, error)
 
 // New initializes state for a new run using the specifid ID and starting data.
 func (m *mem) New(ctx context.Context, workflow inngest.Workflow, id state.Identifier, event map[string]any) (state.State, error) {
+	return m.NewAt(ctx, workflow, id, event, time.Time{})
+}
+
+// NewAt initializes state for a new run which is scheduled to start at the
+// given time.
+func (m *mem) NewAt(ctx context.Context, workflow inngest.Workflow, id state.Identifier, event map[string]any, at time.Time) (state.State, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
+	now := m.clock.Now()
+	if at.IsZero() {
+		at = now
+	}
+
 	s := memstate{
 		metadata: state.Metadata{
-			StartedAt: time.Now(),
-			Pending:   1,
+			StartedAt:   now,
+			ScheduledAt: at,
+			Pending:     1,
 		},
 		workflow:   workflow,
 		identifier: id,
 		event:      event,
 		actions:    map[string]map[string]interface{}{},
 		errors:     map[string]error{},
+		attempts:   map[string]int{},
 	}
 
 	if _, ok := m.state[id.IdempotencyKey()]; ok {
@@ -105,6 +129,7 @@ func (m *mem) Load(ctx context.Context, i state.Identifier) (state.State, error)
 		event:      map[string]interface{}{},
 		actions:    map[string]map[string]interface{}{},
 		errors:     map[string]error{},
+		attempts:   map[string]int{},
 	}
 
 	m.lock.Lock()
@@ -146,6 +171,46 @@ func (m *mem) Finalized(ctx context.Context, i state.Identifier, stepID string)
 	return nil
 }
 
+func (m *mem) Cancel(ctx context.Context, i state.Identifier) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s, ok := m.state[i.IdempotencyKey()]
+	if !ok {
+		return fmt.Errorf("identifier not found")
+	}
+
+	instance := s.(memstate)
+	instance.metadata.Cancelled = true
+	m.state[i.IdempotencyKey()] = instance
+
+	return nil
+}
+
+func (m *mem) ResetSteps(ctx context.Context, i state.Identifier, stepIDs []string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s, ok := m.state[i.IdempotencyKey()]
+	if !ok {
+		return fmt.Errorf("identifier not found")
+	}
+	instance := s.(memstate)
+
+	// Copy the maps so that any previous state references aren't updated.
+	instance.actions = copyMap(instance.actions)
+	instance.errors = copyMap(instance.errors)
+	instance.attempts = copyMap(instance.attempts)
+	for _, id := range stepIDs {
+		delete(instance.actions, id)
+		delete(instance.errors, id)
+		delete(instance.attempts, id)
+	}
+
+	m.state[i.IdempotencyKey()] = instance
+	return nil
+}
+
 func (m *mem) SaveResponse(ctx context.Context, i state.Identifier, r state.DriverResponse, attempt int) (state.State, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -159,6 +224,10 @@ func (m *mem) SaveResponse(ctx context.Context, i state.Identifier, r state.Driv
 	// Copy the maps so that any previous state references aren't updated.
 	instance.actions = copyMap(instance.actions)
 	instance.errors = copyMap(instance.errors)
+	instance.attempts = copyMap(instance.attempts)
+
+	// Attempts are zero-indexed.
+	instance.attempts[r.Step.ID] = attempt + 1
 
 	if r.Err == nil {
 		instance.actions[r.Step.ID] = r.Output
@@ -193,15 +262,16 @@ func (m *mem) LeasePause(ctx context.Context, id uuid.UUID) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
+	now := m.clock.Now()
 	pause, ok := m.pauses[id]
-	if !ok || pause.Expires.Before(time.Now()) {
+	if !ok || pause.Expires.Before(now) {
 		return state.ErrPauseNotFound
 	}
-	if pause.LeasedUntil != nil && time.Now().Before(*pause.LeasedUntil) {
+	if pause.LeasedUntil != nil && now.Before(*pause.LeasedUntil) {
 		return state.ErrPauseLeased
 	}
 
-	lease := time.Now().Add(state.PauseLeaseDuration)
+	lease := now.Add(state.PauseLeaseDuration)
 	pause.LeasedUntil = &lease
 	m.pauses[id] = pause
 
@@ -1,10 +1,18 @@
 package inmemory
 
 import (
+	"context"
+	"crypto/rand"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/execution/state"
 	"github.com/inngest/inngest/pkg/execution/state/testharness"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStateHarness(t *testing.T) {
@@ -12,3 +20,33 @@ func TestStateHarness(t *testing.T) {
 		return NewStateManager(), func() {}
 	})
 }
+
+func TestClock(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewVirtual(start)
+	sm, err := (&Config{Clock: clk}).Manager(ctx)
+	require.NoError(t, err)
+
+	id := state.Identifier{WorkflowID: uuid.New(), RunID: ulid.MustNew(ulid.Now(), rand.Reader)}
+	s, err := sm.New(ctx, inngest.Workflow{}, id, map[string]any{})
+	require.NoError(t, err)
+	require.Equal(t, start, s.Metadata().StartedAt)
+
+	pause := state.Pause{
+		ID:         uuid.New(),
+		Identifier: id,
+		Expires:    start.Add(time.Hour),
+	}
+	require.NoError(t, sm.SavePause(ctx, pause))
+
+	// Leases expire using the clock's time.
+	require.NoError(t, sm.LeasePause(ctx, pause.ID))
+	require.ErrorIs(t, sm.LeasePause(ctx, pause.ID), state.ErrPauseLeased)
+	clk.Advance(state.PauseLeaseDuration + time.Second)
+	require.NoError(t, sm.LeasePause(ctx, pause.ID))
+
+	// As do pauses.
+	clk.Advance(time.Hour)
+	require.ErrorIs(t, sm.LeasePause(ctx, pause.ID), state.ErrPauseNotFound)
+}
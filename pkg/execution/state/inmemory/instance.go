@@ -24,6 +24,7 @@ func NewStateInstance(
 	event map[string]any,
 	actions map[string]map[string]any,
 	errors map[string]error,
+	attempts map[string]int,
 ) state.State {
 	return &memstate{
 		workflow:   w,
@@ -32,6 +33,7 @@ func NewStateInstance(
 		event:      event,
 		actions:    actions,
 		errors:     errors,
+		attempts:   attempts,
 	}
 }
 
@@ -51,6 +53,9 @@ type memstate struct {
 
 	// errors stores a map of action errors
 	errors map[string]error
+
+	// attempts stores the number of times each action has been attempted
+	attempts map[string]int
 }
 
 func (s memstate) Metadata() state.Metadata {
@@ -85,6 +90,10 @@ func (s memstate) Errors() map[string]error {
 	return s.errors
 }
 
+func (s memstate) Attempts() map[string]int {
+	return s.attempts
+}
+
 func (s memstate) ActionID(id string) (map[string]interface{}, error) {
 	data, hasAction := s.Actions()[id]
 	err, hasError := s.Errors()[id]
@@ -146,6 +146,10 @@ type KeyGenerator interface {
 	// for given workflow run.
 	Errors(context.Context, state.Identifier) string
 
+	// Attempts returns the key used to store the number of times each step
+	// has been attempted for the given workflow run.
+	Attempts(context.Context, state.Identifier) string
+
 	// PauseLease stores the key which references a pause's lease.
 	//
 	// This is stored independently as we may store more than one copy of a pause
@@ -168,6 +172,12 @@ type mgr struct {
 }
 
 func (m mgr) New(ctx context.Context, workflow inngest.Workflow, id state.Identifier, input map[string]any) (state.State, error) {
+	return m.NewAt(ctx, workflow, id, input, time.Time{})
+}
+
+// NewAt creates state for a new run which is scheduled to start at the given
+// time.
+func (m mgr) NewAt(ctx context.Context, workflow inngest.Workflow, id state.Identifier, input map[string]any, at time.Time) (state.State, error) {
 	// TODO: We could probably optimize the commands here by storing the event
 	// within run metadata.  We want step output (actions) and errors to be
 	// their own redis hash for fast inserts (HSET on individual step results).
@@ -177,6 +187,10 @@ func (m mgr) New(ctx context.Context, workflow inngest.Workflow, id state.Identi
 		CreatedAt: time.Now().Truncate(time.Second),
 		Pending:   1,
 	}
+	metadata.ScheduledAt = metadata.CreatedAt
+	if !at.IsZero() {
+		metadata.ScheduledAt = at
+	}
 
 	// We marshal this ahead of creating a redis transaction as it's necessary
 	// every time and reduces the duration that the lock is held.
@@ -240,11 +254,13 @@ func (m mgr) New(ctx context.Context, workflow inngest.Workflow, id state.Identi
 			workflow,
 			id,
 			state.Metadata{
-				StartedAt: metadata.CreatedAt,
+				StartedAt:   metadata.CreatedAt,
+				ScheduledAt: metadata.ScheduledAt,
 			},
 			input,
 			map[string]map[string]any{},
 			map[string]error{},
+			map[string]int{},
 		),
 		nil
 }
@@ -266,14 +282,76 @@ func (m mgr) metadata(ctx context.Context, id state.Identifier) (*runMetadata, e
 }
 
 func (m mgr) Load(ctx context.Context, id state.Identifier) (state.State, error) {
-	// XXX: Use a pipeliner to improve speed.
-	metadata, err := m.metadata(ctx, id)
-	if err != nil {
-		return nil, err
+	states, errs := m.LoadMany(ctx, []state.Identifier{id})
+	return states[0], errs[0]
+}
+
+// runCmds stores the commands used to load a single run's state.
+type runCmds struct {
+	metadata *redis.StringStringMapCmd
+	event    *redis.StringCmd
+	actions  *redis.StringStringMapCmd
+	errors   *redis.StringStringMapCmd
+	attempts *redis.StringStringMapCmd
+
+	// workflow is loaded once the run's workflow version is known.
+	workflow *redis.StringCmd
+}
+
+// LoadMany loads state for each of the given runs using two pipelines:  the
+// first loads each run's metadata, event and step data, and the second loads
+// the workflow version used by each run.
+func (m mgr) LoadMany(ctx context.Context, ids []state.Identifier) ([]state.State, []error) {
+	states := make([]state.State, len(ids))
+	errs := make([]error, len(ids))
+	cmds := make([]runCmds, len(ids))
+
+	// Errors are checked for each command, so the pipeline's error (the
+	// first command's error) is ignored.
+	_, _ = m.r.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for n, id := range ids {
+			cmds[n] = runCmds{
+				metadata: p.HGetAll(ctx, m.kf.RunMetadata(ctx, id)),
+				event:    p.Get(ctx, m.kf.Event(ctx, id)),
+				actions:  p.HGetAll(ctx, m.kf.Actions(ctx, id)),
+				errors:   p.HGetAll(ctx, m.kf.Errors(ctx, id)),
+				attempts: p.HGetAll(ctx, m.kf.Attempts(ctx, id)),
+			}
+		}
+		return nil
+	})
+
+	metadata := make([]*runMetadata, len(ids))
+	for n := range ids {
+		val, err := cmds[n].metadata.Result()
+		if err == nil {
+			metadata[n], err = NewRunMetadata(val)
+		}
+		errs[n] = err
+	}
+
+	_, _ = m.r.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for n, id := range ids {
+			if errs[n] == nil {
+				cmds[n].workflow = p.Get(ctx, m.kf.Workflow(ctx, id.WorkflowID, metadata[n].Version))
+			}
+		}
+		return nil
+	})
+
+	for n, id := range ids {
+		if errs[n] != nil {
+			continue
+		}
+		states[n], errs[n] = newState(id, metadata[n], cmds[n])
 	}
+	return states, errs
+}
 
+// newState creates a run's state from the results of its commands.
+func newState(id state.Identifier, metadata *runMetadata, cmds runCmds) (state.State, error) {
 	// Load the workflow.
-	byt, err := m.r.Get(ctx, m.kf.Workflow(ctx, id.WorkflowID, metadata.Version)).Bytes()
+	byt, err := cmds.workflow.Bytes()
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +365,7 @@ func (m mgr) Load(ctx context.Context, id state.Identifier) (state.State, error)
 	w.Version = metadata.Version
 
 	// Load the event.
-	byt, err = m.r.Get(ctx, m.kf.Event(ctx, id)).Bytes()
+	byt, err = cmds.event.Bytes()
 	if err != nil {
 		return nil, err
 	}
@@ -297,7 +375,7 @@ func (m mgr) Load(ctx context.Context, id state.Identifier) (state.State, error)
 	}
 
 	// Load the actions.  This is a map of step IDs to JSON-encoded results.
-	rmap, err := m.r.HGetAll(ctx, m.kf.Actions(ctx, id)).Result()
+	rmap, err := cmds.actions.Result()
 	if err != nil {
 		return nil, err
 	}
@@ -310,13 +388,10 @@ func (m mgr) Load(ctx context.Context, id state.Identifier) (state.State, error)
 		}
 		actions[stepID] = data
 	}
-	if err := json.Unmarshal(byt, &event); err != nil {
-		return nil, err
-	}
 
 	// Load the errors.  This is a map of step IDs to error strings.
 	// The original error type is not preserved.
-	rmap, err = m.r.HGetAll(ctx, m.kf.Errors(ctx, id)).Result()
+	rmap, err = cmds.errors.Result()
 	if err != nil {
 		return nil, err
 	}
@@ -324,19 +399,35 @@ func (m mgr) Load(ctx context.Context, id state.Identifier) (state.State, error)
 	for stepID, str := range rmap {
 		errors[stepID] = fmt.Errorf(str)
 	}
-	if err := json.Unmarshal(byt, &event); err != nil {
+
+	// Load the number of times each step has been attempted.
+	rmap, err = cmds.attempts.Result()
+	if err != nil {
 		return nil, err
 	}
+	attempts := map[string]int{}
+	for stepID, str := range rmap {
+		if attempts[stepID], err = strconv.Atoi(str); err != nil {
+			return nil, err
+		}
+	}
 
 	meta := state.Metadata{
-		StartedAt: metadata.CreatedAt,
-		Pending:   metadata.Pending,
+		StartedAt:   metadata.CreatedAt,
+		ScheduledAt: metadata.ScheduledAt,
+		Pending:     metadata.Pending,
+		Cancelled:   metadata.Cancelled,
 	}
 
-	return inmemory.NewStateInstance(*w, id, meta, event, actions, errors), nil
+	return inmemory.NewStateInstance(*w, id, meta, event, actions, errors, attempts), nil
 }
 
 func (m mgr) SaveResponse(ctx context.Context, i state.Identifier, r state.DriverResponse, attempt int) (state.State, error) {
+	// Record the number of attempts, which are zero-indexed.
+	if err := m.r.HSet(ctx, m.kf.Attempts(ctx, i), r.Step.ID, attempt+1).Err(); err != nil {
+		return nil, err
+	}
+
 	if r.Err == nil {
 		// Save the output.
 		str, err := json.Marshal(r.Output)
@@ -376,6 +467,32 @@ func (m mgr) Scheduled(ctx context.Context, i state.Identifier, stepID string) e
 	return m.r.HIncrBy(ctx, m.kf.RunMetadata(ctx, i), "pending", 1).Err()
 }
 
+func (m mgr) Cancel(ctx context.Context, i state.Identifier) error {
+	key := m.kf.RunMetadata(ctx, i)
+	// Ensure that we don't create metadata for runs which don't exist.
+	n, err := m.r.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("identifier not found")
+	}
+	return m.r.HSet(ctx, key, "cancelled", true).Err()
+}
+
+func (m mgr) ResetSteps(ctx context.Context, i state.Identifier, stepIDs []string) error {
+	if len(stepIDs) == 0 {
+		return nil
+	}
+	_, err := m.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HDel(ctx, m.kf.Actions(ctx, i), stepIDs...)
+		p.HDel(ctx, m.kf.Errors(ctx, i), stepIDs...)
+		p.HDel(ctx, m.kf.Attempts(ctx, i), stepIDs...)
+		return nil
+	})
+	return err
+}
+
 func (m mgr) SaveActionOutput(ctx context.Context, id state.Identifier, actionID string, data map[string]interface{}) (state.State, error) {
 	str, err := json.Marshal(data)
 	if err != nil {
@@ -578,6 +695,59 @@ func (m mgr) PauseByStep(ctx context.Context, i state.Identifier, actionID strin
 	return pause, err
 }
 
+// PausesByRun returns the pauses for the given run from any of the given
+// steps, loading every pause using two round trips.
+func (m mgr) PausesByRun(ctx context.Context, i state.Identifier, stepIDs []string) ([]*state.Pause, error) {
+	if len(stepIDs) == 0 {
+		return []*state.Pause{}, nil
+	}
+
+	keys := make([]string, len(stepIDs))
+	for n, stepID := range stepIDs {
+		keys[n] = m.kf.PauseStep(ctx, i, stepID)
+	}
+	vals, err := m.r.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys = []string{}
+	for _, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			// There's no pause for the step.
+			continue
+		}
+		id, err := uuid.Parse(str)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, m.kf.PauseID(ctx, id))
+	}
+	if len(keys) == 0 {
+		return []*state.Pause{}, nil
+	}
+
+	vals, err = m.r.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	pauses := []*state.Pause{}
+	for _, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			// The pause was consumed.
+			continue
+		}
+		pause := &state.Pause{}
+		if err := json.Unmarshal([]byte(str), pause); err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, pause)
+	}
+	return pauses, nil
+}
+
 type iter struct {
 	ri *redis.ScanIterator
 }
@@ -625,6 +795,16 @@ func NewRunMetadata(data map[string]string) (*runMetadata, error) {
 		return nil, fmt.Errorf("invalid created at stored in run metadata")
 	}
 
+	// Runs created prior to delayed events have no scheduled time, and
+	// started immediately.
+	m.ScheduledAt = m.CreatedAt
+	if str, ok := data["scheduledAt"]; ok {
+		m.ScheduledAt, err = time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduled at stored in run metadata")
+		}
+	}
+
 	str, ok = data["pending"]
 	if !ok {
 		return nil, fmt.Errorf("no created at stored in run metadata")
@@ -634,6 +814,13 @@ func NewRunMetadata(data map[string]string) (*runMetadata, error) {
 		return nil, fmt.Errorf("invalid pending stored in run metadata")
 	}
 
+	if str, ok := data["cancelled"]; ok {
+		m.Cancelled, err = strconv.ParseBool(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cancelled stored in run metadata")
+		}
+	}
+
 	return m, nil
 }
 
@@ -643,16 +830,20 @@ func NewRunMetadata(data map[string]string) (*runMetadata, error) {
 type runMetadata struct {
 	// Version is required to load the correct workflow Version
 	// for the specific run.
-	Version   int       `json:"version"`
-	CreatedAt time.Time `json:"createdAt"`
-	Pending   int       `json:"pending"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ScheduledAt time.Time `json:"scheduledAt"`
+	Pending     int       `json:"pending"`
+	Cancelled   bool      `json:"cancelled"`
 }
 
 func (r runMetadata) Map() map[string]any {
 	return map[string]any{
-		"version":   r.Version,
-		"createdAt": r.CreatedAt.Format(time.RFC3339),
-		"pending":   r.Pending,
+		"version":     r.Version,
+		"createdAt":   r.CreatedAt.Format(time.RFC3339),
+		"scheduledAt": r.ScheduledAt.Format(time.RFC3339Nano),
+		"pending":     r.Pending,
+		"cancelled":   r.Cancelled,
 	}
 }
 
@@ -684,6 +875,10 @@ func (d defaultKeyFunc) Errors(ctx context.Context, id state.Identifier) string
 	return fmt.Sprintf("%s:errors:%s:%s", d.prefix, id.WorkflowID, id.RunID)
 }
 
+func (d defaultKeyFunc) Attempts(ctx context.Context, id state.Identifier) string {
+	return fmt.Sprintf("%s:attempts:%s:%s", d.prefix, id.WorkflowID, id.RunID)
+}
+
 func (d defaultKeyFunc) PauseID(ctx context.Context, id uuid.UUID) string {
 	return fmt.Sprintf("%s:pauses:%s", d.prefix, id.String())
 }
@@ -21,6 +21,9 @@ var (
 	// already leased by another event.
 	ErrPauseLeased      = fmt.Errorf("pause already leased")
 	ErrIdentifierExists = fmt.Errorf("identifier already exists")
+	// ErrFunctionCancelled is returned when attempting to execute a step of
+	// a run that has been cancelled.
+	ErrFunctionCancelled = fmt.Errorf("function cancelled")
 )
 
 const (
@@ -94,6 +97,13 @@ func (p Pause) Edge() inngest.Edge {
 	}
 }
 
+// AsyncID returns the ID under which the async event which resumed a pause is
+// stored within the run's actions, for the pause's incoming step.  This makes
+// the event available to the step and its children as steps["$async:<step>"].
+func AsyncID(stepID string) string {
+	return "$async:" + stepID
+}
+
 // Metadata must be stored for each workflow run, allowing the runner to inspect
 // when the execution started, the number of steps enqueued, and the number of
 // steps finalized.
@@ -104,6 +114,11 @@ func (p Pause) Edge() inngest.Edge {
 type Metadata struct {
 	StartedAt time.Time `json:"startedAt"`
 
+	// ScheduledAt is the time that the run's first step is scheduled to
+	// start.  This is later than StartedAt for runs triggered by events with
+	// a timestamp in the future.
+	ScheduledAt time.Time `json:"scheduledAt"`
+
 	// Pending is the number of steps that have been enqueued but have
 	// not yet finalized.
 	//
@@ -115,6 +130,41 @@ type Metadata struct {
 	//   the dag) enqueued. Note that the step must have its children
 	//   enqueued to be considered finalized.
 	Pending int
+
+	// Cancelled indicates that the run has been cancelled.  Steps of
+	// cancelled runs are finalized without being executed.
+	Cancelled bool `json:"cancelled"`
+}
+
+// RunStatus is the status of a function run, derived from its state.
+type RunStatus string
+
+const (
+	RunStatusScheduled RunStatus = "scheduled"
+	RunStatusRunning   RunStatus = "running"
+	RunStatusCompleted RunStatus = "completed"
+	RunStatusFailed    RunStatus = "failed"
+	RunStatusCancelled RunStatus = "cancelled"
+)
+
+// Status returns the status of the given run at the given time.  Runs are
+// scheduled until their first step starts, running while steps are pending,
+// and completed or failed once every step has finalized.
+func Status(s State, now time.Time) RunStatus {
+	md := s.Metadata()
+	if md.Cancelled {
+		return RunStatusCancelled
+	}
+	if md.Pending > 0 {
+		if md.ScheduledAt.After(now) && len(s.Actions()) == 0 && len(s.Errors()) == 0 {
+			return RunStatusScheduled
+		}
+		return RunStatusRunning
+	}
+	if len(s.Errors()) > 0 {
+		return RunStatusFailed
+	}
+	return RunStatusCompleted
 }
 
 // State represents the current state of a workflow.  It is data-structure
@@ -184,6 +234,52 @@ type CompleteSubscriber interface {
 }
 */
 
+// MultiLoader is an optional interface which a state store can implement to load
+// state for many runs at once, eg. when listing runs, rather than loading each
+// run in turn.
+type MultiLoader interface {
+	// LoadMany returns run state for each of the given identifiers, in the
+	// same order.  If a run's state can't be loaded its state is nil and the
+	// error is returned at the same index within errs.
+	LoadMany(ctx context.Context, ids []Identifier) (states []State, errs []error)
+}
+
+// ScheduledMutater is an optional interface which a state store can implement to
+// record the time that each run is scheduled to start.  Runs created using
+// Mutater.New are assumed to start immediately.
+type ScheduledMutater interface {
+	// NewAt creates a new state in the same manner as New, recording at as the
+	// time that the run's first step is scheduled to start.  If at is zero, the
+	// run is assumed to start immediately.
+	NewAt(ctx context.Context, workflow inngest.Workflow, i Identifier, input map[string]any, at time.Time) (State, error)
+}
+
+// AttemptGetter is an optional interface which State can implement to return
+// the number of times each step has been attempted.
+type AttemptGetter interface {
+	// Attempts returns the number of times each step has been attempted,
+	// keyed by step ID.  Steps which haven't run are not present.
+	Attempts() map[string]int
+}
+
+// RunCanceller is an optional interface which a state store can implement to
+// cancel runs.
+type RunCanceller interface {
+	// Cancel marks the run as cancelled, preventing any further steps from
+	// running.  Steps which have already been enqueued must still be
+	// finalized.
+	Cancel(ctx context.Context, i Identifier) error
+}
+
+// StepResetter is an optional interface which a state store can implement to
+// clear the results of steps, allowing steps which have already completed or
+// failed to be rerun.
+type StepResetter interface {
+	// ResetSteps removes the output, errors and attempts of each given step
+	// within the run.
+	ResetSteps(ctx context.Context, i Identifier, stepIDs []string) error
+}
+
 // Mutater mutates state for a given identifier, storing the state and returning
 // the new state.
 //
@@ -262,6 +358,15 @@ type PauseGetter interface {
 	PauseByID(ctx context.Context, pauseID uuid.UUID) (*Pause, error)
 }
 
+// RunPauseGetter is an optional interface which a state store can implement to
+// load a run's pauses for many steps at once, rather than calling PauseByStep
+// for each step.
+type RunPauseGetter interface {
+	// PausesByRun returns the pauses for the given run from any of the given
+	// steps.  Steps without a pause are skipped.
+	PausesByRun(ctx context.Context, i Identifier, stepIDs []string) ([]*Pause, error)
+}
+
 // PauseIterator allows the runner to iterate over all pauses returned by a PauseGetter.  This
 // ensures that, at scale, all pauses do not need to be loaded into memory.
 type PauseIterator interface {
@@ -80,6 +80,20 @@ func (mr *MockStateMockRecorder) Actions() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Actions", reflect.TypeOf((*MockState)(nil).Actions))
 }
 
+// Attempts mocks base method.
+func (m *MockState) Attempts() map[string]int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attempts")
+	ret0, _ := ret[0].(map[string]int)
+	return ret0
+}
+
+// Attempts indicates an expected call of Attempts.
+func (mr *MockStateMockRecorder) Attempts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attempts", reflect.TypeOf((*MockState)(nil).Attempts))
+}
+
 // Errors mocks base method.
 func (m *MockState) Errors() map[string]error {
 	m.ctrl.T.Helper()
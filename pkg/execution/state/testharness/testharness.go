@@ -116,7 +116,12 @@ func CheckState(t *testing.T, gen Generator) {
 		"PausesByEvent/Consumed":             checkPausesByEvent_consumed,
 		"PauseByStep":                        checkPausesByStep,
 		"PauseByID":                          checkPauseByID,
+		"PausesByRun":                        checkPausesByRun,
+		"LoadMany":                           checkLoadMany,
+		"ResetSteps":                         checkResetSteps,
 		"Metadata/StartedAt":                 checkMetadataStartedAt,
+		"Metadata/ScheduledAt":               checkMetadataScheduledAt,
+		"Cancel":                             checkCancel,
 		"Idempotency":                        checkIdempotency,
 	}
 	for name, f := range funcs {
@@ -255,6 +260,10 @@ func checkSaveResponse_output(t *testing.T, m state.Manager) {
 	loaded, err = next.ActionID(w.Steps[1].ID)
 	require.NoError(t, err)
 	require.EqualValues(t, r2.Output, loaded)
+	// Attempts should be recorded for each step, and are zero-indexed.
+	if ag, ok := next.(state.AttemptGetter); ok {
+		require.Equal(t, map[string]int{w.Steps[0].ID: 1, w.Steps[1].ID: 2}, ag.Attempts())
+	}
 	// Output shouldn't be finalized until edges are added via the runner.
 	require.Equal(t, 0, next.Metadata().Pending)
 
@@ -412,6 +421,58 @@ func checkMetadataStartedAt(t *testing.T, m state.Manager) {
 	require.EqualValues(t, s.Metadata().StartedAt.UTC(), reloaded.Metadata().StartedAt.UTC())
 }
 
+func checkMetadataScheduledAt(t *testing.T, m state.Manager) {
+	ctx := context.Background()
+	sm, ok := m.(state.ScheduledMutater)
+	if !ok {
+		t.Skip("state manager doesn't record scheduled times")
+	}
+	w.UUID = uuid.NewSHA1(uuid.NameSpaceOID, []byte(w.ID))
+	runID := ulid.MustNew(ulid.Now(), rand.Reader)
+	id := state.Identifier{
+		WorkflowID: w.UUID,
+		RunID:      runID,
+		Key:        runID.String(),
+	}
+
+	at := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	s, err := sm.NewAt(ctx, w, id, input.Map(), at)
+	require.NoError(t, err)
+	require.True(t, at.Equal(s.Metadata().ScheduledAt), "New should return the scheduled time")
+
+	reloaded, err := m.Load(ctx, s.Identifier())
+	require.NoError(t, err)
+	require.True(t, at.Equal(reloaded.Metadata().ScheduledAt), "Loaded state should have the scheduled time")
+
+	// Runs without a scheduled time start immediately.
+	immediate := setup(t, m)
+	reloaded, err = m.Load(ctx, immediate.Identifier())
+	require.NoError(t, err)
+	require.True(t, reloaded.Metadata().ScheduledAt.Equal(reloaded.Metadata().StartedAt))
+}
+
+func checkCancel(t *testing.T, m state.Manager) {
+	c, ok := m.(state.RunCanceller)
+	if !ok {
+		t.Skip("state manager doesn't cancel runs")
+	}
+
+	ctx := context.Background()
+	s := setup(t, m)
+
+	loaded, err := m.Load(ctx, s.Identifier())
+	require.NoError(t, err)
+	require.False(t, loaded.Metadata().Cancelled)
+
+	err = c.Cancel(ctx, s.Identifier())
+	require.NoError(t, err)
+
+	reloaded, err := m.Load(ctx, s.Identifier())
+	require.NoError(t, err)
+	require.True(t, reloaded.Metadata().Cancelled, "Loaded state should be cancelled")
+	require.Equal(t, loaded.Metadata().Pending, reloaded.Metadata().Pending, "Cancelling should not change the pending count")
+}
+
 func checkSavePause(t *testing.T, m state.Manager) {
 	ctx := context.Background()
 	s := setup(t, m)
@@ -880,6 +941,127 @@ func checkPausesByStep(t *testing.T, m state.Manager) {
 	require.EqualValues(t, second, *found)
 }
 
+func checkPausesByRun(t *testing.T, m state.Manager) {
+	ctx := context.Background()
+	rpg, ok := m.(state.RunPauseGetter)
+	if !ok {
+		t.Skip("state manager doesn't load pauses by run")
+	}
+	s := setup(t, m)
+	other := setup(t, m)
+
+	pause := state.Pause{
+		ID:         uuid.New(),
+		Identifier: s.Identifier(),
+		Outgoing:   inngest.TriggerName,
+		Incoming:   w.Steps[0].ID,
+		Expires:    time.Now().Add(state.PauseLeaseDuration * 2).Truncate(time.Millisecond).UTC(),
+	}
+	require.NoError(t, m.SavePause(ctx, pause))
+	consumed := state.Pause{
+		ID:         uuid.New(),
+		Identifier: s.Identifier(),
+		Outgoing:   w.Steps[0].ID,
+		Incoming:   w.Steps[1].ID,
+		Expires:    time.Now().Add(state.PauseLeaseDuration * 2).Truncate(time.Millisecond).UTC(),
+	}
+	require.NoError(t, m.SavePause(ctx, consumed))
+	require.NoError(t, m.ConsumePause(ctx, consumed.ID))
+	require.NoError(t, m.SavePause(ctx, state.Pause{
+		ID:         uuid.New(),
+		Identifier: other.Identifier(),
+		Outgoing:   inngest.TriggerName,
+		Incoming:   w.Steps[0].ID,
+		Expires:    time.Now().Add(state.PauseLeaseDuration * 2).Truncate(time.Millisecond).UTC(),
+	}))
+
+	steps := []string{inngest.TriggerName, w.Steps[0].ID, w.Steps[1].ID}
+	found, err := rpg.PausesByRun(ctx, s.Identifier(), steps)
+	require.NoError(t, err)
+	require.Len(t, found, 1, "PausesByRun should only return unconsumed pauses for the run")
+	require.EqualValues(t, pause, *found[0])
+
+	found, err = rpg.PausesByRun(ctx, s.Identifier(), []string{w.Steps[1].ID})
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
+
+func checkLoadMany(t *testing.T, m state.Manager) {
+	ctx := context.Background()
+	ml, ok := m.(state.MultiLoader)
+	if !ok {
+		t.Skip("state manager doesn't load many runs at once")
+	}
+	a := setup(t, m)
+	b := setup(t, m)
+	_, err := m.SaveResponse(ctx, b.Identifier(), state.DriverResponse{
+		Step:   w.Steps[0],
+		Output: map[string]interface{}{"status": float64(200)},
+	}, 0)
+	require.NoError(t, err)
+
+	missingID := ulid.MustNew(ulid.Now(), rand.Reader)
+	missing := state.Identifier{WorkflowID: w.UUID, RunID: missingID, Key: missingID.String()}
+
+	ids := []state.Identifier{a.Identifier(), missing, b.Identifier()}
+	states, errs := ml.LoadMany(ctx, ids)
+	require.Len(t, states, 3)
+	require.Len(t, errs, 3)
+
+	require.Error(t, errs[1], "Loading a missing run should error")
+	require.Nil(t, states[1])
+
+	for _, n := range []int{0, 2} {
+		require.NoError(t, errs[n])
+		loaded, err := m.Load(ctx, ids[n])
+		require.NoError(t, err)
+		require.Equal(t, ids[n], states[n].Identifier())
+		require.EqualValues(t, w, states[n].Workflow())
+		require.EqualValues(t, loaded.Event(), states[n].Event())
+		require.EqualValues(t, loaded.Actions(), states[n].Actions())
+		require.EqualValues(t, loaded.Metadata(), states[n].Metadata())
+	}
+	require.EqualValues(t, map[string]interface{}{"status": float64(200)}, states[2].Actions()[w.Steps[0].ID])
+}
+
+func checkResetSteps(t *testing.T, m state.Manager) {
+	ctx := context.Background()
+	sr, ok := m.(state.StepResetter)
+	if !ok {
+		t.Skip("state manager doesn't reset steps")
+	}
+	s := setup(t, m)
+	_, err := m.SaveResponse(ctx, s.Identifier(), state.DriverResponse{
+		Step:   w.Steps[0],
+		Output: map[string]interface{}{"status": float64(200)},
+	}, 0)
+	require.NoError(t, err)
+	_, err = m.SaveResponse(ctx, s.Identifier(), state.DriverResponse{
+		Step: w.Steps[1],
+		Err:  fmt.Errorf("step failed"),
+	}, 2)
+	require.NoError(t, err)
+	before, err := m.Load(ctx, s.Identifier())
+	require.NoError(t, err)
+
+	require.NoError(t, sr.ResetSteps(ctx, s.Identifier(), []string{w.Steps[0].ID, w.Steps[1].ID}))
+
+	loaded, err := m.Load(ctx, s.Identifier())
+	require.NoError(t, err)
+	require.False(t, loaded.ActionComplete(w.Steps[0].ID))
+	require.Empty(t, loaded.Actions())
+	require.Empty(t, loaded.Errors())
+	if ag, ok := loaded.(state.AttemptGetter); ok {
+		require.Empty(t, ag.Attempts())
+	}
+	require.Equal(t, before.Metadata().Pending, loaded.Metadata().Pending)
+	require.EqualValues(t, s.Event(), loaded.Event())
+
+	// Resetting steps without results is a no-op.
+	require.NoError(t, sr.ResetSteps(ctx, s.Identifier(), []string{w.Steps[0].ID}))
+	require.NoError(t, sr.ResetSteps(ctx, s.Identifier(), nil))
+}
+
 func checkPauseByID(t *testing.T, m state.Manager) {
 	ctx := context.Background()
 	s := setup(t, m)
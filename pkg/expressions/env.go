@@ -17,6 +17,8 @@ var (
 		"user",
 		"actions", // deprecated
 		"action",  // deprecated
+		"body",    // webhook transforms
+		"headers", // webhook transforms
 	}
 )
 
@@ -16,13 +16,16 @@ package expressions
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
 	"github.com/pkg/errors"
 	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 var (
@@ -40,6 +43,11 @@ type Evaluable interface {
 	// data should be treated as null values;  the expression must not error.
 	Evaluate(ctx context.Context, data *Data) (bool, *time.Time, error)
 
+	// Value evaluates the expression against the incoming Data, returning
+	// the result as native types.  This allows expressions to transform
+	// data, eg. by returning maps or lists, instead of returning a boolean.
+	Value(ctx context.Context, data *Data) (interface{}, error)
+
 	// UsedAttributes returns the attributes that are referenced within the
 	// expression.
 	UsedAttributes(ctx context.Context) *UsedAttributes
@@ -112,10 +120,56 @@ func (e *expressionEvaluator) Evaluate(ctx context.Context, data *Data) (bool, *
 		return false, nil, nil
 	}
 
-	act, err := data.Partial(ctx, *e.attrs)
+	result, tr, err := e.eval(ctx, data)
 	if err != nil {
 		return false, nil, err
 	}
+	if types.IsUnknown(result) {
+		// When evaluating to a strict result this should never happen.  We inject a decorator
+		// to handle unknowns as values similar to null, and should always get a value.
+		return false, nil, nil
+	}
+
+	b, ok := result.Value().(bool)
+	if !ok {
+		return false, nil, errors.Wrapf(ErrInvalidResult, "returned type %T (%s)", result, result)
+	}
+
+	// Find earliest date that we need to test against.
+	earliest := tr.Next()
+	return b, earliest, nil
+}
+
+// Value evaluates the expression against the given data, returning the result
+// as native JSON-compatible types:  maps, slices, strings, float64s, bools or
+// nil.  Unlike Evaluate, the expression may return any type.
+func (e *expressionEvaluator) Value(ctx context.Context, data *Data) (interface{}, error) {
+	if data == nil {
+		data = NewData(nil)
+	}
+
+	result, _, err := e.eval(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if types.IsUnknown(result) {
+		return nil, nil
+	}
+
+	val, err := result.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidResult, "unable to convert %T (%s)", result, result)
+	}
+	return val.(*structpb.Value).AsInterface(), nil
+}
+
+// eval evaluates the expression, returning the raw result and the times
+// compared within the expression.
+func (e *expressionEvaluator) eval(ctx context.Context, data *Data) (ref.Val, *timeRefs, error) {
+	act, err := data.Partial(ctx, *e.attrs)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// We want to perform an exhaustive search and track the state of the search
 	// to see if dates are compared, then return the minimum date compared.
@@ -132,34 +186,21 @@ func (e *expressionEvaluator) Evaluate(ctx context.Context, data *Data) (bool, *
 		cel.CustomDecorator(td),
 	)
 	if err != nil {
-		return false, nil, err
+		return nil, nil, err
 	}
 
 	result, _, err := program.Eval(act)
 	if result == nil {
-		return false, nil, ErrNoResult
-	}
-	if types.IsUnknown(result) {
-		// When evaluating to a strict result this should never happen.  We inject a decorator
-		// to handle unknowns as values similar to null, and should always get a value.
-		return false, nil, nil
+		return nil, nil, ErrNoResult
 	}
 	if types.IsError(result) {
-		return false, nil, errors.Wrapf(ErrInvalidResult, "invalid type comparison: %s", err.Error())
+		return nil, nil, errors.Wrapf(ErrInvalidResult, "invalid type comparison: %s", err.Error())
 	}
 	if err != nil {
 		// This shouldn't be handled, as we should get an Error type in result above.
-		return false, nil, fmt.Errorf("error evaluating expression '%s': %w", e.expression, err)
-	}
-
-	b, ok := result.Value().(bool)
-	if !ok {
-		return false, nil, errors.Wrapf(ErrInvalidResult, "returned type %T (%s)", result, result)
+		return nil, nil, fmt.Errorf("error evaluating expression '%s': %w", e.expression, err)
 	}
-
-	// Find earliest date that we need to test against.
-	earliest := tr.Next()
-	return b, earliest, nil
+	return result, tr, nil
 }
 
 // UsedAttributes returns the attributes used within the expression.
@@ -954,6 +954,50 @@ func TestFilteredAttributes(t *testing.T) {
 	}
 }
 
+func TestValue(t *testing.T) {
+	data := NewData(map[string]interface{}{
+		"body": map[string]interface{}{
+			"type":  "charge.succeeded",
+			"items": []interface{}{"a", "b"},
+			"total": 10,
+		},
+		"headers": map[string]interface{}{"x-source": "stripe"},
+	})
+
+	tests := []struct {
+		expr     string
+		expected interface{}
+	}{
+		{`body.type`, "charge.succeeded"},
+		{`body.total * 2`, float64(20)},
+		{`body.type == "charge.succeeded"`, true},
+		{`body.missing`, nil},
+		{
+			`{"name": "stripe/" + body.type, "data": {"total": body.total, "source": headers["x-source"]}}`,
+			map[string]interface{}{
+				"name": "stripe/charge.succeeded",
+				"data": map[string]interface{}{"total": float64(10), "source": "stripe"},
+			},
+		},
+		{
+			`body.items.map(i, {"name": "item/" + i})`,
+			[]interface{}{
+				map[string]interface{}{"name": "item/a"},
+				map[string]interface{}{"name": "item/b"},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		eval, err := NewExpressionEvaluator(ctx, test.expr)
+		require.NoError(t, err, test.expr)
+		actual, err := eval.Value(ctx, data)
+		require.NoError(t, err, test.expr)
+		require.Equal(t, test.expected, actual, test.expr)
+	}
+}
+
 func BenchmarkEvaluate(b *testing.B) {
 	expression := `["P0", "P1"].exists(p, p in event.data.tag) && lowercase(event.data.project) == "benchmark" && user.email.endsWith("example.net")`
 	data := NewData(map[string]interface{}{
@@ -0,0 +1,204 @@
+package function
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/expressions"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// validateGraph statically analyses the function's DAG, returning an error
+// for cycles, steps which can never run, invalid durations and expressions
+// which reference steps that won't have run when they're evaluated.  Edges
+// must reference known steps, as checked by Validate.
+func (f Function) validateGraph(ctx context.Context, edges []inngest.Edge) error {
+	var err error
+
+	children := map[string][]string{}
+	parents := map[string][]string{}
+	for _, edge := range edges {
+		children[edge.Outgoing] = append(children[edge.Outgoing], edge.Incoming)
+		parents[edge.Incoming] = append(parents[edge.Incoming], edge.Outgoing)
+	}
+
+	ids := make([]string, 0, len(f.Steps))
+	for id := range f.Steps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, cycle := range cycles(ids, children) {
+		err = multierror.Append(err, fmt.Errorf("steps contain a cycle: %s", strings.Join(cycle, " -> ")))
+	}
+
+	reachable := walk(inngest.TriggerName, children)
+	for _, id := range ids {
+		if _, ok := reachable[id]; !ok {
+			err = multierror.Append(err, fmt.Errorf("step '%s' is unreachable from the trigger", id))
+		}
+	}
+
+	for _, edge := range edges {
+		if edge.Metadata == nil {
+			continue
+		}
+		name := fmt.Sprintf("%s -> %s", edge.Outgoing, edge.Incoming)
+
+		if edge.Metadata.Wait != nil {
+			if _, derr := str2duration.ParseDuration(*edge.Metadata.Wait); derr != nil {
+				err = multierror.Append(err, fmt.Errorf("invalid wait duration '%s' for edge '%s'", *edge.Metadata.Wait, name))
+			}
+		}
+
+		exprs := []string{}
+		if edge.Metadata.If != "" {
+			exprs = append(exprs, edge.Metadata.If)
+		}
+		if am := edge.Metadata.AsyncEdgeMetadata; am != nil {
+			if am.Event == "" {
+				err = multierror.Append(err, fmt.Errorf("async edge '%s' must specify an event", name))
+			}
+			if _, derr := str2duration.ParseDuration(am.TTL); derr != nil {
+				err = multierror.Append(err, fmt.Errorf("invalid async ttl '%s' for edge '%s'", am.TTL, name))
+			}
+			if am.Match != nil && *am.Match != "" {
+				exprs = append(exprs, *am.Match)
+			}
+		}
+
+		// Expressions are evaluated once the outgoing step finishes, so only
+		// the outgoing step and its ancestors have output available.
+		available := walk(edge.Outgoing, parents)
+		available[edge.Outgoing] = struct{}{}
+		for _, expr := range exprs {
+			eval, verr := expressions.NewExpressionEvaluator(ctx, expr)
+			if verr != nil {
+				// Invalid expressions are reported by Validate.
+				continue
+			}
+			for _, path := range eval.UsedAttributes(ctx).Fields["steps"] {
+				if len(path) == 0 {
+					continue
+				}
+				// Async events are stored prior to their step running.
+				step := strings.TrimPrefix(path[0], state.AsyncID(""))
+				if _, ok := available[step]; !ok {
+					err = multierror.Append(err, fmt.Errorf("expression '%s' for edge '%s' references step '%s', which will not have run", expr, name, path[0]))
+				}
+			}
+		}
+	}
+
+	return err
+}
+
+// cycles returns each cycle found within the graph, as the path of step IDs
+// leading back to the first step.
+func cycles(ids []string, children map[string][]string) [][]string {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	found := [][]string{}
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		path = append(path, id)
+		for _, child := range children[id] {
+			switch state[child] {
+			case visiting:
+				for n := range path {
+					if path[n] == child {
+						cycle := append([]string{}, path[n:]...)
+						found = append(found, append(cycle, child))
+						break
+					}
+				}
+			case 0:
+				visit(child)
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+	}
+
+	for _, id := range ids {
+		if state[id] == 0 {
+			visit(id)
+		}
+	}
+	return found
+}
+
+// walk returns all nodes reachable from the given node, excluding the node
+// itself unless it's part of a cycle.  Walking parents returns ancestors.
+func walk(id string, next map[string][]string) map[string]struct{} {
+	seen := map[string]struct{}{}
+	queue := []string{id}
+	for len(queue) > 0 {
+		id, queue = queue[0], queue[1:]
+		for _, n := range next[id] {
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			seen[n] = struct{}{}
+			queue = append(queue, n)
+		}
+	}
+	return seen
+}
+
+// Warnings analyses the given functions together, returning non-fatal issues
+// which can't be found by validating each function alone.  Async edges which
+// wait for an event that doesn't trigger any of the given functions and isn't
+// known are reported, as the event may never be received.  known reports
+// whether an event is produced elsewhere, eg. by a source or another function,
+// and may be nil.
+func Warnings(ctx context.Context, fns []*Function, known func(event string) bool) []string {
+	events := map[string]struct{}{}
+	for _, fn := range fns {
+		for _, t := range fn.Triggers {
+			if t.EventTrigger != nil {
+				events[t.Event] = struct{}{}
+			}
+		}
+	}
+
+	warnings := []string{}
+	for _, fn := range fns {
+		_, edges, err := fn.Actions(ctx)
+		if err != nil {
+			continue
+		}
+		for _, edge := range edges {
+			if edge.Metadata == nil || edge.Metadata.AsyncEdgeMetadata == nil {
+				continue
+			}
+			event := edge.Metadata.AsyncEdgeMetadata.Event
+			if _, ok := events[event]; ok || event == "" {
+				continue
+			}
+			if known != nil && known(event) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"function '%s' waits for event '%s' after step '%s', but no known function or source produces it",
+				fn.Name,
+				event,
+				edge.Outgoing,
+			))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
@@ -0,0 +1,152 @@
+package function
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inngest/inngest/inngest"
+	"github.com/stretchr/testify/require"
+)
+
+// graphFn returns a function with a step for each key, running after the given
+// edges.  Steps without edges run after the trigger.
+func graphFn(event string, steps map[string][]After) Function {
+	fn := Function{
+		Name:     "graph",
+		ID:       "graph",
+		Triggers: []Trigger{{EventTrigger: &EventTrigger{Event: event}}},
+		Steps:    map[string]Step{},
+	}
+	for id, after := range steps {
+		fn.Steps[id] = Step{
+			ID:   id,
+			Path: "file://.",
+			Name: id,
+			Runtime: inngest.RuntimeWrapper{
+				Runtime: inngest.RuntimeHTTP{URL: "https://www.example.com/" + id},
+			},
+			After: after,
+		}
+	}
+	return fn
+}
+
+func TestValidateGraph(t *testing.T) {
+	trigger := After{Step: inngest.TriggerName}
+
+	tests := []struct {
+		name  string
+		steps map[string][]After
+		errs  []string
+	}{
+		{
+			name: "valid",
+			steps: map[string][]After{
+				"check": {trigger},
+				"plan":  {{Step: "check", If: "steps.check.ok == true", Wait: strptr("1h30m")}},
+				"email": {{Step: "upgraded", If: `steps["$async:upgraded"].data.plan == "pro"`}},
+				"upgraded": {{
+					Step: "plan",
+					Async: &inngest.AsyncEdgeMetadata{
+						Event: "user/upgraded",
+						TTL:   "7d",
+						Match: strptr(`async.data.id == steps["check"].id && steps.plan.sent`),
+					},
+				}},
+			},
+		},
+		{
+			name: "cycle",
+			steps: map[string][]After{
+				"a": {trigger, {Step: "c"}},
+				"b": {{Step: "a"}},
+				"c": {{Step: "b"}},
+			},
+			errs: []string{"steps contain a cycle: a -> b -> c -> a"},
+		},
+		{
+			name: "unreachable",
+			steps: map[string][]After{
+				"a": {trigger},
+				"b": {{Step: "c"}},
+				"c": {{Step: "b"}},
+			},
+			errs: []string{
+				"steps contain a cycle: b -> c -> b",
+				"step 'b' is unreachable from the trigger",
+				"step 'c' is unreachable from the trigger",
+			},
+		},
+		{
+			name: "durations",
+			steps: map[string][]After{
+				"a": {trigger},
+				"b": {{Step: "a", Wait: strptr("tomorrow")}},
+				"c": {{Step: "a", Async: &inngest.AsyncEdgeMetadata{TTL: "1 week"}}},
+			},
+			errs: []string{
+				"invalid wait duration 'tomorrow' for edge 'a -> b'",
+				"async edge 'a -> c' must specify an event",
+				"invalid async ttl '1 week' for edge 'a -> c'",
+			},
+		},
+		{
+			name: "non-ancestor steps",
+			steps: map[string][]After{
+				"a": {trigger},
+				"b": {{Step: "a"}},
+				"c": {{Step: "a", If: "steps.b.ok"}},
+				"d": {{
+					Step:  inngest.TriggerName,
+					Async: &inngest.AsyncEdgeMetadata{Event: "x", TTL: "1h", Match: strptr(`steps["a"].id == async.id`)},
+				}},
+			},
+			errs: []string{
+				"expression 'steps.b.ok' for edge 'a -> c' references step 'b', which will not have run",
+				`expression 'steps["a"].id == async.id' for edge '$trigger -> d' references step 'a', which will not have run`,
+			},
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fn := graphFn("user/signup", test.steps)
+			// Graph analysis is only run when deploying, not when loading.
+			require.NoError(t, fn.Validate(ctx))
+
+			err := fn.ValidateGraph(ctx)
+			if len(test.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range test.errs {
+				require.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	async := func(event string) map[string][]After {
+		return map[string][]After{
+			"a": {{Step: inngest.TriggerName}},
+			"b": {{Step: "a", Async: &inngest.AsyncEdgeMetadata{Event: event, TTL: "1d"}}},
+		}
+	}
+
+	signup := graphFn("user/signup", async("user/upgraded"))
+	upgrade := graphFn("user/upgraded", async("user/signup"))
+	cancel := graphFn("user/signup", async("user/cancelled"))
+
+	ctx := context.Background()
+	require.Empty(t, Warnings(ctx, []*Function{&signup, &upgrade}, nil))
+	require.Empty(t, Warnings(ctx, []*Function{&signup}, func(event string) bool {
+		return event == "user/upgraded"
+	}))
+	require.Equal(t, []string{
+		"function 'graph' waits for event 'user/cancelled' after step 'a', but no known function or source produces it",
+		"function 'graph' waits for event 'user/upgraded' after step 'a', but no known function or source produces it",
+	}, Warnings(ctx, []*Function{&signup, &cancel}, nil))
+}
@@ -1,15 +1,21 @@
 package function
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
 	"github.com/inngest/cuetypescript"
 	"github.com/inngest/event-schemas/events/marshalling/jsonschema"
+	"github.com/inngest/inngest/pkg/event"
 )
 
 // DefinitionFormat specifies how the event is typed.
@@ -60,6 +66,72 @@ func (ed *EventDefinition) Validate(ctx context.Context) error {
 	return nil
 }
 
+// ValidateEvent validates the given event against the event definition,
+// returning an error describing any fields which don't match.  This compiles
+// the definition on each call;  use Schema to validate many events.
+func (ed *EventDefinition) ValidateEvent(ctx context.Context, evt event.Event) error {
+	schema, err := ed.Schema(ctx)
+	if err != nil {
+		return err
+	}
+	return schema.ValidateEvent(evt)
+}
+
+// Schema compiles the event definition, returning a schema which validates
+// events without recompiling the definition.
+func (ed *EventDefinition) Schema(ctx context.Context) (*Schema, error) {
+	def := ed.cueType
+	if def == "" {
+		// Convert a copy of the definition, as definitions may be shared
+		// between goroutines.
+		copied := *ed
+		if err := copied.createCueType(ctx); err != nil {
+			return nil, err
+		}
+		def = copied.cueType
+	}
+
+	cctx := cuecontext.New()
+	val := cctx.CompileString(def)
+	if err := val.Err(); err != nil {
+		return nil, fmt.Errorf("error compiling event definition: %w", err)
+	}
+	return &Schema{cctx: cctx, val: val}, nil
+}
+
+// Schema is a compiled event definition.  Schemas are safe for concurrent use.
+type Schema struct {
+	// l guards the cue context, which isn't safe for concurrent use.
+	l    sync.Mutex
+	cctx *cue.Context
+	val  cue.Value
+}
+
+// ValidateEvent validates the given event against the schema, returning an
+// error describing any fields which don't match.
+func (s *Schema) ValidateEvent(evt event.Event) error {
+	byt, err := json.Marshal(evt.Map())
+	if err != nil {
+		return err
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	data := s.cctx.CompileBytes(byt)
+	if err := data.Err(); err != nil {
+		return fmt.Errorf("error compiling event: %w", err)
+	}
+
+	val := s.val.Unify(data)
+	if err := val.Validate(cue.Final(), cue.Concrete(true)); err != nil {
+		buf := &bytes.Buffer{}
+		cueerrors.Print(buf, err, nil)
+		return fmt.Errorf("event does not match definition: %s", strings.TrimSpace(buf.String()))
+	}
+	return nil
+}
+
 // createCueType converts the Def input into Cue.
 func (ed *EventDefinition) createCueType(ctx context.Context) error {
 	if ed.cueType != "" {
@@ -174,11 +174,33 @@ func (f Function) Validate(ctx context.Context) error {
 				err = multierror.Append(err, verr)
 			}
 		}
+		if edge.Metadata != nil && edge.Metadata.AsyncEdgeMetadata != nil && edge.Metadata.AsyncEdgeMetadata.Match != nil {
+			if _, verr := expressions.NewExpressionEvaluator(ctx, *edge.Metadata.AsyncEdgeMetadata.Match); verr != nil {
+				err = multierror.Append(err, verr)
+			}
+		}
 	}
 
 	return err
 }
 
+// ValidateGraph statically analyses the function's steps, returning an error
+// for cycles, steps which can never run, invalid durations and expressions
+// which reference steps that won't have run.  This is run when validating,
+// deploying or developing functions, but not when loading functions that are
+// already deployed, so that existing functions continue to run.
+func (f Function) ValidateGraph(ctx context.Context) error {
+	if err := f.Validate(ctx); err != nil {
+		// The graph can't be analysed with unknown steps.
+		return err
+	}
+	_, edges, err := f.Actions(ctx)
+	if err != nil {
+		return err
+	}
+	return f.validateGraph(ctx, edges)
+}
+
 // Workflow produces the workflow.cue definition for a function.  Our executor
 // runs a "workflow", which is a DAG of the function steps.  Its a subset of
 // the function used purely for execution.
@@ -9,6 +9,7 @@ import (
 	"github.com/inngest/inngest/inngest"
 	"github.com/inngest/inngest/inngest/clistate"
 	"github.com/inngest/inngest/internal/cuedefs"
+	"github.com/inngest/inngest/pkg/event"
 	"github.com/stretchr/testify/require"
 )
 
@@ -397,3 +398,71 @@ func TestDeterministicUUID(t *testing.T) {
 func strptr(s string) *string {
 	return &s
 }
+
+func TestEventDefinitionValidateEvent(t *testing.T) {
+	ctx := context.Background()
+
+	cueDef := &EventDefinition{
+		Format: FormatCue,
+		Def: `{
+  name: "test/signup"
+  data: {
+    email: string
+    plan?: "free" | "pro"
+  }
+}`,
+	}
+	jsonDef := &EventDefinition{
+		Format: FormatJSONSchema,
+		Def: `{
+  "type": "object",
+  "properties": {
+    "name": { "const": "test/signup" },
+    "data": {
+      "type": "object",
+      "properties": { "email": { "type": "string" } },
+      "required": ["email"]
+    }
+  },
+  "required": ["name", "data"]
+}`,
+	}
+
+	tests := []struct {
+		name  string
+		evt   event.Event
+		valid bool
+	}{
+		{
+			name:  "valid event",
+			evt:   event.Event{Name: "test/signup", Data: map[string]interface{}{"email": "a@example.com", "plan": "pro"}},
+			valid: true,
+		},
+		{
+			name:  "additional fields",
+			evt:   event.Event{ID: "id", Name: "test/signup", Timestamp: 1, Data: map[string]interface{}{"email": "a@example.com", "extra": 1}},
+			valid: true,
+		},
+		{
+			name: "missing field",
+			evt:  event.Event{Name: "test/signup", Data: map[string]interface{}{}},
+		},
+		{
+			name: "invalid type",
+			evt:  event.Event{Name: "test/signup", Data: map[string]interface{}{"email": 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := cueDef.ValidateEvent(ctx, test.evt)
+			require.Equal(t, test.valid, err == nil, "%v", err)
+			err = jsonDef.ValidateEvent(ctx, test.evt)
+			require.Equal(t, test.valid, err == nil, "%v", err)
+		})
+	}
+
+	err := cueDef.ValidateEvent(ctx, event.Event{Name: "test/signup", Data: map[string]interface{}{"email": "a", "plan": "enterprise"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "plan")
+}
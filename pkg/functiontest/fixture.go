@@ -0,0 +1,126 @@
+// Package functiontest runs fixture-driven tests of functions.  Each fixture
+// triggers a function with an event, mocks the output of the function's steps,
+// and lists the expected results of the run.
+package functiontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/function"
+)
+
+const (
+	// FixtureSuffix is the suffix of fixture files, which are stored next to
+	// the function's config.
+	FixtureSuffix = ".fixture.json"
+
+	// DefaultTimeout is the maximum time to wait for a fixture's run to
+	// complete, if the fixture doesn't specify a timeout.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Fixture is a single test case for a function.
+type Fixture struct {
+	// Name is the name of the test case, defaulting to the fixture's
+	// filename.
+	Name string `json:"name"`
+	// Event is the event which triggers the function.
+	Event event.Event `json:"event"`
+	// Steps mocks the response of each step, keyed by step ID.  Steps which
+	// aren't mocked complete with no output.
+	Steps map[string]StepMock `json:"steps"`
+	// Expect lists the expected results of the run.
+	Expect Expect `json:"expect"`
+	// Timeout is the maximum time to wait for the run to complete, eg.
+	// "30s", defaulting to DefaultTimeout.  Waits and pause timeouts are
+	// fast-forwarded, so don't count towards this timeout.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Path is the path of the fixture file.
+	Path string `json:"-"`
+}
+
+// StepMock is the mocked response of a step.
+type StepMock struct {
+	// Output is the step's output.
+	Output map[string]interface{} `json:"output,omitempty"`
+	// Error, if set, fails the step with the given message.  Mocked errors
+	// are not retried.
+	Error string `json:"error,omitempty"`
+}
+
+// Expect lists the expected results of a run.  Each field is optional;  empty
+// fields aren't asserted.
+type Expect struct {
+	// Ran lists every step which should run, in any order.
+	Ran []string `json:"ran,omitempty"`
+	// NotRan lists steps which shouldn't run, eg. the steps of branches
+	// whose edge expressions don't match.
+	NotRan []string `json:"notRan,omitempty"`
+	// Outputs lists the expected output of steps, keyed by step ID.
+	Outputs map[string]map[string]interface{} `json:"outputs,omitempty"`
+	// Errors lists the expected error message of failed steps, keyed by
+	// step ID.
+	Errors map[string]string `json:"errors,omitempty"`
+	// Status is the expected status of the run, either "completed" or
+	// "failed".
+	Status string `json:"status,omitempty"`
+}
+
+// timeout returns the fixture's timeout.
+func (f Fixture) timeout() (time.Duration, error) {
+	if f.Timeout == "" {
+		return DefaultTimeout, nil
+	}
+	d, err := time.ParseDuration(f.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	return d, nil
+}
+
+// LoadFixtures loads every fixture stored next to the given function's config,
+// ordered by filename.
+func LoadFixtures(fn function.Function) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(fn.Dir(), "*"+FixtureSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]Fixture, len(paths))
+	for n, path := range paths {
+		if fixtures[n], err = LoadFixture(path); err != nil {
+			return nil, err
+		}
+	}
+	return fixtures, nil
+}
+
+// LoadFixture loads a single fixture file.
+func LoadFixture(path string) (Fixture, error) {
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+
+	f := Fixture{}
+	if err := json.Unmarshal(byt, &f); err != nil {
+		return Fixture{}, fmt.Errorf("error parsing fixture %s: %w", path, err)
+	}
+	if f.Event.Name == "" {
+		return Fixture{}, fmt.Errorf("fixture %s has no event name", path)
+	}
+	if f.Name == "" {
+		f.Name = strings.TrimSuffix(filepath.Base(path), FixtureSuffix)
+	}
+	f.Path = path
+	return f, nil
+}
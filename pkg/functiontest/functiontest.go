@@ -0,0 +1,387 @@
+package functiontest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/inngest/inngest/pkg/clock"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/config/registration"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/execution/driver/mockdriver"
+	"github.com/inngest/inngest/pkg/execution/executor"
+	"github.com/inngest/inngest/pkg/execution/queue/inmemoryqueue"
+	"github.com/inngest/inngest/pkg/execution/runner"
+	"github.com/inngest/inngest/pkg/execution/state"
+	"github.com/inngest/inngest/pkg/execution/state/inmemory"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/inngest/inngest/pkg/service"
+)
+
+const (
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	// StatusTimeout is the status of runs which didn't complete within the
+	// fixture's timeout.
+	StatusTimeout = "timeout"
+
+	// pollInterval is the interval used to check whether a run has completed.
+	pollInterval = 5 * time.Millisecond
+)
+
+// Result is the result of running a single fixture.
+type Result struct {
+	// Function is the ID of the function tested.
+	Function string `json:"function"`
+	// Fixture is the name of the fixture.
+	Fixture string `json:"fixture"`
+	// Path is the path of the fixture file.
+	Path string `json:"path,omitempty"`
+	// Passed is true if every expectation matched.
+	Passed bool `json:"passed"`
+	// Failures lists each expectation which didn't match.
+	Failures []string `json:"failures,omitempty"`
+	// Status is the status of the run.
+	Status string `json:"status"`
+	// Ran lists the steps which ran, sorted by ID.
+	Ran []string `json:"ran"`
+	// Outputs lists the output of each step which completed.
+	Outputs map[string]map[string]interface{} `json:"outputs,omitempty"`
+	// Errors lists the error of each step which failed.
+	Errors map[string]string `json:"errors,omitempty"`
+	// Duration is the time taken to run the fixture.
+	Duration time.Duration `json:"duration"`
+}
+
+// Run runs the function using the given fixture, returning the result of
+// each expectation.  The function runs within an in-memory executor, with each
+// step's driver replaced by a mock returning the fixture's step responses.
+//
+// The executor uses a virtual clock which fast-forwards whenever the run is
+// idle, so that waits and pause timeouts complete immediately.
+//
+// This only returns an error if the function can't be run;  unmet
+// expectations are reported as failures within the result.
+func Run(ctx context.Context, fn function.Function, f Fixture) (Result, error) {
+	result := Result{
+		Function: fn.ID,
+		Fixture:  f.Name,
+		Path:     f.Path,
+		Outputs:  map[string]map[string]interface{}{},
+		Errors:   map[string]string{},
+	}
+
+	timeout, err := f.timeout()
+	if err != nil {
+		return result, err
+	}
+
+	flow, err := fn.Workflow(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	// Check that the fixture is valid for this function prior to running
+	// it, surfacing typos in step IDs as failures.
+	if !triggeredBy(fn, f.Event.Name) {
+		result.Failures = append(result.Failures, fmt.Sprintf("event %s doesn't trigger the function", f.Event.Name))
+	}
+	steps := map[string]struct{}{}
+	for _, step := range flow.Steps {
+		steps[step.ID] = struct{}{}
+	}
+	for _, id := range f.referencedSteps() {
+		if _, ok := steps[id]; !ok {
+			result.Failures = append(result.Failures, fmt.Sprintf("unknown step: %s", id))
+		}
+	}
+	if len(result.Failures) > 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	clk := clock.NewVirtual(time.Now())
+	c, err := newConfig(ctx, fn, f, clk)
+	if err != nil {
+		return result, err
+	}
+
+	el := &inmemorydatastore.MemoryExecutionLoader{}
+	if err := el.SetFunctions(ctx, []*function.Function{&fn}); err != nil {
+		return result, err
+	}
+
+	// The config returns in-memory singletons for the queue and state, which
+	// are shared with the executor.
+	sm, err := c.State.Service.Concrete.Manager(ctx)
+	if err != nil {
+		return result, err
+	}
+	q, err := c.Queue.Service.Concrete.Producer()
+	if err != nil {
+		return result, err
+	}
+	mq, ok := q.(inmemoryqueue.MemoryQueue)
+	if !ok {
+		return result, fmt.Errorf("unexpected queue: %T", q)
+	}
+
+	exec := executor.NewService(*c, executor.WithExecutionLoader(el), executor.WithClock(clk))
+	execErr := make(chan error, 1)
+	go func() {
+		execErr <- service.Start(ctx, exec)
+	}()
+
+	start := time.Now()
+	id, err := runner.InitializeAt(ctx, fn, f.Event, clk.Now(), sm, q)
+	if err != nil {
+		return result, err
+	}
+
+	s, err := waitForRun(ctx, sm, mq, *id, clk, timeout, execErr)
+	if err != nil {
+		return result, err
+	}
+	result.Duration = time.Since(start)
+
+	ran := map[string]struct{}{}
+	if ag, ok := s.(state.AttemptGetter); ok {
+		for stepID := range ag.Attempts() {
+			ran[stepID] = struct{}{}
+		}
+	}
+	for stepID := range s.Actions() {
+		ran[stepID] = struct{}{}
+	}
+	for stepID := range s.Errors() {
+		ran[stepID] = struct{}{}
+	}
+	for stepID := range ran {
+		result.Ran = append(result.Ran, stepID)
+	}
+	sort.Strings(result.Ran)
+	for stepID, output := range s.Actions() {
+		result.Outputs[stepID] = output
+	}
+	for stepID, err := range s.Errors() {
+		result.Errors[stepID] = err.Error()
+	}
+
+	switch {
+	case s.Metadata().Pending > 0:
+		result.Status = StatusTimeout
+		result.Failures = append(result.Failures, fmt.Sprintf("run didn't complete within %s", timeout))
+	case len(result.Errors) > 0:
+		result.Status = StatusFailed
+	default:
+		result.Status = StatusCompleted
+	}
+
+	result.Failures = append(result.Failures, f.Expect.check(result)...)
+	result.Passed = len(result.Failures) == 0
+	return result, nil
+}
+
+// newConfig returns an in-memory config which mocks every runtime used by the
+// function's steps, using the given clock within the queue and state store.
+func newConfig(ctx context.Context, fn function.Function, f Fixture, clk clock.Clock) (*config.Config, error) {
+	c, err := config.Dev(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qc, ok := c.Queue.Service.Concrete.(*inmemoryqueue.Config)
+	if !ok {
+		return nil, fmt.Errorf("unexpected queue backend: %s", c.Queue.Service.Backend)
+	}
+	qc.Clock = clk
+	sc, ok := c.State.Service.Concrete.(*inmemory.Config)
+	if !ok {
+		return nil, fmt.Errorf("unexpected state backend: %s", c.State.Service.Backend)
+	}
+	sc.Clock = clk
+
+	responses := map[string]state.DriverResponse{}
+	for id, mock := range f.Steps {
+		r := state.DriverResponse{Output: mock.Output}
+		if mock.Error != "" {
+			r.Err = errors.New(mock.Error)
+			r.SetFinal()
+		}
+		responses[id] = r
+	}
+
+	actions, _, err := fn.Actions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.Execution.Drivers = map[string]registration.DriverConfig{}
+	for _, a := range actions {
+		rt := a.Runtime.RuntimeType()
+		c.Execution.Drivers[rt] = &mockdriver.Config{Driver: rt, Responses: responses}
+	}
+	return c, nil
+}
+
+// waitForRun waits until the run has no pending steps, or until the timeout
+// elapses, returning the run's latest state.  Whenever the queue is idle the
+// clock is advanced to its next timer.
+func waitForRun(ctx context.Context, sm state.Manager, q inmemoryqueue.MemoryQueue, id state.Identifier, clk *clock.Virtual, timeout time.Duration, execErr <-chan error) (state.State, error) {
+	deadline := time.After(timeout)
+	for {
+		s, err := sm.Load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// Permanently failed steps are finalized by both the state store and
+		// the executor, so pending may drop below zero.
+		if s.Metadata().Pending <= 0 {
+			return s, nil
+		}
+
+		// The run is waiting on a timer if no steps are available or
+		// running.
+		if q.Idle() {
+			clk.AdvanceToNext()
+		}
+
+		select {
+		case err := <-execErr:
+			if err == nil {
+				err = fmt.Errorf("executor stopped")
+			}
+			return nil, err
+		case <-deadline:
+			return s, nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// triggeredBy returns whether the function has an event trigger for the given
+// event.
+func triggeredBy(fn function.Function, name string) bool {
+	for _, t := range fn.Triggers {
+		if t.EventTrigger != nil && t.Event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedSteps returns the ID of every step referenced within the fixture,
+// sorted by ID.
+func (f Fixture) referencedSteps() []string {
+	ids := map[string]struct{}{}
+	for id := range f.Steps {
+		ids[id] = struct{}{}
+	}
+	for _, id := range f.Expect.Ran {
+		ids[id] = struct{}{}
+	}
+	for _, id := range f.Expect.NotRan {
+		ids[id] = struct{}{}
+	}
+	for id := range f.Expect.Outputs {
+		ids[id] = struct{}{}
+	}
+	for id := range f.Expect.Errors {
+		ids[id] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// check returns a failure message for each expectation which doesn't match
+// the result.
+func (e Expect) check(r Result) []string {
+	failures := []string{}
+
+	ran := map[string]bool{}
+	for _, id := range r.Ran {
+		ran[id] = true
+	}
+
+	if e.Ran != nil {
+		expected := append([]string{}, e.Ran...)
+		sort.Strings(expected)
+		if strings.Join(expected, ",") != strings.Join(r.Ran, ",") {
+			failures = append(failures, fmt.Sprintf("expected steps [%s] to run, but [%s] ran", strings.Join(expected, ", "), strings.Join(r.Ran, ", ")))
+		}
+	}
+
+	for _, id := range e.NotRan {
+		if ran[id] {
+			failures = append(failures, fmt.Sprintf("expected step %s not to run", id))
+		}
+	}
+
+	outputs := make([]string, 0, len(e.Outputs))
+	for id := range e.Outputs {
+		outputs = append(outputs, id)
+	}
+	sort.Strings(outputs)
+	for _, id := range outputs {
+		actual, ok := r.Outputs[id]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("expected step %s to have output, but it didn't complete", id))
+			continue
+		}
+		if !jsonEqual(e.Outputs[id], actual) {
+			failures = append(failures, fmt.Sprintf("unexpected output for step %s: expected %s, got %s", id, marshal(e.Outputs[id]), marshal(actual)))
+		}
+	}
+
+	errs := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		errs = append(errs, id)
+	}
+	sort.Strings(errs)
+	for _, id := range errs {
+		actual, ok := r.Errors[id]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("expected step %s to fail, but it didn't", id))
+			continue
+		}
+		if actual != e.Errors[id] {
+			failures = append(failures, fmt.Sprintf("unexpected error for step %s: expected %q, got %q", id, e.Errors[id], actual))
+		}
+	}
+
+	if e.Status != "" && e.Status != r.Status {
+		failures = append(failures, fmt.Sprintf("expected the run to be %s, but it was %s", e.Status, r.Status))
+	}
+
+	return failures
+}
+
+// jsonEqual compares the values as JSON, ignoring differences in numeric
+// types.
+func jsonEqual(a, b interface{}) bool {
+	var x, y interface{}
+	if err := json.Unmarshal([]byte(marshal(a)), &x); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(marshal(b)), &y); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func marshal(v interface{}) string {
+	byt, _ := json.Marshal(v)
+	return string(byt)
+}
@@ -0,0 +1,114 @@
+package functiontest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/inngest/inngest/pkg/config/defaults"
+	"github.com/inngest/inngest/pkg/function"
+	"github.com/stretchr/testify/require"
+)
+
+func loadBranching(t *testing.T) (function.Function, []Fixture) {
+	t.Helper()
+	fn, err := function.Load(context.Background(), "./testdata/branching")
+	require.NoError(t, err)
+	fixtures, err := LoadFixtures(*fn)
+	require.NoError(t, err)
+	return *fn, fixtures
+}
+
+func TestLoadFixtures(t *testing.T) {
+	_, fixtures := loadBranching(t)
+	require.Len(t, fixtures, 2)
+
+	// Fixtures are sorted by filename, with the name defaulting to the
+	// filename.
+	require.Equal(t, "free plan, failed onboarding", fixtures[0].Name)
+	require.Equal(t, "premium", fixtures[1].Name)
+	require.Equal(t, "premium.fixture.json", filepath.Base(fixtures[1].Path))
+	require.Equal(t, "test/user.signup", fixtures[1].Event.Name)
+	require.Equal(t, map[string]interface{}{"plan": "premium"}, fixtures[1].Steps["check"].Output)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	fn, fixtures := loadBranching(t)
+
+	for _, f := range fixtures {
+		r, err := Run(ctx, fn, f)
+		require.NoError(t, err)
+		require.True(t, r.Passed, "%s: %v", f.Name, r.Failures)
+	}
+
+	r, err := Run(ctx, fn, fixtures[0])
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, r.Status)
+	require.Equal(t, []string{"check", "free"}, r.Ran)
+	require.Equal(t, map[string]string{"free": "email bounced"}, r.Errors)
+
+	r, err = Run(ctx, fn, fixtures[1])
+	require.NoError(t, err)
+	require.Equal(t, StatusCompleted, r.Status)
+	require.Equal(t, []string{"check", "premium"}, r.Ran)
+	require.Equal(t, map[string]interface{}{"sent": true}, r.Outputs["premium"])
+}
+
+func TestRunFailures(t *testing.T) {
+	ctx := context.Background()
+	fn, fixtures := loadBranching(t)
+
+	// Taking the free branch fails each expectation of the premium fixture.
+	f := fixtures[1]
+	f.Steps = map[string]StepMock{
+		"check": {Output: map[string]interface{}{"plan": "free"}},
+		"free":  {Output: map[string]interface{}{"sent": false}},
+	}
+	f.Expect.Outputs = map[string]map[string]interface{}{
+		"free":    {"sent": true},
+		"premium": {"sent": true},
+	}
+
+	r, err := Run(ctx, fn, f)
+	require.NoError(t, err)
+	require.False(t, r.Passed)
+	require.Equal(t, StatusCompleted, r.Status)
+	require.Equal(t, []string{
+		"expected steps [check, premium] to run, but [check, free] ran",
+		"expected step free not to run",
+		`unexpected output for step free: expected {"sent":true}, got {"sent":false}`,
+		"expected step premium to have output, but it didn't complete",
+	}, r.Failures)
+
+	// Fixtures referencing unknown steps or events fail without running.
+	f = fixtures[1]
+	f.Event.Name = "test/unknown"
+	f.Expect.NotRan = []string{"typo"}
+
+	r, err = Run(ctx, fn, f)
+	require.NoError(t, err)
+	require.False(t, r.Passed)
+	require.Empty(t, r.Ran)
+	require.Equal(t, []string{
+		"event test/unknown doesn't trigger the function",
+		"unknown step: typo",
+	}, r.Failures)
+}
+
+func TestRunWaits(t *testing.T) {
+	ctx := context.Background()
+	fn, err := function.Load(ctx, "./testdata/waits")
+	require.NoError(t, err)
+	fixtures, err := LoadFixtures(*fn)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 1)
+
+	// The 72h wait and 7d pause timeout are fast-forwarded.
+	r, err := Run(ctx, *fn, fixtures[0])
+	require.NoError(t, err)
+	require.True(t, r.Passed, "%v", r.Failures)
+	require.Equal(t, []string{"nudge", "reminder", "welcome"}, r.Ran)
+	require.Less(t, r.Duration, time.Second)
+}
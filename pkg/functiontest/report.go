@@ -0,0 +1,111 @@
+package functiontest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the results as a JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report, with a test suite for
+// each function and a test case for each fixture.
+func WriteJUnit(w io.Writer, results []Result) error {
+	report := junitSuites{}
+	suites := map[string]int{}
+	var total float64
+
+	for _, r := range results {
+		n, ok := suites[r.Function]
+		if !ok {
+			n = len(report.Suites)
+			suites[r.Function] = n
+			report.Suites = append(report.Suites, junitSuite{Name: r.Function})
+		}
+		suite := &report.Suites[n]
+
+		tc := junitCase{
+			Name:      r.Fixture,
+			Classname: r.Function,
+			File:      r.Path,
+			Time:      seconds(r.Duration.Seconds()),
+		}
+		if !r.Passed {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d expectations failed", len(r.Failures)),
+				Body:    strings.Join(r.Failures, "\n"),
+			}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		report.Tests++
+		total += r.Duration.Seconds()
+	}
+
+	for n, suite := range report.Suites {
+		var t float64
+		for _, r := range results {
+			if r.Function == suite.Name {
+				t += r.Duration.Seconds()
+			}
+		}
+		report.Suites[n].Time = seconds(t)
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
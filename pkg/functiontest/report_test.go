@@ -0,0 +1,67 @@
+package functiontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var reportResults = []Result{
+	{
+		Function: "fn-a",
+		Fixture:  "passes",
+		Passed:   true,
+		Status:   StatusCompleted,
+		Ran:      []string{"step-1"},
+		Duration: 1500 * time.Millisecond,
+	},
+	{
+		Function: "fn-a",
+		Fixture:  "fails",
+		Failures: []string{"expected step step-2 not to run", "expected the run to be failed, but it was completed"},
+		Status:   StatusCompleted,
+		Ran:      []string{"step-1", "step-2"},
+		Duration: 500 * time.Millisecond,
+	},
+	{
+		Function: "fn-b",
+		Fixture:  "passes",
+		Passed:   true,
+		Status:   StatusCompleted,
+		Duration: 250 * time.Millisecond,
+	},
+}
+
+func TestWriteJUnit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteJUnit(buf, reportResults))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" time="2.250">
+  <testsuite name="fn-a" tests="2" failures="1" time="2.000">
+    <testcase name="passes" classname="fn-a" time="1.500"></testcase>
+    <testcase name="fails" classname="fn-a" time="0.500">
+      <failure message="2 expectations failed">expected step step-2 not to run&#xA;expected the run to be failed, but it was completed</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="fn-b" tests="1" failures="0" time="0.250">
+    <testcase name="passes" classname="fn-b" time="0.250"></testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteJSON(buf, reportResults))
+
+	results := []Result{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	require.Equal(t, reportResults, results)
+
+	buf.Reset()
+	require.NoError(t, WriteJSON(buf, nil))
+	require.Equal(t, "[]\n", buf.String())
+}
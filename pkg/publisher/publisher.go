@@ -0,0 +1,294 @@
+// Package publisher sends events and finished function runs to outbound webhook
+// subscriptions, recording each attempt within the delivery log.  Runners
+// forward each event to the publisher's topic, and executors publish finished
+// runs to the publisher's topic.
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/inngest/inngest/pkg/backoff"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/expressions"
+	"github.com/inngest/inngest/pkg/logger"
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/inngest/inngest/pkg/service"
+)
+
+const (
+	// concurrency is the number of messages handled at once.
+	concurrency = 10
+	// requestTimeout is the timeout for each delivery attempt.
+	requestTimeout = 30 * time.Second
+
+	HeaderSubscription = "X-Inngest-Subscription"
+	HeaderDelivery     = "X-Inngest-Delivery"
+	HeaderEvent        = "X-Inngest-Event"
+	HeaderSignature    = "X-Inngest-Signature"
+)
+
+type Opt func(s *svc)
+
+// WithDeliveryStore sets the store used to record each delivery attempt.  If
+// this isn't provided, the configured data store is used.
+func WithDeliveryStore(d coredata.DeliveryWriter) func(s *svc) {
+	return func(s *svc) {
+		s.deliveries = d
+	}
+}
+
+func NewService(c config.Config, opts ...Opt) service.Service {
+	svc := &svc{
+		config:  c,
+		client:  &http.Client{Timeout: requestTimeout},
+		backoff: backoff.LinearJitterBackoff,
+	}
+	for _, o := range opts {
+		o(svc)
+	}
+	return svc
+}
+
+type svc struct {
+	config config.Config
+	// pubsub allows us to subscribe to the event stream.
+	pubsub pubsub.Subscriber
+	// deliveries records each delivery attempt.
+	deliveries coredata.DeliveryWriter
+	// subscriptions lists subscriptions with their expressions precompiled.
+	subscriptions []subscription
+	client        *http.Client
+	// backoff returns the time of the next attempt for the given attempt
+	// number.
+	backoff func(attempt int) time.Time
+}
+
+type subscription struct {
+	config.Subscription
+	expr expressions.Evaluable
+}
+
+func (s svc) Name() string {
+	return "publisher"
+}
+
+func (s *svc) Pre(ctx context.Context) error {
+	var err error
+
+	if s.deliveries == nil {
+		s.deliveries, err = s.config.DataStore.Service.Concrete.ReadWriter(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.subscriptions, err = newSubscriptions(ctx, s.config.Publisher.Subscriptions)
+	if err != nil {
+		return err
+	}
+
+	s.pubsub, err = pubsub.NewSubscriber(ctx, s.config.EventStream.Service)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *svc) Run(ctx context.Context) error {
+	if !s.config.Publisher.Enabled() {
+		// Nothing is published to the topic without subscriptions.
+		logger.From(ctx).Info().Msg("no subscriptions configured")
+		<-ctx.Done()
+		return nil
+	}
+
+	logger.From(ctx).Info().
+		Str("topic", s.config.Publisher.Topic).
+		Int("subscriptions", len(s.subscriptions)).
+		Msg("subscribing to events")
+	return s.pubsub.SubscribeN(
+		pubsub.WithConsumerGroup(ctx, s.Name()),
+		s.config.Publisher.Topic,
+		s.handleMessage,
+		concurrency,
+	)
+}
+
+func (s *svc) Stop(ctx context.Context) error {
+	return pubsub.Close(s.pubsub)
+}
+
+// newSubscriptions compiles each subscription's expression.
+func newSubscriptions(ctx context.Context, subs []config.Subscription) ([]subscription, error) {
+	result := make([]subscription, len(subs))
+	for n, sub := range subs {
+		result[n] = subscription{Subscription: sub}
+		if sub.Expression == nil {
+			continue
+		}
+		expr, err := expressions.NewExpressionEvaluator(ctx, *sub.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for subscription '%s': %w", sub.ID, err)
+		}
+		result[n].expr = expr
+	}
+	return result, nil
+}
+
+func (s *svc) handleMessage(ctx context.Context, m pubsub.Message) error {
+	if m.Name != event.EventReceivedName && m.Name != event.RunFinishedName {
+		return nil
+	}
+
+	evt := event.Event{}
+	if err := json.Unmarshal([]byte(m.Data), &evt); err != nil {
+		return fmt.Errorf("error unmarshalling event: %w", err)
+	}
+
+	s.publish(ctx, evt)
+	return nil
+}
+
+// publish sends the event to every matching subscription, blocking until
+// each delivery succeeds or permanently fails.
+func (s *svc) publish(ctx context.Context, evt event.Event) {
+	data := expressions.NewData(map[string]interface{}{"event": evt.Map()})
+
+	wg := sync.WaitGroup{}
+	for _, sub := range s.subscriptions {
+		if !sub.Matches(evt.Name) {
+			continue
+		}
+		if sub.expr != nil {
+			ok, _, err := sub.expr.Evaluate(ctx, data)
+			if err != nil {
+				logger.From(ctx).Warn().Err(err).Str("subscription", sub.ID).Msg("error evaluating subscription expression")
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		copied := sub
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.deliver(ctx, copied, evt)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends the event to the subscription, retrying with backoff until the
+// event is delivered, the error isn't retryable, or attempts are exhausted.
+func (s *svc) deliver(ctx context.Context, sub subscription, evt event.Event) {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		logger.From(ctx).Error().Err(err).Msg("error marshalling event")
+		return
+	}
+
+	// The delivery ID is deterministic, allowing subscribers to deduplicate
+	// events redelivered from the event stream.
+	id := fmt.Sprintf("%s:%s", sub.ID, evt.ID)
+
+	attempts := s.config.Publisher.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		status, err := s.send(ctx, sub, id, evt.Name, body)
+
+		d := coredata.Delivery{
+			ID:             id,
+			SubscriptionID: sub.ID,
+			URL:            sub.URL,
+			EventID:        evt.ID,
+			EventName:      evt.Name,
+			Attempt:        attempt,
+			StatusCode:     status,
+			At:             time.Now(),
+		}
+		if err != nil {
+			d.Error = err.Error()
+		}
+		if serr := s.deliveries.SaveDelivery(ctx, d); serr != nil {
+			logger.From(ctx).Error().Err(serr).Str("delivery", id).Msg("error saving delivery")
+		}
+
+		if err == nil {
+			return
+		}
+
+		l := logger.From(ctx).Warn().Err(err).Str("delivery", id).Int("attempt", attempt)
+		if !retryable(status) || attempt == attempts {
+			l.Msg("delivery failed")
+			return
+		}
+		l.Msg("delivery failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(s.backoff(attempt))):
+		}
+	}
+}
+
+// send POSTs the event body to the subscription, returning the response's
+// status code.
+func (s *svc) send(ctx context.Context, sub subscription, id, name string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSubscription, sub.ID)
+	req.Header.Set(HeaderDelivery, id)
+	req.Header.Set(HeaderEvent, name)
+	if sub.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(sub.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature of the body sent within the X-Inngest-Signature
+// header, in the format "sha256=<hex encoded HMAC-SHA256>".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryable returns whether a delivery which failed with the given status
+// code can be retried.  A status code of 0 indicates that no response was
+// received.
+func retryable(status int) bool {
+	return status == 0 ||
+		status >= 500 ||
+		status == http.StatusTooManyRequests ||
+		status == http.StatusRequestTimeout
+}
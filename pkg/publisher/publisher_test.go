@@ -0,0 +1,190 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/coredata"
+	inmemorydatastore "github.com/inngest/inngest/pkg/coredata/inmemory"
+	"github.com/inngest/inngest/pkg/event"
+	"github.com/inngest/inngest/pkg/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records requests sent to a test server, responding with the
+// given status codes in order.
+type receiver struct {
+	lock     sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newTestService(t *testing.T, subs ...config.Subscription) (*svc, *inmemorydatastore.MemoryDeliveryStore) {
+	t.Helper()
+
+	c := config.Config{}
+	c.Publisher.MaxAttempts = 3
+	store := inmemorydatastore.NewInMemoryDeliveryStore()
+	s := NewService(c, WithDeliveryStore(store)).(*svc)
+	s.backoff = func(attempt int) time.Time { return time.Now() }
+
+	var err error
+	s.subscriptions, err = newSubscriptions(context.Background(), subs)
+	require.NoError(t, err)
+	return s, store
+}
+
+func message(t *testing.T, name string, evt event.Event) pubsub.Message {
+	byt, err := json.Marshal(evt)
+	require.NoError(t, err)
+	return pubsub.Message{Name: name, Data: string(byt), Timestamp: time.Now()}
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+
+	billing := &receiver{}
+	billingSrv := httptest.NewServer(billing)
+	defer billingSrv.Close()
+
+	runs := &receiver{}
+	runsSrv := httptest.NewServer(runs)
+	defer runsSrv.Close()
+
+	expr := "event.data.total > 100"
+	s, store := newTestService(
+		t,
+		config.Subscription{
+			ID:         "billing",
+			URL:        billingSrv.URL,
+			Event:      "billing/*",
+			Expression: &expr,
+			Secret:     "shh",
+		},
+		config.Subscription{
+			ID:    "runs",
+			URL:   runsSrv.URL,
+			Event: event.FunctionFinishedName,
+		},
+	)
+
+	// Non-matching names and expressions are ignored.
+	require.NoError(t, s.handleMessage(ctx, message(t, event.EventReceivedName, event.Event{
+		ID: "1", Name: "user/created", Data: map[string]interface{}{"total": 500},
+	})))
+	require.NoError(t, s.handleMessage(ctx, message(t, event.EventReceivedName, event.Event{
+		ID: "2", Name: "billing/paid", Data: map[string]interface{}{"total": 5},
+	})))
+	require.Empty(t, billing.requests)
+
+	require.NoError(t, s.handleMessage(ctx, message(t, event.EventReceivedName, event.Event{
+		ID: "3", Name: "billing/paid", Data: map[string]interface{}{"total": 500},
+	})))
+	require.Len(t, billing.requests, 1)
+
+	req := billing.requests[0]
+	require.Equal(t, "billing", req.Header.Get(HeaderSubscription))
+	require.Equal(t, "billing:3", req.Header.Get(HeaderDelivery))
+	require.Equal(t, "billing/paid", req.Header.Get(HeaderEvent))
+	require.Equal(t, Sign("shh", billing.bodies[0]), req.Header.Get(HeaderSignature))
+
+	sent := event.Event{}
+	require.NoError(t, json.Unmarshal(billing.bodies[0], &sent))
+	require.Equal(t, "3", sent.ID)
+
+	// Finished runs are sent to run subscriptions.
+	require.NoError(t, s.handleMessage(ctx, message(t, event.RunFinishedName, event.Event{
+		ID: "run", Name: event.FunctionFinishedName, Data: map[string]interface{}{"status": "completed"},
+	})))
+	require.Len(t, runs.requests, 1)
+	require.Empty(t, runs.requests[0].Header.Get(HeaderSignature))
+
+	deliveries, err := store.Deliveries(ctx, coredata.DeliveryQuery{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	for _, d := range deliveries {
+		require.True(t, d.Succeeded())
+		require.Equal(t, 1, d.Attempt)
+		require.Equal(t, http.StatusOK, d.StatusCode)
+	}
+}
+
+func TestPublish_retries(t *testing.T) {
+	ctx := context.Background()
+
+	r := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	s, store := newTestService(t, config.Subscription{ID: "sub", URL: srv.URL, Event: "test/event"})
+	s.publish(ctx, event.Event{ID: "1", Name: "test/event"})
+
+	require.Len(t, r.requests, 3)
+	sub := "sub"
+	deliveries, err := store.Deliveries(ctx, coredata.DeliveryQuery{SubscriptionID: &sub})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	require.Equal(t, 3, deliveries[0].Attempt)
+	require.True(t, deliveries[0].Succeeded())
+	require.Equal(t, http.StatusInternalServerError, deliveries[2].StatusCode)
+	require.Equal(t, "unexpected status code: 500", deliveries[2].Error)
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		r := &receiver{statuses: []int{http.StatusBadRequest}}
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		s, store := newTestService(t, config.Subscription{ID: "sub", URL: srv.URL, Event: "test/event"})
+		s.publish(ctx, event.Event{ID: "1", Name: "test/event"})
+		require.Len(t, r.requests, 1)
+
+		deliveries, err := store.Deliveries(ctx, coredata.DeliveryQuery{})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.False(t, deliveries[0].Succeeded())
+	})
+
+	t.Run("attempts are limited", func(t *testing.T) {
+		r := &receiver{statuses: []int{500, 500, 500, 500}}
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		s, _ := newTestService(t, config.Subscription{ID: "sub", URL: srv.URL, Event: "test/event"})
+		s.publish(ctx, event.Event{ID: "1", Name: "test/event"})
+		require.Len(t, r.requests, 3)
+	})
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	sub := config.Subscription{Event: "billing/*"}
+	require.True(t, sub.Matches("billing/paid"))
+	require.False(t, sub.Matches("user/created"))
+
+	sub = config.Subscription{Event: "billing/paid"}
+	require.True(t, sub.Matches("billing/paid"))
+	require.False(t, sub.Matches("billing/paid.v2"))
+}
@@ -27,6 +27,14 @@ func NewSubscriber(ctx context.Context, c config.MessagingService) (Subscriber,
 }
 
 func NewPublishSubscriber(ctx context.Context, c config.MessagingService) (PublishSubscriber, error) {
+	// Redis Streams isn't supported by gocloud, and has its own implementation.
+	switch r := c.Concrete.(type) {
+	case *config.RedisMessaging:
+		return newRedisBroker(ctx, *r)
+	case config.RedisMessaging:
+		return newRedisBroker(ctx, r)
+	}
+
 	b := &broker{
 		conf:   c,
 		topics: map[string]*pubsub.Topic{},
@@ -182,6 +190,13 @@ func (b *broker) SubscribeN(ctx context.Context, topic string, run PerformFunc,
 		}(msg)
 	}
 
+	// Wait for in-flight messages to be processed, then shut down the
+	// subscription to flush any pending acks.
+	wg.Wait()
+	if err := subs.Shutdown(context.Background()); err != nil {
+		logger.From(ctx).Warn().Err(err).Msg("error shutting down subscription")
+	}
+
 	if errors.Is(unrecoverableErr, context.Canceled) {
 		// There's no need to error here, and an implicit race on sem acquisition
 		// in which we only set the ctx cancelled error if it came from sem.Acquire,
@@ -3,6 +3,7 @@ package pubsub
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"time"
 )
 
@@ -48,3 +49,13 @@ type PublishSubscriber interface {
 	Publisher
 	Subscriber
 }
+
+// Close closes the given publisher or subscriber if its backend holds a
+// connection, such as Redis.  This should be called when shutting down, once
+// no more messages are published.
+func Close(v interface{}) error {
+	if c, ok := v.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,405 @@
+package pubsub
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/inngest/inngest/pkg/logger"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	// redisBlockTimeout is the time that each read blocks waiting for new
+	// messages, after which pending messages are checked and the context's
+	// cancellation is respected.
+	redisBlockTimeout = time.Second
+	// redisClaimCount is the maximum number of pending messages reclaimed at
+	// once.
+	redisClaimCount = 100
+
+	defaultReclaimAfter  = time.Minute
+	defaultMaxDeliveries = 10
+
+	// redisFieldMessage is the stream entry field containing the encoded
+	// message.
+	redisFieldMessage = "message"
+)
+
+type groupCtxKey struct{}
+
+type group struct {
+	name      string
+	ephemeral bool
+}
+
+// WithConsumerGroup returns a context which subscribes using the given
+// consumer group, for backends that support consumer groups.  Subscribers
+// within the same group share messages between them, while each group receives
+// every message.  Services should subscribe using their own group so that, eg.
+// the runner and publisher both receive every event.
+func WithConsumerGroup(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, groupCtxKey{}, group{name: name})
+}
+
+// WithEphemeralConsumerGroup returns a context which subscribes using the given
+// consumer group in the same manner as WithConsumerGroup.  Ephemeral groups only
+// receive messages published after subscribing, and are removed once the
+// subscription stops.  This is used by subscribers which only need messages
+// while running, such as API instances streaming updates.
+func WithEphemeralConsumerGroup(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, groupCtxKey{}, group{name: name, ephemeral: true})
+}
+
+// consumerGroup returns the consumer group stored within the context, or the
+// given default.
+func consumerGroup(ctx context.Context, def string) group {
+	if g, ok := ctx.Value(groupCtxKey{}).(group); ok && g.name != "" {
+		return g
+	}
+	return group{name: def}
+}
+
+// newRedisBroker returns a PublishSubscriber which uses Redis Streams.  Each
+// topic is a stream, which subscribers read via consumer groups.  Messages are
+// acknowledged once handled successfully;  messages which error or whose
+// consumer stops remain pending and are reclaimed after ReclaimAfter.
+//
+// This requires Redis 6.2 or later, as pending messages are reclaimed using
+// XAUTOCLAIM.
+func newRedisBroker(ctx context.Context, c config.RedisMessaging) (*redisBroker, error) {
+	opts, err := redisOpts(c)
+	if err != nil {
+		return nil, err
+	}
+	r := redis.NewClient(opts)
+	if err := r.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("error connecting to redis: %w", err)
+	}
+
+	host, _ := os.Hostname()
+
+	b := &redisBroker{
+		conf:          c,
+		r:             r,
+		consumer:      fmt.Sprintf("%s-%s", host, ulid.MustNew(ulid.Now(), rand.Reader)),
+		reclaimAfter:  time.Duration(c.ReclaimAfter) * time.Second,
+		maxDeliveries: int64(c.MaxDeliveries),
+	}
+	if b.reclaimAfter <= 0 {
+		b.reclaimAfter = defaultReclaimAfter
+	}
+	if b.maxDeliveries <= 0 {
+		b.maxDeliveries = defaultMaxDeliveries
+	}
+	return b, nil
+}
+
+func redisOpts(c config.RedisMessaging) (*redis.Options, error) {
+	if c.DSN != nil {
+		return redis.ParseURL(*c.DSN)
+	}
+	return &redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		DB:       c.DB,
+		Username: c.Username,
+		Password: c.Password,
+	}, nil
+}
+
+type redisBroker struct {
+	conf config.RedisMessaging
+	r    redis.UniversalClient
+	// consumer is the unique name of this broker within each consumer group.
+	consumer string
+	// reclaimAfter is the time that messages may be pending before they're
+	// reclaimed by another consumer.
+	reclaimAfter time.Duration
+	// maxDeliveries is the number of times a message is delivered before it's
+	// dropped.
+	maxDeliveries int64
+	// subs tracks running subscriptions, which must stop before the client
+	// is closed.
+	subs sync.WaitGroup
+}
+
+// Close waits for all subscriptions to stop, then closes the connection to
+// Redis.  Subscriptions stop once their context is cancelled.
+func (b *redisBroker) Close() error {
+	b.subs.Wait()
+	return b.r.Close()
+}
+
+// Publish adds the message to the topic's stream.
+func (b *redisBroker) Publish(ctx context.Context, topic string, m Message) error {
+	body, err := m.Encode()
+	if err != nil {
+		return fmt.Errorf("error encoding message: %w", err)
+	}
+
+	logger.From(ctx).Debug().Interface("event", m.Name).Str("topic", topic).Msg("publishing event")
+
+	err = b.r.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.conf.StreamKey(topic),
+		MaxLen: b.conf.MaxLen,
+		Approx: b.conf.MaxLen > 0,
+		Values: map[string]interface{}{
+			"name":            m.Name,
+			redisFieldMessage: string(body),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("error publishing event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to a topic, invoking the given run function consecutively
+// in a single threaded manner each time an event is received.
+func (b *redisBroker) Subscribe(ctx context.Context, topic string, run PerformFunc) error {
+	return b.SubscribeN(ctx, topic, run, 1)
+}
+
+// SubscribeN subscribes to the given topic using the context's consumer group,
+// running up to concurrency messages at once.  It blocks until the given context
+// is cancelled, and returns a nil error when shutting down from a cancelled context.
+func (b *redisBroker) SubscribeN(ctx context.Context, topic string, run PerformFunc, concurrency int64) error {
+	b.subs.Add(1)
+	defer b.subs.Done()
+
+	g := consumerGroup(ctx, b.conf.Group)
+	s := &redisSubscription{
+		b:        b,
+		stream:   b.conf.StreamKey(topic),
+		group:    g.name,
+		inflight: map[string]struct{}{},
+		// Pending messages are first reclaimed after the reclaim timeout,
+		// giving consumers which are restarting time to resume.
+		lastReclaim: time.Now(),
+	}
+
+	// Create the group.  Groups read every message within the stream, so that
+	// messages published before the service first subscribes aren't lost,
+	// whereas ephemeral groups only read messages published from now on.  The
+	// group may already exist if another consumer created it.
+	start := "0"
+	if g.ephemeral {
+		start = "$"
+	}
+	err := b.r.XGroupCreateMkStream(ctx, s.stream, s.group, start).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("error creating consumer group: %w", err)
+	}
+	if g.ephemeral {
+		defer func() {
+			// Remove the group using a new context, as the subscription's
+			// context is cancelled when shutting down.
+			if err := b.r.XGroupDestroy(context.Background(), s.stream, s.group).Err(); err != nil {
+				logger.From(ctx).Error().Err(err).Str("group", s.group).Msg("error removing consumer group")
+			}
+		}()
+	}
+
+	// As with the gocloud broker, we acquire capacity prior to reading a
+	// message so that we never hold messages that we can't process.
+	sem := semaphore.NewWeighted(concurrency)
+	wg := sync.WaitGroup{}
+
+	var unrecoverableErr error
+	for {
+		if err := sem.Acquire(ctx, 1); err != nil || ctx.Err() != nil {
+			break
+		}
+
+		msg, err := s.next(ctx)
+		if err != nil || msg == nil {
+			sem.Release(1)
+			if ctx.Err() != nil {
+				break
+			}
+			if err != nil {
+				unrecoverableErr = err
+				break
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(msg redis.XMessage) {
+			defer sem.Release(1)
+			defer wg.Done()
+			s.handle(ctx, msg, run)
+		}(*msg)
+	}
+
+	wg.Wait()
+
+	if unrecoverableErr != nil && ctx.Err() == nil {
+		return unrecoverableErr
+	}
+	return nil
+}
+
+// redisSubscription reads messages from a single stream for a consumer group.
+type redisSubscription struct {
+	b      *redisBroker
+	stream string
+	group  string
+
+	// claimed stores messages reclaimed from other consumers, which are
+	// handled before reading new messages.
+	claimed     []redis.XMessage
+	lastReclaim time.Time
+
+	// inflight stores the IDs of messages currently being handled, so that
+	// we don't reclaim our own messages.
+	inflight map[string]struct{}
+	lock     sync.RWMutex
+}
+
+// next returns the next message to handle, or nil if no message was received
+// within the block timeout.
+func (s *redisSubscription) next(ctx context.Context) (*redis.XMessage, error) {
+	if len(s.claimed) == 0 && time.Since(s.lastReclaim) >= s.b.reclaimAfter {
+		if err := s.reclaim(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.claimed) > 0 {
+		msg := s.claimed[0]
+		s.claimed = s.claimed[1:]
+		s.track(msg.ID)
+		return &msg, nil
+	}
+
+	streams, err := s.b.r.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.b.consumer,
+		Streams:  []string{s.stream, ">"},
+		Count:    1,
+		Block:    redisBlockTimeout,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading from stream: %w", err)
+	}
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			s.track(msg.ID)
+			return &msg, nil
+		}
+	}
+	return nil, nil
+}
+
+// reclaim claims messages which have been pending for longer than the reclaim
+// timeout, eg. because their consumer stopped or the handler errored.  Messages
+// which have been delivered too many times are acknowledged and dropped.
+func (s *redisSubscription) reclaim(ctx context.Context) error {
+	s.lastReclaim = time.Now()
+
+	msgs, _, err := s.b.r.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   s.stream,
+		Group:    s.group,
+		Consumer: s.b.consumer,
+		MinIdle:  s.b.reclaimAfter,
+		Start:    "0-0",
+		Count:    redisClaimCount,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("error reclaiming pending messages: %w", err)
+	}
+
+	for _, msg := range msgs {
+		if s.isInflight(msg.ID) {
+			continue
+		}
+
+		pending, err := s.b.r.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: s.stream,
+			Group:  s.group,
+			Start:  msg.ID,
+			End:    msg.ID,
+			Count:  1,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			// The message was acknowledged after being claimed.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error loading pending message: %w", err)
+		}
+		if len(pending) == 1 && pending[0].RetryCount > s.b.maxDeliveries {
+			logger.From(ctx).Error().
+				Str("stream", s.stream).
+				Str("id", msg.ID).
+				Int64("deliveries", pending[0].RetryCount).
+				Msg("dropping message after max deliveries")
+			s.ack(ctx, msg.ID)
+			continue
+		}
+
+		s.claimed = append(s.claimed, msg)
+	}
+	return nil
+}
+
+// handle decodes and runs the message, acknowledging the message if it's
+// handled successfully.  Messages which error remain pending and are retried
+// once reclaimed.
+func (s *redisSubscription) handle(ctx context.Context, msg redis.XMessage, run PerformFunc) {
+	defer s.untrack(msg.ID)
+
+	m := &Message{}
+	body, _ := msg.Values[redisFieldMessage].(string)
+	if err := m.Decode([]byte(body)); err != nil {
+		logger.From(ctx).Error().Err(err).Msg("error decoding pubsub message")
+		// Undecodable messages will never succeed, so we acknowledge them.
+		s.ack(ctx, msg.ID)
+		return
+	}
+
+	if err := run(ctx, *m); err != nil {
+		logger.From(ctx).Warn().Err(err).Str("id", msg.ID).Msg("error handling message")
+		return
+	}
+	s.ack(ctx, msg.ID)
+}
+
+func (s *redisSubscription) ack(ctx context.Context, id string) {
+	// Acknowledge using a new context, as messages handled while shutting
+	// down must still be acknowledged.
+	if err := s.b.r.XAck(context.Background(), s.stream, s.group, id).Err(); err != nil {
+		logger.From(ctx).Error().Err(err).Str("id", id).Msg("error acknowledging message")
+	}
+}
+
+func (s *redisSubscription) track(id string) {
+	s.lock.Lock()
+	s.inflight[id] = struct{}{}
+	s.lock.Unlock()
+}
+
+func (s *redisSubscription) untrack(id string) {
+	s.lock.Lock()
+	delete(s.inflight, id)
+	s.lock.Unlock()
+}
+
+func (s *redisSubscription) isInflight(id string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, ok := s.inflight[id]
+	return ok
+}
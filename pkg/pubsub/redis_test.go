@@ -0,0 +1,304 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/inngest/inngest/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testredisbroker(t *testing.T, r *miniredis.Miniredis) *redisBroker {
+	t.Helper()
+
+	c := &config.MessagingService{}
+	c.Set(config.RedisMessaging{
+		Topic:     "events",
+		DSN:       strPtr(fmt.Sprintf("redis://%s", r.Addr())),
+		KeyPrefix: "test",
+		Group:     "default",
+	})
+	ps, err := NewPublishSubscriber(context.Background(), *c)
+	require.NoError(t, err)
+	b, ok := ps.(*redisBroker)
+	require.True(t, ok)
+	return b
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// subscribe subscribes to the topic in the background using the given consumer
+// group.  The subscription stops when the test finishes.
+func subscribe(t *testing.T, b *redisBroker, group string, run PerformFunc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(WithConsumerGroup(context.Background(), group))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := b.SubscribeN(ctx, "events", run, 5)
+		require.NoError(t, err)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Wait for the consumer group to be created.
+	require.Eventually(t, func() bool {
+		return b.r.XPending(context.Background(), "test:events", group).Err() == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func pending(t *testing.T, b *redisBroker, group string) int64 {
+	t.Helper()
+	p, err := b.r.XPending(context.Background(), "test:events", group).Result()
+	require.NoError(t, err)
+	return p.Count
+}
+
+func TestRedisPublishSubscribe(t *testing.T) {
+	ctx := context.Background()
+	r := miniredis.RunT(t)
+	b := testredisbroker(t, r)
+
+	// Each group receives every message.
+	runner := make(chan Message, 10)
+	publisher := make(chan Message, 10)
+	subscribe(t, b, "runner", func(ctx context.Context, m Message) error {
+		runner <- m
+		return nil
+	})
+	subscribe(t, b, "publisher", func(ctx context.Context, m Message) error {
+		publisher <- m
+		return nil
+	})
+
+	sent := Message{Name: "basic", Data: "{}", Timestamp: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, b.Publish(ctx, "events", sent))
+
+	for _, ch := range []chan Message{runner, publisher} {
+		select {
+		case <-time.After(2 * time.Second):
+			t.Fatal("message not received")
+		case received := <-ch:
+			require.EqualValues(t, sent, received)
+		}
+	}
+
+	// Messages are acknowledged once handled.
+	require.Eventually(t, func() bool {
+		return pending(t, b, "runner") == 0 && pending(t, b, "publisher") == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRedisPublishBeforeSubscribe(t *testing.T) {
+	ctx := context.Background()
+	r := miniredis.RunT(t)
+	b := testredisbroker(t, r)
+
+	// Messages published before a group is created are delivered to the
+	// group once it subscribes.
+	sent := Message{Name: "early", Data: "{}", Timestamp: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, b.Publish(ctx, "events", sent))
+
+	received := make(chan Message, 10)
+	subscribe(t, b, "runner", func(ctx context.Context, m Message) error {
+		received <- m
+		return nil
+	})
+
+	select {
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not received")
+	case m := <-received:
+		require.EqualValues(t, sent, m)
+	}
+}
+
+func TestRedisClose(t *testing.T) {
+	ctx := context.Background()
+	r := miniredis.RunT(t)
+	b := testredisbroker(t, r)
+
+	subCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		done <- b.Subscribe(subCtx, "events", func(ctx context.Context, m Message) error { return nil })
+	}()
+	require.Eventually(t, func() bool {
+		return b.r.XPending(ctx, "test:events", "default").Err() == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// Close waits for subscriptions to stop before closing the client.
+	closed := make(chan error)
+	go func() { closed <- Close(b) }()
+	select {
+	case <-closed:
+		t.Fatal("closed prior to the subscription stopping")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+	require.NoError(t, <-closed)
+	require.ErrorIs(t, b.Publish(ctx, "events", Message{Name: "basic"}), redis.ErrClosed)
+}
+
+func TestRedisSubscribeN(t *testing.T) {
+	ctx := context.Background()
+	r := miniredis.RunT(t)
+	b := testredisbroker(t, r)
+
+	var i, paused int32
+	subscribe(t, b, "runner", func(ctx context.Context, m Message) error {
+		atomic.AddInt32(&i, 1)
+		for atomic.LoadInt32(&paused) == 0 {
+			<-time.After(10 * time.Millisecond)
+		}
+		return nil
+	})
+
+	for n := 0; n < 10; n++ {
+		require.NoError(t, b.Publish(ctx, "events", Message{Name: "basic"}))
+	}
+
+	// Only 5 messages are handled at once.
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&i) == 5 }, 2*time.Second, 10*time.Millisecond)
+	<-time.After(200 * time.Millisecond)
+	require.EqualValues(t, 5, atomic.LoadInt32(&i))
+
+	atomic.AddInt32(&paused, 1)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&i) == 10 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestRedisReclaim(t *testing.T) {
+	ctx := context.Background()
+	r := miniredis.RunT(t)
+	b := testredisbroker(t, r)
+	// miniredis only supports reclaiming messages with no minimum idle time.
+	b.reclaimAfter = 0
+
+	require.NoError(t, b.r.XGroupCreateMkStream(ctx, "test:events", "runner", "$").Err())
+	require.NoError(t, b.Publish(ctx, "events", Message{Name: "crashed"}))
+
+	// Read the message from another consumer which never acknowledges it, as
+	// if the consumer stopped while handling the message.
+	_, err := b.r.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    "runner",
+		Consumer: "crashed",
+		Streams:  []string{"test:events", ">"},
+		Count:    1,
+	}).Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, pending(t, b, "runner"))
+
+	// Messages which error are retried.
+	var attempts int32
+	received := make(chan Message, 10)
+	subscribe(t, b, "runner", func(ctx context.Context, m Message) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return fmt.Errorf("failed")
+		}
+		received <- m
+		return nil
+	})
+
+	select {
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not reclaimed")
+	case m := <-received:
+		require.Equal(t, "crashed", m.Name)
+	}
+	require.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	require.Eventually(t, func() bool { return pending(t, b, "runner") == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestRedisMaxDeliveries(t *testing.T) {
+	ctx := context.Background()
+	r := miniredis.RunT(t)
+	b := testredisbroker(t, r)
+	b.reclaimAfter = 0
+	b.maxDeliveries = 3
+
+	var attempts int32
+	subscribe(t, b, "runner", func(ctx context.Context, m Message) error {
+		atomic.AddInt32(&attempts, 1)
+		return fmt.Errorf("failed")
+	})
+	require.NoError(t, b.Publish(ctx, "events", Message{Name: "poison"}))
+
+	// The message is dropped after exceeding the max deliveries.
+	require.Eventually(t, func() bool { return pending(t, b, "runner") == 0 }, 2*time.Second, 10*time.Millisecond)
+	require.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+// commands records the commands sent to redis.
+type commands struct {
+	l    sync.Mutex
+	cmds []string
+}
+
+func (c *commands) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.cmds = append(c.cmds, cmd.String())
+	return ctx, nil
+}
+
+func (c *commands) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+func (c *commands) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (c *commands) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+func (c *commands) contains(prefix string) bool {
+	c.l.Lock()
+	defer c.l.Unlock()
+	for _, cmd := range c.cmds {
+		if strings.HasPrefix(cmd, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRedisEphemeralConsumerGroup(t *testing.T) {
+	r := miniredis.RunT(t)
+	b := testredisbroker(t, r)
+	// miniredis doesn't support removing groups, so record the commands sent.
+	cmds := &commands{}
+	b.r.AddHook(cmds)
+
+	ctx, cancel := context.WithCancel(WithEphemeralConsumerGroup(context.Background(), "api-1"))
+	done := make(chan error)
+	go func() {
+		done <- b.Subscribe(ctx, "events", func(ctx context.Context, m Message) error { return nil })
+	}()
+	require.Eventually(t, func() bool {
+		return b.r.XPending(context.Background(), "test:events", "api-1").Err() == nil
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, cmds.contains("xgroup destroy"))
+	// Ephemeral groups only read messages published after subscribing.
+	require.True(t, cmds.contains("xgroup create test:events api-1 $"))
+
+	// The group is removed once the subscription stops.
+	cancel()
+	require.NoError(t, <-done)
+	require.True(t, cmds.contains("xgroup destroy test:events api-1"))
+}
@@ -11,7 +11,11 @@ config.#Config & {
 		level:  "trace"
 	}
 
-	eventAPI: addr: "127.0.0.1"
+	eventAPI: {
+		addr: "127.0.0.1"
+		// testdsl sends events using this key.
+		keys: [{key: "key", name: "tests"}]
+	}
 
 	execution: {
 		logOutput: true
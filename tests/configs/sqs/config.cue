@@ -15,6 +15,8 @@ config.#Config & {
 
 	eventAPI: {
 		addr: "127.0.0.1"
+		// testdsl sends events using this key.
+		keys: [{key: "key", name: "tests"}]
 	}
 
 	execution: {
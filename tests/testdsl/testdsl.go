@@ -15,6 +15,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/inngest/inngest/inngest"
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/inngest/inngest/pkg/config"
 	"github.com/inngest/inngest/pkg/event"
 	"github.com/inngest/inngest/pkg/function"
@@ -39,6 +40,19 @@ type TestData struct {
 	Out         Buffer
 
 	Config config.Config
+
+	// Clock is used to wait and to time out checks, defaulting to the
+	// system clock.  The functions under test run within a separate inngest
+	// serve process which always uses the system clock, so this must only
+	// be a virtual clock when testing the DSL itself.
+	Clock clock.Clock
+}
+
+func (td *TestData) clock() clock.Clock {
+	if td.Clock == nil {
+		return clock.New()
+	}
+	return td.Clock
 }
 
 // registered stores all registered test DSL roots
@@ -83,7 +97,7 @@ func SendTrigger(ctx context.Context, td *TestData) error {
 	fmt.Println("> Sending trigger")
 
 	var err error
-	evt, err := function.GenerateTriggerData(ctx, time.Now().Unix(), td.Fn.Triggers)
+	evt, err := function.GenerateTriggerData(ctx, td.clock().Now().Unix(), td.Fn.Triggers)
 	if err != nil {
 		return fmt.Errorf("error generating trigger data: %w", err)
 	}
@@ -120,7 +134,7 @@ func RequireReceiveTrigger(ctx context.Context, td *TestData) error {
 func Wait(t time.Duration) Proc {
 	return func(ctx context.Context, td *TestData) error {
 		fmt.Printf("> Waiting %s\n", t.String())
-		<-time.After(t)
+		<-td.clock().After(t)
 		return nil
 	}
 }
@@ -152,7 +166,7 @@ func RequireLogFields(fields map[string]any) Proc {
 func RequireLogFieldsWithin(fields map[string]any, t time.Duration) Proc {
 	return func(ctx context.Context, td *TestData) error {
 		fmt.Printf("> Checking log fields within %s: %v\n", t, fields)
-		return timeout(t, func() error {
+		return timeout(td.clock(), t, func() error {
 			if err := requireLogFields(ctx, td, fields); err != nil {
 				return fmt.Errorf("Could not find fields: %v", fields)
 			}
@@ -176,7 +190,7 @@ func RequireNoLogFields(fields map[string]any) Proc {
 func RequireNoLogFieldsWithin(fields map[string]any, t time.Duration) Proc {
 	return func(ctx context.Context, td *TestData) error {
 		fmt.Printf("> Checking for no log fields: %v\n", fields)
-		err := timeout(t, func() error {
+		err := timeout(td.clock(), t, func() error {
 			if err := requireLogFields(ctx, td, fields); err != nil {
 				return fmt.Errorf("Could not find fields: %v", fields)
 			}
@@ -209,7 +223,7 @@ func RequireOutputWithin(output string, within time.Duration) Proc {
 	return func(ctx context.Context, td *TestData) error {
 		fmt.Printf("> Checking output within %s: %s\n", within, output)
 		// Require output within timeout
-		return timeout(within, func() error {
+		return timeout(td.clock(), within, func() error {
 			if err := requireOutput(ctx, td, output); err != nil {
 				return fmt.Errorf("Could not find output: %s", output)
 			}
@@ -228,7 +242,7 @@ func RequireStepRetries(step string, count int) Proc {
 			backoffTime = uint(10) << i
 
 			fmt.Printf("\t> Checking attempt #%d executes (waiting %d seconds)\n", i+1, backoffTime)
-			if err := timeout(time.Second*time.Duration(backoffTime), func() error {
+			if err := timeout(td.clock(), time.Second*time.Duration(backoffTime), func() error {
 				return requireLogFields(ctx, td, map[string]any{
 					"caller":  "executor",
 					"step":    step,
@@ -244,7 +258,7 @@ func RequireStepRetries(step string, count int) Proc {
 			}
 
 			fmt.Printf("\t> Checking attempt #%d queues a retry\n", i+1)
-			if err := timeout(time.Second*5, func() error {
+			if err := timeout(td.clock(), time.Second*5, func() error {
 				return requireLogFields(ctx, td, map[string]any{
 					"caller":  "executor",
 					"message": "enqueueing retry",
@@ -263,7 +277,7 @@ func RequireStepRetries(step string, count int) Proc {
 		}
 
 		fmt.Printf("> Checking step %s permanently failed after %d retries (waiting %d seconds)\n", step, count, backoffTime)
-		if err := timeout(time.Second*time.Duration(backoffTime), func() error {
+		if err := timeout(td.clock(), time.Second*time.Duration(backoffTime), func() error {
 			return requireLogFields(ctx, td, map[string]any{
 				"caller":  "executor",
 				"message": "step permanently failed",
@@ -277,7 +291,7 @@ func RequireStepRetries(step string, count int) Proc {
 
 		// Finally, check that the step did not have more retries than it was
 		// allowed before it failed.
-		if err := timeout(time.Second, func() error {
+		if err := timeout(td.clock(), time.Second, func() error {
 			return requireLogFields(ctx, td, map[string]any{
 				"caller":  "executor",
 				"message": "enqueueing retry",
@@ -298,14 +312,18 @@ func RequireStepRetries(step string, count int) Proc {
 	}
 }
 
-// timeout is a helper for timeout funcs.
-func timeout(t time.Duration, f func() error) error {
-	timeout := time.After(t)
+// timeout is a helper for timeout funcs, calling f every 50ms until it succeeds
+// or the timeout elapses on the given clock.
+func timeout(clk clock.Clock, t time.Duration, f func() error) error {
+	timeout := clk.NewTimer(t)
+	defer timeout.Stop()
 	for {
+		poll := clk.NewTimer(50 * time.Millisecond)
 		select {
-		case <-timeout:
+		case <-timeout.C():
+			poll.Stop()
 			return f()
-		case <-time.After(50 * time.Millisecond):
+		case <-poll.C():
 			if err := f(); err == nil {
 				return nil
 			}
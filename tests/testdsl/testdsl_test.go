@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/inngest/inngest/pkg/clock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -83,37 +84,70 @@ func TestRequireLogFields(t *testing.T) {
 func TestRequireNoLogFieldsWithin(t *testing.T) {
 	ctx := context.Background()
 	buf := &parallelBuf{}
+	clk := clock.NewVirtual(time.Now())
+	data := &TestData{Out: buf, Clock: clk}
 
-	// Send "fail" within 1 second
-	go func() {
-		<-time.After(time.Second)
-		_, _ = buf.WriteString(`{"fail":true}`)
-	}()
-
-	// Ensure that fail is found within ~500ms
-	now := time.Now()
-	data := &TestData{Out: buf}
+	// Send "fail" after 1 second, which must be found straight away.
 	proc := RequireNoLogFieldsWithin(
 		map[string]any{
 			"fail": true,
 		},
 		2*time.Second,
 	)
-	err := proc(ctx, data)
+	err, elapsed := runWithClock(t, clk, 2*time.Second, func() error { return proc(ctx, data) }, func(elapsed time.Duration) {
+		if elapsed == time.Second {
+			_, _ = buf.WriteString(`{"fail":true}`)
+		}
+	})
 	require.NotNil(t, err)
-	require.Equal(t, 1, int(time.Since(now).Seconds()))
+	require.Equal(t, time.Second, elapsed)
 
 	// Ensure that success isn't found within 5 seconds.
-	now = time.Now()
 	proc = RequireNoLogFieldsWithin(
 		map[string]any{
 			"success": true,
 		},
 		5*time.Second,
 	)
-	err = proc(ctx, data)
+	err, elapsed = runWithClock(t, clk, 5*time.Second, func() error { return proc(ctx, data) }, func(time.Duration) {})
 	require.Nil(t, err)
-	require.Equal(t, 5, int(time.Since(now).Seconds()))
+	require.Equal(t, 5*time.Second, elapsed)
+}
+
+// runWithClock runs f, advancing the virtual clock to each of f's 50ms checks
+// until f returns or the timeout elapses.  before is called with the elapsed
+// time prior to each advance.
+func runWithClock(t *testing.T, clk *clock.Virtual, timeout time.Duration, f func() error, before func(time.Duration)) (error, time.Duration) {
+	start := clk.Now()
+	done := make(chan error, 1)
+	go func() { done <- f() }()
+
+	for {
+		target := clk.Now().Add(50 * time.Millisecond)
+		if deadline := start.Add(timeout); target.After(deadline) {
+			target = deadline
+		}
+
+		// Wait for f to wait on its next check before advancing.
+		var err error
+		finished := false
+		require.Eventually(t, func() bool {
+			select {
+			case err = <-done:
+				finished = true
+				return true
+			default:
+			}
+			next, ok := clk.Next()
+			return ok && next.Equal(target)
+		}, time.Second, time.Millisecond)
+		if finished {
+			return err, clk.Now().Sub(start)
+		}
+
+		before(target.Sub(start))
+		clk.Set(target)
+	}
 }
 
 type parallelBuf struct {